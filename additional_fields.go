@@ -0,0 +1,53 @@
+package pivnet
+
+import (
+	"encoding/json"
+	"reflect"
+	"strings"
+)
+
+// unmarshalWithAdditionalFields decodes b into v, a pointer to a struct, and
+// returns the keys of b that do not belong to any of the struct's JSON
+// fields. It returns nil if there are none.
+//
+// v must not itself implement json.Unmarshaler, so callers pass a pointer to
+// a type defined on top of their own.
+func unmarshalWithAdditionalFields(b []byte, v interface{}) (map[string]json.RawMessage, error) {
+	err := json.Unmarshal(b, v)
+	if err != nil {
+		return nil, err
+	}
+
+	var all map[string]json.RawMessage
+	err = json.Unmarshal(b, &all)
+	if err != nil {
+		return nil, err
+	}
+
+	known := map[string]bool{}
+	t := reflect.TypeOf(v).Elem()
+	for i := 0; i < t.NumField(); i++ {
+		name := strings.Split(t.Field(i).Tag.Get("json"), ",")[0]
+		if name == "-" {
+			continue
+		}
+		if name == "" {
+			name = t.Field(i).Name
+		}
+		known[strings.ToLower(name)] = true
+	}
+
+	var additional map[string]json.RawMessage
+	for key, value := range all {
+		if known[strings.ToLower(key)] {
+			continue
+		}
+
+		if additional == nil {
+			additional = map[string]json.RawMessage{}
+		}
+		additional[key] = value
+	}
+
+	return additional, nil
+}
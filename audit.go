@@ -0,0 +1,157 @@
+package pivnet
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// AuditEvent records a mutating call made by the client: a POST, PUT,
+// PATCH or DELETE, whether it succeeded or not.
+type AuditEvent struct {
+	Time time.Time `json:"time"`
+
+	// Client is the ClientConfig.Name of the client, if set.
+	Client string `json:"client,omitempty"`
+
+	// Operation is the action named by the endpoint, such as
+	// "add_product_file", or else the method and the resource type, such
+	// as "create_release" or "delete_user_group".
+	Operation string `json:"operation"`
+	Method    string `json:"method"`
+
+	// Endpoint is relative to /api/v2, as requested by the library.
+	Endpoint string `json:"endpoint"`
+
+	// Resources holds the identifiers in Endpoint by resource type, e.g.
+	// {"product": "my-product", "release": "1234"}. Identifiers sent in the
+	// body, such as the product file added to a release, are in
+	// RequestBody.
+	Resources map[string]string `json:"resources,omitempty"`
+
+	// RequestBody is the JSON body of the request, if any.
+	RequestBody json.RawMessage `json:"request_body,omitempty"`
+
+	// StatusCode and RequestID are those of the response, if one was
+	// received.
+	StatusCode int    `json:"status_code,omitempty"`
+	RequestID  string `json:"request_id,omitempty"`
+
+	// Error is the error returned by the call, if it failed.
+	Error string `json:"error,omitempty"`
+}
+
+// AuditLogger is called with an AuditEvent once each mutating call made by
+// the client has completed. Unlike the debug logging of the client, the
+// events are a stable record meant to be retained, e.g.
+//
+//	encoder := json.NewEncoder(auditFile)
+//	config.AuditLogger = func(event pivnet.AuditEvent) {
+//		encoder.Encode(event)
+//	}
+//
+// It is called from the goroutine making the call, so an AuditLogger
+// shared by concurrent calls must be safe for concurrent use.
+type AuditLogger func(event AuditEvent)
+
+func isMutatingMethod(method string) bool {
+	switch method {
+	case "POST", "PUT", "PATCH", "DELETE":
+		return true
+	}
+	return false
+}
+
+// makeAuditedRequest makes the request and reports it to the audit logger.
+func (c Client) makeAuditedRequest(
+	requestType string,
+	endpoint string,
+	expected func(statusCode int) bool,
+	body io.Reader,
+	maxResponseBytes int64,
+) (*http.Response, error) {
+	event := newAuditEvent(requestType, strings.TrimPrefix(c.stripHostPrefix(endpoint), apiVersion))
+	event.Time = c.clock()
+	event.Client = c.name
+
+	if body != nil {
+		b, err := ioutil.ReadAll(body)
+		if err != nil {
+			event.Error = err.Error()
+			c.auditLogger(event)
+			return nil, err
+		}
+
+		if json.Valid(b) {
+			event.RequestBody = b
+		}
+		body = bytes.NewReader(b)
+	}
+
+	c.responseHooks = append(append([]ResponseHook{}, c.responseHooks...), func(resp *http.Response) {
+		event.StatusCode = resp.StatusCode
+		event.RequestID = resp.Header.Get(RequestIDHeader)
+	})
+
+	resp, err := c.makeUnauditedRequest(requestType, endpoint, expected, body, maxResponseBytes)
+	if err != nil {
+		event.Error = err.Error()
+	}
+
+	c.auditLogger(event)
+
+	return resp, err
+}
+
+func newAuditEvent(method string, endpoint string) AuditEvent {
+	event := AuditEvent{
+		Method:   method,
+		Endpoint: endpoint,
+	}
+
+	path := endpoint
+	if i := strings.IndexAny(path, "?#"); i >= 0 {
+		path = path[:i]
+	}
+
+	segments := strings.Split(strings.Trim(path, "/"), "/")
+
+	var resourceType string
+	for i := 0; i+1 < len(segments); i += 2 {
+		resourceType = strings.TrimSuffix(segments[i], "s")
+
+		if event.Resources == nil {
+			event.Resources = map[string]string{}
+		}
+		event.Resources[resourceType] = segments[i+1]
+	}
+
+	if len(segments)%2 == 1 {
+		last := segments[len(segments)-1]
+		if method != "POST" || !strings.HasSuffix(last, "s") {
+			event.Operation = last
+			return event
+		}
+		resourceType = strings.TrimSuffix(last, "s")
+	}
+
+	var verb string
+	switch method {
+	case "POST":
+		verb = "create"
+	case "PUT", "PATCH":
+		verb = "update"
+	case "DELETE":
+		verb = "delete"
+	default:
+		verb = strings.ToLower(method)
+	}
+
+	event.Operation = verb + "_" + resourceType
+
+	return event
+}
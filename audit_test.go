@@ -0,0 +1,129 @@
+package pivnet_test
+
+import (
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"time"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"github.com/onsi/gomega/ghttp"
+	"github.com/pivotal-cf/go-pivnet"
+	"github.com/pivotal-cf/go-pivnet/logger/loggerfakes"
+)
+
+var _ = Describe("PivnetClient - audit logging", func() {
+	var (
+		server *ghttp.Server
+		client pivnet.Client
+		events []pivnet.AuditEvent
+		now    time.Time
+	)
+
+	BeforeEach(func() {
+		server = ghttp.NewServer()
+		events = nil
+		now = time.Date(2016, 1, 2, 3, 4, 5, 0, time.UTC)
+
+		client = pivnet.NewClient(pivnet.ClientConfig{
+			Host:      server.URL(),
+			Token:     "my-auth-token",
+			UserAgent: "pivnet-resource/0.1.0 (some-url)",
+			Name:      "publisher",
+			Clock:     func() time.Time { return now },
+			AuditLogger: func(event pivnet.AuditEvent) {
+				events = append(events, event)
+			},
+		}, &loggerfakes.FakeLogger{})
+	})
+
+	AfterEach(func() {
+		server.Close()
+	})
+
+	It("records mutating calls with the resources they target", func() {
+		server.AppendHandlers(
+			ghttp.CombineHandlers(
+				ghttp.VerifyRequest("PATCH", fmt.Sprintf("%s/products/banana/releases/1234/add_product_file", apiPrefix)),
+				ghttp.VerifyJSON(`{"product_file":{"id":2345}}`),
+				ghttp.RespondWith(http.StatusNoContent, nil, http.Header{pivnet.RequestIDHeader: []string{"some-request-id"}}),
+			),
+		)
+
+		err := client.ProductFiles.AddToRelease("banana", 1234, 2345)
+		Expect(err).NotTo(HaveOccurred())
+
+		Expect(events).To(HaveLen(1))
+		event := events[0]
+
+		Expect(event.Time).To(Equal(now))
+		Expect(event.Client).To(Equal("publisher"))
+		Expect(event.Operation).To(Equal("add_product_file"))
+		Expect(event.Method).To(Equal("PATCH"))
+		Expect(event.Endpoint).To(Equal("/products/banana/releases/1234/add_product_file"))
+		Expect(event.Resources).To(Equal(map[string]string{"product": "banana", "release": "1234"}))
+		Expect(event.RequestBody).To(MatchJSON(`{"product_file":{"id":2345}}`))
+		Expect(event.StatusCode).To(Equal(http.StatusNoContent))
+		Expect(event.RequestID).To(Equal("some-request-id"))
+		Expect(event.Error).To(BeEmpty())
+	})
+
+	It("names the operation after the method when the endpoint has no action", func() {
+		server.AppendHandlers(
+			ghttp.RespondWith(http.StatusCreated, `{"release":{"id":1234}}`),
+			ghttp.RespondWith(http.StatusNoContent, nil),
+		)
+
+		_, err := client.Releases.Create(pivnet.CreateReleaseConfig{
+			ProductSlug: "banana",
+			Version:     "1.2.3",
+			ReleaseType: "All-In-One",
+			EULASlug:    "some-eula",
+		})
+		Expect(err).NotTo(HaveOccurred())
+
+		err = client.Releases.Delete("banana", pivnet.Release{ID: 1234})
+		Expect(err).NotTo(HaveOccurred())
+
+		Expect(events).To(HaveLen(2))
+		Expect(events[0].Operation).To(Equal("create_release"))
+		Expect(events[0].Resources).To(Equal(map[string]string{"product": "banana"}))
+		Expect(events[1].Operation).To(Equal("delete_release"))
+		Expect(events[1].Resources).To(Equal(map[string]string{"product": "banana", "release": "1234"}))
+	})
+
+	Context("when the call fails", func() {
+		It("records the error", func() {
+			server.AppendHandlers(
+				ghttp.RespondWith(http.StatusNotFound, `{"message":"release not found"}`),
+			)
+
+			err := client.Releases.Delete("banana", pivnet.Release{ID: 1234})
+			Expect(err).To(HaveOccurred())
+
+			Expect(events).To(HaveLen(1))
+			Expect(events[0].Operation).To(Equal("delete_release"))
+			Expect(events[0].StatusCode).To(Equal(http.StatusNotFound))
+			Expect(events[0].Error).To(ContainSubstring("release not found"))
+		})
+	})
+
+	It("does not record reads or downloads", func() {
+		server.AppendHandlers(
+			ghttp.RespondWithJSONEncoded(http.StatusOK, pivnet.ProductFileResponse{pivnet.ProductFile{
+				ID: 2345,
+				Links: &pivnet.Links{
+					Download: map[string]string{"href": "/products/banana/releases/1234/product_files/2345/download"},
+				},
+			}}),
+			ghttp.RespondWith(http.StatusOK, "some file contents"),
+		)
+
+		err := client.ProductFiles.DownloadTo("banana", 1234, 2345, ioutil.Discard)
+		Expect(err).NotTo(HaveOccurred())
+
+		Expect(server.ReceivedRequests()).To(HaveLen(2))
+		Expect(events).To(BeEmpty())
+	})
+})
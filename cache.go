@@ -0,0 +1,80 @@
+package pivnet
+
+import (
+	"sync"
+	"time"
+)
+
+type ttlCacheEntry struct {
+	value     interface{}
+	expiresAt time.Time
+}
+
+// ttlCache is a thread-safe in-memory cache whose entries expire after a
+// fixed duration. A nil *ttlCache is valid and caches nothing.
+type ttlCache struct {
+	ttl time.Duration
+	now func() time.Time
+
+	mutex   sync.Mutex
+	entries map[string]ttlCacheEntry
+}
+
+// newTTLCache returns nil, disabling caching, if ttl is not positive.
+func newTTLCache(ttl time.Duration, now func() time.Time) *ttlCache {
+	if ttl <= 0 {
+		return nil
+	}
+
+	return &ttlCache{
+		ttl:     ttl,
+		now:     now,
+		entries: map[string]ttlCacheEntry{},
+	}
+}
+
+func (c *ttlCache) get(key string) (interface{}, bool) {
+	if c == nil {
+		return nil, false
+	}
+
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	entry, ok := c.entries[key]
+	if !ok {
+		return nil, false
+	}
+
+	if !c.now().Before(entry.expiresAt) {
+		delete(c.entries, key)
+		return nil, false
+	}
+
+	return entry.value, true
+}
+
+func (c *ttlCache) set(key string, value interface{}) {
+	if c == nil {
+		return
+	}
+
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	c.entries[key] = ttlCacheEntry{
+		value:     value,
+		expiresAt: c.now().Add(c.ttl),
+	}
+}
+
+func (c *ttlCache) clear() {
+	if c == nil {
+		return
+	}
+
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	c.entries = map[string]ttlCacheEntry{}
+}
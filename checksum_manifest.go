@@ -0,0 +1,64 @@
+package pivnet
+
+import "sort"
+
+var signatureFileSuffixes = []string{".sig", ".asc"}
+
+type ChecksumManifestEntry struct {
+	ProductFileID int    `json:"product_file_id" yaml:"product_file_id"`
+	FileName      string `json:"file_name" yaml:"file_name"`
+	SHA256        string `json:"sha256,omitempty" yaml:"sha256,omitempty"`
+	SHA1          string `json:"sha1,omitempty" yaml:"sha1,omitempty"`
+	MD5           string `json:"md5,omitempty" yaml:"md5,omitempty"`
+
+	// HasSignatureFile is reported by Pivnet for the product file.
+	HasSignatureFile bool `json:"has_signature_file,omitempty" yaml:"has_signature_file,omitempty"`
+
+	// SignatureFileID is the ID of the product file of the release named
+	// like this file with a ".sig" or ".asc" suffix, or zero if there is
+	// none. Pivnet does not link signatures to files, so this is matched by
+	// name.
+	SignatureFileID int `json:"signature_file_id,omitempty" yaml:"signature_file_id,omitempty"`
+}
+
+// ChecksumManifest returns the checksums of every product file of the
+// release, with the signature file for each where one can be found, sorted
+// by file name.
+func (r ReleasesService) ChecksumManifest(productSlug string, releaseID int) ([]ChecksumManifestEntry, error) {
+	productFiles, err := ProductFilesService{client: r.client}.ListForRelease(productSlug, releaseID)
+	if err != nil {
+		return nil, err
+	}
+
+	idsByName := map[string]int{}
+	for _, pf := range productFiles {
+		idsByName[productFileName(pf)] = pf.ID
+	}
+
+	manifest := []ChecksumManifestEntry{}
+	for _, pf := range productFiles {
+		entry := ChecksumManifestEntry{
+			ProductFileID:    pf.ID,
+			FileName:         productFileName(pf),
+			SHA256:           pf.SHA256,
+			SHA1:             pf.SHA1,
+			MD5:              pf.MD5,
+			HasSignatureFile: pf.HasSignatureFile,
+		}
+
+		for _, suffix := range signatureFileSuffixes {
+			if id, ok := idsByName[entry.FileName+suffix]; ok {
+				entry.SignatureFileID = id
+				break
+			}
+		}
+
+		manifest = append(manifest, entry)
+	}
+
+	sort.Slice(manifest, func(i, j int) bool {
+		return manifest[i].FileName < manifest[j].FileName
+	})
+
+	return manifest, nil
+}
@@ -0,0 +1,88 @@
+package pivnet_test
+
+import (
+	"fmt"
+	"net/http"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"github.com/onsi/gomega/ghttp"
+	"github.com/pivotal-cf/go-pivnet"
+	"github.com/pivotal-cf/go-pivnet/logger"
+	"github.com/pivotal-cf/go-pivnet/logger/loggerfakes"
+)
+
+var _ = Describe("PivnetClient - checksum manifest", func() {
+	var (
+		server     *ghttp.Server
+		client     pivnet.Client
+		fakeLogger logger.Logger
+
+		productFilesURL string
+	)
+
+	BeforeEach(func() {
+		server = ghttp.NewServer()
+		fakeLogger = &loggerfakes.FakeLogger{}
+
+		client = pivnet.NewClient(pivnet.ClientConfig{
+			Host:      server.URL(),
+			Token:     "my-auth-token",
+			UserAgent: "pivnet-resource/0.1.0 (some-url)",
+		}, fakeLogger)
+
+		productFilesURL = fmt.Sprintf("%s/products/%s/releases/%d/product_files", apiPrefix, productSlug, 1)
+	})
+
+	AfterEach(func() {
+		server.Close()
+	})
+
+	Describe("ChecksumManifest", func() {
+		It("returns the checksums and signature files sorted by file name", func() {
+			server.AppendHandlers(
+				ghttp.CombineHandlers(
+					ghttp.VerifyRequest("GET", productFilesURL),
+					ghttp.RespondWith(http.StatusOK, `{"product_files":[
+						{"id":1,"aws_object_key":"product/tile.pivotal","sha256":"aaa","has_signature_file":true},
+						{"id":2,"aws_object_key":"product/tile.pivotal.sig","sha256":"bbb"},
+						{"id":3,"aws_object_key":"product/cli.tgz","md5":"ccc"}
+					]}`),
+				),
+			)
+
+			manifest, err := client.Releases.ChecksumManifest(productSlug, 1)
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(manifest).To(Equal([]pivnet.ChecksumManifestEntry{
+				{ProductFileID: 3, FileName: "cli.tgz", MD5: "ccc"},
+				{ProductFileID: 1, FileName: "tile.pivotal", SHA256: "aaa", HasSignatureFile: true, SignatureFileID: 2},
+				{ProductFileID: 2, FileName: "tile.pivotal.sig", SHA256: "bbb"},
+			}))
+		})
+
+		Context("when the release has no product files", func() {
+			It("returns an empty manifest", func() {
+				server.AppendHandlers(
+					ghttp.RespondWith(http.StatusOK, `{"product_files":[]}`),
+				)
+
+				manifest, err := client.Releases.ChecksumManifest(productSlug, 1)
+				Expect(err).NotTo(HaveOccurred())
+				Expect(manifest).NotTo(BeNil())
+				Expect(manifest).To(BeEmpty())
+			})
+		})
+
+		Context("when the server responds with a non-2XX status code", func() {
+			It("returns an error", func() {
+				server.AppendHandlers(
+					ghttp.RespondWith(http.StatusTeapot, `{"message":"foo message"}`),
+				)
+
+				_, err := client.Releases.ChecksumManifest(productSlug, 1)
+				Expect(err).To(MatchError(ContainSubstring("foo message")))
+			})
+		})
+	})
+})
@@ -0,0 +1,121 @@
+package pivnet
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"path"
+	"strings"
+
+	"github.com/pivotal-cf/go-pivnet/logger"
+)
+
+const (
+	checksumSidecarSuffix  = ".sha256"
+	maxChecksumSidecarSize = 64 * 1024
+)
+
+// withSidecarChecksum returns pf with its SHA256 taken from a sidecar file
+// if Pivnet has no checksum for it. The sidecar is the product file of the
+// release named like pf with a ".sha256" suffix, in the "<hash>  <filename>"
+// format written by sha256sum. pf is returned unchanged if it already has a
+// checksum or the release has no sidecar for it.
+func (p ProductFilesService) withSidecarChecksum(
+	productSlug string,
+	releaseID int,
+	pf ProductFile,
+) (ProductFile, error) {
+	if newChecksumVerifier(pf) != nil {
+		return pf, nil
+	}
+
+	productFiles, err := p.ListForRelease(productSlug, releaseID)
+	if err != nil {
+		return ProductFile{}, err
+	}
+
+	name := productFileName(pf)
+
+	sidecarID := 0
+	for _, candidate := range productFiles {
+		if productFileName(candidate) == name+checksumSidecarSuffix {
+			sidecarID = candidate.ID
+			break
+		}
+	}
+
+	if sidecarID == 0 {
+		return pf, nil
+	}
+
+	sidecar, err := p.GetForRelease(productSlug, releaseID, sidecarID)
+	if err != nil {
+		return ProductFile{}, err
+	}
+
+	if sidecar.Size > maxChecksumSidecarSize {
+		return ProductFile{}, fmt.Errorf(
+			"Checksum file %s is too large (%d bytes)",
+			productFileName(sidecar),
+			sidecar.Size,
+		)
+	}
+
+	var contents bytes.Buffer
+	_, err = p.fetch(sidecar, DownloadOptions{}, &contents)
+	if err != nil {
+		return ProductFile{}, p.eulaNotAccepted(productSlug, releaseID, err)
+	}
+
+	checksum, err := parseChecksumSidecar(contents.String(), name)
+	if err != nil {
+		return ProductFile{}, fmt.Errorf(
+			"Could not read checksum file %s: %s",
+			productFileName(sidecar),
+			err.Error(),
+		)
+	}
+
+	p.client.logger.Debug("Using checksum from checksum file", logger.Data{
+		"product_file_id": pf.ID,
+		"checksum_file":   productFileName(sidecar),
+	})
+
+	pf.SHA256 = checksum
+	return pf, nil
+}
+
+// parseChecksumSidecar returns the SHA256 for name from sha256sum output,
+// i.e. lines of "<hash>  <filename>", or "<hash> *<filename>" for files
+// hashed in binary mode. A file holding a single line may omit the file
+// name.
+func parseChecksumSidecar(contents string, name string) (string, error) {
+	var lines [][]string
+
+	scanner := bufio.NewScanner(strings.NewReader(contents))
+	for scanner.Scan() {
+		fields := strings.SplitN(strings.TrimSpace(scanner.Text()), " ", 2)
+		if fields[0] != "" {
+			lines = append(lines, fields)
+		}
+	}
+
+	for _, fields := range lines {
+		if !isSHA256(strings.ToLower(fields[0])) {
+			return "", fmt.Errorf("'%s' is not a SHA256 checksum", fields[0])
+		}
+
+		if len(lines) == 1 && len(fields) == 1 {
+			return fields[0], nil
+		}
+
+		if len(fields) == 2 {
+			fileName := strings.TrimPrefix(strings.TrimSpace(fields[1]), "*")
+			if fileName == name || path.Base(fileName) == name {
+				return fields[0], nil
+			}
+		}
+	}
+
+	return "", fmt.Errorf("no checksum found for %s", name)
+}
@@ -0,0 +1,270 @@
+package pivnet_test
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"os"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"github.com/onsi/gomega/ghttp"
+	"github.com/pivotal-cf/go-pivnet"
+	"github.com/pivotal-cf/go-pivnet/logger/loggerfakes"
+)
+
+var _ = Describe("PivnetClient - checksum sidecar files", func() {
+	var (
+		server *ghttp.Server
+		client pivnet.Client
+
+		releaseID    int
+		fileContents []byte
+		checksum     string
+
+		productFile     pivnet.ProductFile
+		sidecar         pivnet.ProductFile
+		sidecarContents string
+		releaseFiles    []pivnet.ProductFile
+	)
+
+	productFileURL := func(id int) string {
+		return fmt.Sprintf("%s/products/%s/releases/%d/product_files/%d", apiPrefix, productSlug, releaseID, id)
+	}
+
+	BeforeEach(func() {
+		server = ghttp.NewServer()
+		client = pivnet.NewClient(pivnet.ClientConfig{
+			Host:      server.URL(),
+			Token:     "my-auth-token",
+			UserAgent: "pivnet-resource/0.1.0 (some-url)",
+		}, &loggerfakes.FakeLogger{})
+
+		releaseID = 1234
+		fileContents = []byte("some file contents")
+
+		sum := sha256.Sum256(fileContents)
+		checksum = hex.EncodeToString(sum[:])
+
+		productFile = pivnet.ProductFile{
+			ID:           2345,
+			AWSObjectKey: "product-files/banana/some-file.tgz",
+			Links: &pivnet.Links{
+				Download: map[string]string{"href": "/some/download/link"},
+			},
+		}
+
+		sidecar = pivnet.ProductFile{
+			ID:           3456,
+			AWSObjectKey: "product-files/banana/some-file.tgz.sha256",
+			Links: &pivnet.Links{
+				Download: map[string]string{"href": "/sidecar/download/link"},
+			},
+		}
+
+		sidecarContents = checksum + "  some-file.tgz\n"
+		releaseFiles = []pivnet.ProductFile{productFile, sidecar}
+	})
+
+	JustBeforeEach(func() {
+		server.RouteToHandler("GET", productFileURL(productFile.ID),
+			ghttp.RespondWithJSONEncoded(http.StatusOK, pivnet.ProductFileResponse{productFile}),
+		)
+		server.RouteToHandler("GET", productFileURL(sidecar.ID),
+			ghttp.RespondWithJSONEncoded(http.StatusOK, pivnet.ProductFileResponse{sidecar}),
+		)
+		server.RouteToHandler("GET", fmt.Sprintf("%s/products/%s/releases/%d/product_files", apiPrefix, productSlug, releaseID),
+			ghttp.RespondWithJSONEncoded(http.StatusOK, pivnet.ProductFilesResponse{ProductFiles: releaseFiles}),
+		)
+		server.RouteToHandler("POST", apiPrefix+"/some/download/link",
+			ghttp.RespondWith(http.StatusOK, fileContents),
+		)
+		server.RouteToHandler("POST", apiPrefix+"/sidecar/download/link",
+			ghttp.RespondWith(http.StatusOK, sidecarContents),
+		)
+	})
+
+	AfterEach(func() {
+		server.Close()
+	})
+
+	sidecarDownloaded := func() bool {
+		for _, req := range server.ReceivedRequests() {
+			if req.URL.Path == apiPrefix+"/sidecar/download/link" {
+				return true
+			}
+		}
+		return false
+	}
+
+	Describe("DownloadWithOptions", func() {
+		It("verifies a file without a checksum against its sidecar", func() {
+			var buf bytes.Buffer
+			err := client.ProductFiles.DownloadWithOptions(
+				productSlug,
+				releaseID,
+				productFile.ID,
+				pivnet.DownloadOptions{ChecksumSidecar: true},
+				&buf,
+			)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(buf.Bytes()).To(Equal(fileContents))
+			Expect(sidecarDownloaded()).To(BeTrue())
+		})
+
+		Context("when the content does not match the sidecar checksum", func() {
+			BeforeEach(func() {
+				sidecarContents = "  " + hex.EncodeToString(make([]byte, sha256.Size)) + " *some-file.tgz\n"
+			})
+
+			It("returns an ErrChecksumMismatch", func() {
+				err := client.ProductFiles.DownloadWithOptions(
+					productSlug,
+					releaseID,
+					productFile.ID,
+					pivnet.DownloadOptions{ChecksumSidecar: true},
+					ioutil.Discard,
+				)
+				Expect(err).To(BeAssignableToTypeOf(pivnet.ErrChecksumMismatch{}))
+			})
+		})
+
+		Context("when the sidecar has no checksum for the file", func() {
+			BeforeEach(func() {
+				sidecarContents = checksum + "  other-file.tgz\n" + checksum + "  another-file.tgz\n"
+			})
+
+			It("returns an error", func() {
+				err := client.ProductFiles.DownloadWithOptions(
+					productSlug,
+					releaseID,
+					productFile.ID,
+					pivnet.DownloadOptions{ChecksumSidecar: true},
+					ioutil.Discard,
+				)
+				Expect(err).To(MatchError(ContainSubstring("no checksum found for some-file.tgz")))
+			})
+		})
+
+		Context("when the release has no sidecar for the file", func() {
+			BeforeEach(func() {
+				releaseFiles = []pivnet.ProductFile{productFile}
+			})
+
+			It("downloads the file without verifying it", func() {
+				err := client.ProductFiles.DownloadWithOptions(
+					productSlug,
+					releaseID,
+					productFile.ID,
+					pivnet.DownloadOptions{ChecksumSidecar: true},
+					ioutil.Discard,
+				)
+				Expect(err).NotTo(HaveOccurred())
+				Expect(sidecarDownloaded()).To(BeFalse())
+			})
+		})
+
+		Context("when the product file has a checksum in its metadata", func() {
+			BeforeEach(func() {
+				productFile.SHA256 = checksum
+				sidecarContents = "not a checksum"
+			})
+
+			It("does not look for a sidecar", func() {
+				err := client.ProductFiles.DownloadWithOptions(
+					productSlug,
+					releaseID,
+					productFile.ID,
+					pivnet.DownloadOptions{ChecksumSidecar: true},
+					ioutil.Discard,
+				)
+				Expect(err).NotTo(HaveOccurred())
+				Expect(server.ReceivedRequests()).To(HaveLen(2))
+			})
+		})
+
+		Context("when the option is not set", func() {
+			It("does not look for a sidecar", func() {
+				err := client.ProductFiles.DownloadWithOptions(
+					productSlug,
+					releaseID,
+					productFile.ID,
+					pivnet.DownloadOptions{},
+					ioutil.Discard,
+				)
+				Expect(err).NotTo(HaveOccurred())
+				Expect(server.ReceivedRequests()).To(HaveLen(2))
+			})
+		})
+	})
+
+	Describe("VerifyFileWithOptions", func() {
+		var (
+			localFilePath string
+		)
+
+		BeforeEach(func() {
+			f, err := ioutil.TempFile("", "go-pivnet-sidecar")
+			Expect(err).NotTo(HaveOccurred())
+			defer f.Close()
+
+			_, err = f.Write(fileContents)
+			Expect(err).NotTo(HaveOccurred())
+
+			localFilePath = f.Name()
+		})
+
+		AfterEach(func() {
+			os.Remove(localFilePath)
+		})
+
+		It("verifies the local file against the sidecar checksum", func() {
+			err := client.ProductFiles.VerifyFileWithOptions(
+				productSlug,
+				releaseID,
+				productFile.ID,
+				localFilePath,
+				pivnet.VerifyOptions{ChecksumSidecar: true},
+			)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(sidecarDownloaded()).To(BeTrue())
+		})
+
+		Context("when the sidecar holds only a checksum", func() {
+			BeforeEach(func() {
+				sidecarContents = checksum
+			})
+
+			It("uses it", func() {
+				err := client.ProductFiles.VerifyFileWithOptions(
+					productSlug,
+					releaseID,
+					productFile.ID,
+					localFilePath,
+					pivnet.VerifyOptions{ChecksumSidecar: true},
+				)
+				Expect(err).NotTo(HaveOccurred())
+			})
+		})
+
+		Context("when the sidecar is malformed", func() {
+			BeforeEach(func() {
+				sidecarContents = "abc  some-file.tgz"
+			})
+
+			It("returns an error", func() {
+				err := client.ProductFiles.VerifyFileWithOptions(
+					productSlug,
+					releaseID,
+					productFile.ID,
+					localFilePath,
+					pivnet.VerifyOptions{ChecksumSidecar: true},
+				)
+				Expect(err).To(MatchError(ContainSubstring("'abc' is not a SHA256 checksum")))
+			})
+		})
+	})
+})
@@ -0,0 +1,69 @@
+package pivnet
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/pivotal-cf/go-pivnet/logger"
+)
+
+// ClientNameLogKey is the log data key under which ClientConfig.Name is
+// added to every line the client logs.
+const ClientNameLogKey = "client"
+
+// Name returns ClientConfig.Name.
+func (c Client) Name() string {
+	return c.name
+}
+
+type clientNameKey struct{}
+
+// RequestClientName returns the ClientConfig.Name of the client that made
+// req, for response hooks shared between clients, e.g.
+//
+//	hook := func(resp *http.Response) {
+//		log.Println(pivnet.RequestClientName(resp.Request), resp.StatusCode)
+//	}
+//
+// It returns the empty string for requests not made by a named client.
+func RequestClientName(req *http.Request) string {
+	if req == nil {
+		return ""
+	}
+
+	name, _ := req.Context().Value(clientNameKey{}).(string)
+	return name
+}
+
+func withClientName(ctx context.Context, name string) context.Context {
+	if name == "" {
+		return ctx
+	}
+	return context.WithValue(ctx, clientNameKey{}, name)
+}
+
+// namedLogger adds the client name to the data of every line it logs.
+type namedLogger struct {
+	logger logger.Logger
+	name   string
+}
+
+func (l namedLogger) Debug(action string, data ...logger.Data) {
+	l.logger.Debug(action, l.annotate(data)...)
+}
+
+func (l namedLogger) Info(action string, data ...logger.Data) {
+	l.logger.Info(action, l.annotate(data)...)
+}
+
+func (l namedLogger) annotate(data []logger.Data) []logger.Data {
+	annotated := logger.Data{}
+	for _, d := range data {
+		for key, value := range d {
+			annotated[key] = value
+		}
+	}
+	annotated[ClientNameLogKey] = l.name
+
+	return []logger.Data{annotated}
+}
@@ -0,0 +1,90 @@
+package pivnet_test
+
+import (
+	"net/http"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"github.com/onsi/gomega/ghttp"
+	"github.com/pivotal-cf/go-pivnet"
+	"github.com/pivotal-cf/go-pivnet/logger/loggerfakes"
+)
+
+var _ = Describe("PivnetClient - client name", func() {
+	var (
+		server     *ghttp.Server
+		fakeLogger *loggerfakes.FakeLogger
+		config     pivnet.ClientConfig
+		names      []string
+	)
+
+	BeforeEach(func() {
+		server = ghttp.NewServer()
+		server.AppendHandlers(ghttp.RespondWith(http.StatusOK, `{"id": 3}`))
+
+		fakeLogger = &loggerfakes.FakeLogger{}
+		names = nil
+		config = pivnet.ClientConfig{
+			Host:      server.URL(),
+			Token:     "my-auth-token",
+			UserAgent: "pivnet-resource/0.1.0 (some-url)",
+			Name:      "account-a",
+			ResponseHooks: []pivnet.ResponseHook{func(resp *http.Response) {
+				names = append(names, pivnet.RequestClientName(resp.Request))
+			}},
+		}
+	})
+
+	AfterEach(func() {
+		server.Close()
+	})
+
+	It("adds the name to every log line", func() {
+		client := pivnet.NewClient(config, fakeLogger)
+		Expect(client.Name()).To(Equal("account-a"))
+
+		_, err := client.Releases.Get("banana", 3)
+		Expect(err).NotTo(HaveOccurred())
+
+		Expect(fakeLogger.DebugCallCount()).To(BeNumerically(">", 0))
+		for i := 0; i < fakeLogger.DebugCallCount(); i++ {
+			action, data := fakeLogger.DebugArgsForCall(i)
+			Expect(data).To(HaveLen(1))
+			Expect(data[0]).To(HaveKeyWithValue(pivnet.ClientNameLogKey, "account-a"))
+
+			if action == "Response status code" {
+				Expect(data[0]).To(HaveKeyWithValue("status code", http.StatusOK))
+			}
+		}
+	})
+
+	It("makes the name available to response hooks", func() {
+		client := pivnet.NewClient(config, fakeLogger)
+
+		_, err := client.Releases.Get("banana", 3)
+		Expect(err).NotTo(HaveOccurred())
+
+		Expect(names).To(Equal([]string{"account-a"}))
+	})
+
+	Context("when no name is set", func() {
+		BeforeEach(func() {
+			config.Name = ""
+		})
+
+		It("does not annotate the logs or requests", func() {
+			client := pivnet.NewClient(config, fakeLogger)
+
+			_, err := client.Releases.Get("banana", 3)
+			Expect(err).NotTo(HaveOccurred())
+
+			for i := 0; i < fakeLogger.DebugCallCount(); i++ {
+				_, data := fakeLogger.DebugArgsForCall(i)
+				for _, d := range data {
+					Expect(d).NotTo(HaveKey(pivnet.ClientNameLogKey))
+				}
+			}
+			Expect(names).To(Equal([]string{""}))
+		})
+	})
+})
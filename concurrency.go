@@ -0,0 +1,35 @@
+package pivnet
+
+import "sync"
+
+// forEachConcurrently calls fn for every index in [0, n) using at most
+// concurrency goroutines, returning once all calls have completed.
+// A concurrency below one is treated as one.
+func forEachConcurrently(n int, concurrency int, fn func(i int)) {
+	if concurrency < 1 {
+		concurrency = 1
+	}
+	if concurrency > n {
+		concurrency = n
+	}
+
+	indices := make(chan int)
+
+	var wg sync.WaitGroup
+	for w := 0; w < concurrency; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range indices {
+				fn(i)
+			}
+		}()
+	}
+
+	for i := 0; i < n; i++ {
+		indices <- i
+	}
+	close(indices)
+
+	wg.Wait()
+}
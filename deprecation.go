@@ -0,0 +1,86 @@
+package pivnet
+
+import (
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/pivotal-cf/go-pivnet/logger"
+)
+
+// DeprecationNotice describes a response on which Pivnet announced that the
+// endpoint is deprecated or will be removed.
+type DeprecationNotice struct {
+	Method string
+	URL    string
+
+	// Deprecation is the raw Deprecation header, if present.
+	Deprecation string
+
+	// Sunset is when the endpoint will be removed, or zero if the response
+	// had no parseable Sunset header.
+	Sunset time.Time
+
+	// Link is the raw Link header, which may point to migration docs.
+	Link string
+}
+
+type deprecationTracker struct {
+	mutex  sync.Mutex
+	latest *DeprecationNotice
+}
+
+// observeDeprecation logs a warning and records the notice if the response
+// carries a Deprecation or Sunset header.
+func (c Client) observeDeprecation(req *http.Request, resp *http.Response) {
+	deprecation := resp.Header.Get("Deprecation")
+	sunset := resp.Header.Get("Sunset")
+	if deprecation == "" && sunset == "" {
+		return
+	}
+
+	notice := DeprecationNotice{
+		Method:      req.Method,
+		URL:         req.URL.String(),
+		Deprecation: deprecation,
+		Link:        resp.Header.Get("Link"),
+	}
+
+	if sunset != "" {
+		notice.Sunset, _ = http.ParseTime(sunset)
+	}
+
+	c.logger.Info("Warning: Pivnet endpoint is deprecated", logger.Data{
+		"method":      notice.Method,
+		"url":         notice.URL,
+		"deprecation": deprecation,
+		"sunset":      sunset,
+		"link":        notice.Link,
+	})
+
+	if c.deprecations == nil {
+		return
+	}
+
+	c.deprecations.mutex.Lock()
+	defer c.deprecations.mutex.Unlock()
+
+	c.deprecations.latest = &notice
+}
+
+// LastDeprecation returns the most recent deprecation notice received by the
+// client, or false if there has been none.
+func (c Client) LastDeprecation() (DeprecationNotice, bool) {
+	if c.deprecations == nil {
+		return DeprecationNotice{}, false
+	}
+
+	c.deprecations.mutex.Lock()
+	defer c.deprecations.mutex.Unlock()
+
+	if c.deprecations.latest == nil {
+		return DeprecationNotice{}, false
+	}
+
+	return *c.deprecations.latest, true
+}
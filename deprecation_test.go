@@ -0,0 +1,85 @@
+package pivnet_test
+
+import (
+	"fmt"
+	"net/http"
+	"time"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"github.com/onsi/gomega/ghttp"
+	"github.com/pivotal-cf/go-pivnet"
+	"github.com/pivotal-cf/go-pivnet/logger/loggerfakes"
+)
+
+var _ = Describe("PivnetClient - deprecation notices", func() {
+	var (
+		server     *ghttp.Server
+		client     pivnet.Client
+		fakeLogger *loggerfakes.FakeLogger
+	)
+
+	BeforeEach(func() {
+		server = ghttp.NewServer()
+		fakeLogger = &loggerfakes.FakeLogger{}
+
+		client = pivnet.NewClient(pivnet.ClientConfig{
+			Host:      server.URL(),
+			Token:     "my-auth-token",
+			UserAgent: "go-pivnet/0.1.0",
+		}, fakeLogger)
+	})
+
+	AfterEach(func() {
+		server.Close()
+	})
+
+	It("has no notice before a deprecated endpoint is used", func() {
+		server.AppendHandlers(ghttp.RespondWith(http.StatusOK, `{}`))
+
+		_, err := client.MakeRequest("GET", "/foo", http.StatusOK, nil)
+		Expect(err).NotTo(HaveOccurred())
+
+		_, ok := client.LastDeprecation()
+		Expect(ok).To(BeFalse())
+		Expect(fakeLogger.InfoCallCount()).To(BeZero())
+	})
+
+	Context("when the response has Deprecation and Sunset headers", func() {
+		BeforeEach(func() {
+			server.AppendHandlers(ghttp.RespondWith(http.StatusOK, `{}`, http.Header{
+				"Deprecation": []string{"true"},
+				"Sunset":      []string{"Sat, 02 Jan 2016 03:04:05 GMT"},
+				"Link":        []string{`<https://example.com/migrate>; rel="deprecation"`},
+			}))
+		})
+
+		It("logs a warning and records the notice", func() {
+			_, err := client.MakeRequest("GET", "/foo", http.StatusOK, nil)
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(fakeLogger.InfoCallCount()).To(Equal(1))
+			action, data := fakeLogger.InfoArgsForCall(0)
+			Expect(action).To(ContainSubstring("deprecated"))
+			Expect(data[0]).To(HaveKeyWithValue("sunset", "Sat, 02 Jan 2016 03:04:05 GMT"))
+
+			notice, ok := client.LastDeprecation()
+			Expect(ok).To(BeTrue())
+			Expect(notice.Method).To(Equal("GET"))
+			Expect(notice.URL).To(Equal(fmt.Sprintf("%s%s/foo", server.URL(), apiPrefix)))
+			Expect(notice.Deprecation).To(Equal("true"))
+			Expect(notice.Sunset.Equal(time.Date(2016, 1, 2, 3, 4, 5, 0, time.UTC))).To(BeTrue())
+			Expect(notice.Link).To(Equal(`<https://example.com/migrate>; rel="deprecation"`))
+		})
+
+		It("shares the notice with derived clients", func() {
+			derived := client.WithUserAgentSuffix("(op=test)")
+
+			_, err := derived.MakeRequest("GET", "/foo", http.StatusOK, nil)
+			Expect(err).NotTo(HaveOccurred())
+
+			_, ok := client.LastDeprecation()
+			Expect(ok).To(BeTrue())
+		})
+	})
+})
@@ -0,0 +1,197 @@
+package pivnet
+
+import (
+	"context"
+	"fmt"
+	"path"
+	"path/filepath"
+	"sync"
+	"time"
+)
+
+type DownloadAllOptions struct {
+	// Include selects the product files whose file name or name matches any
+	// of the glob patterns. Empty selects every product file.
+	Include []string
+
+	// Exclude skips the product files whose file name or name matches any
+	// of the glob patterns, even if they are included.
+	Exclude []string
+
+	// Concurrency is the number of files downloaded at once. Values below
+	// one download one file at a time.
+	Concurrency int
+
+	// TimeoutPerFile, if positive, limits how long the download of each
+	// file may take, including any retries and resumes. A file that takes
+	// longer is abandoned and reported as ErrDownloadTimedOut while the
+	// other files carry on. It complements DownloadOptions.MinThroughput,
+	// which catches downloads that stall rather than ones that are slow
+	// throughout.
+	TimeoutPerFile time.Duration
+
+	// DownloadOptions are applied to the download of each file.
+	DownloadOptions DownloadOptions
+}
+
+// ErrDownloadTimedOut is reported by DownloadAll for a file whose download
+// took longer than DownloadAllOptions.TimeoutPerFile.
+type ErrDownloadTimedOut struct {
+	ProductFileID int
+	Timeout       time.Duration
+}
+
+func (e ErrDownloadTimedOut) Error() string {
+	return fmt.Sprintf(
+		"download of product file %d timed out after %s",
+		e.ProductFileID,
+		e.Timeout,
+	)
+}
+
+// ErrDuplicateFileName is reported by DownloadAll for each of the selected
+// product files that would be downloaded to the same path. None of them is
+// downloaded.
+type ErrDuplicateFileName struct {
+	ProductFileIDs []int
+	Path           string
+}
+
+func (e ErrDuplicateFileName) Error() string {
+	return fmt.Sprintf(
+		"product files %v would all be downloaded to %s",
+		e.ProductFileIDs,
+		e.Path,
+	)
+}
+
+// DownloadAll downloads the product files of the release selected by the
+// options into dir, each named after its file name, using DownloadToFile.
+// It returns the product files that were downloaded. Files that failed are
+// reported in a MultiError keyed by product file ID, those that timed out
+// as ErrDownloadTimedOut so that they can be told apart and retried, and
+// those sharing a file name as ErrDuplicateFileName.
+func (p ProductFilesService) DownloadAll(
+	productSlug string,
+	releaseID int,
+	dir string,
+	options DownloadAllOptions,
+) ([]ProductFile, error) {
+	for _, pattern := range append(append([]string{}, options.Include...), options.Exclude...) {
+		if _, err := path.Match(pattern, ""); err != nil {
+			return nil, err
+		}
+	}
+
+	productFiles, err := p.ListForRelease(productSlug, releaseID)
+	if err != nil {
+		return nil, err
+	}
+
+	var selected []ProductFile
+	for _, pf := range productFiles {
+		if options.selects(pf) {
+			selected = append(selected, pf)
+		}
+	}
+
+	destinations := make([]string, len(selected))
+	sharing := map[string][]int{}
+	for i, pf := range selected {
+		destinations[i] = filepath.Join(dir, localFileName(pf))
+		sharing[destinations[i]] = append(sharing[destinations[i]], pf.ID)
+	}
+
+	downloaded := make([]bool, len(selected))
+	errs := map[int]error{}
+
+	for i, pf := range selected {
+		if ids := sharing[destinations[i]]; len(ids) > 1 {
+			errs[pf.ID] = ErrDuplicateFileName{
+				ProductFileIDs: ids,
+				Path:           destinations[i],
+			}
+		}
+	}
+
+	var mutex sync.Mutex
+	forEachConcurrently(len(selected), options.Concurrency, func(i int) {
+		pf := selected[i]
+		destination := destinations[i]
+
+		if len(sharing[destination]) > 1 {
+			return
+		}
+
+		err := p.downloadOneOfAll(productSlug, releaseID, pf.ID, destination, options)
+		if err != nil {
+			mutex.Lock()
+			errs[pf.ID] = err
+			mutex.Unlock()
+			return
+		}
+
+		downloaded[i] = true
+	})
+
+	result := []ProductFile{}
+	for i, pf := range selected {
+		if downloaded[i] {
+			result = append(result, pf)
+		}
+	}
+
+	return result, newMultiError(errs)
+}
+
+func (p ProductFilesService) downloadOneOfAll(
+	productSlug string,
+	releaseID int,
+	productFileID int,
+	destination string,
+	options DownloadAllOptions,
+) error {
+	if options.TimeoutPerFile <= 0 {
+		return p.DownloadToFile(productSlug, releaseID, productFileID, destination, options.DownloadOptions)
+	}
+
+	parent := p.client.ctx
+	if parent == nil {
+		parent = context.Background()
+	}
+
+	ctx, cancel := context.WithTimeout(parent, options.TimeoutPerFile)
+	defer cancel()
+
+	files := ProductFilesService{client: p.client.WithContext(ctx)}
+
+	err := files.DownloadToFile(productSlug, releaseID, productFileID, destination, options.DownloadOptions)
+	if err != nil && ctx.Err() == context.DeadlineExceeded && parent.Err() == nil {
+		return ErrDownloadTimedOut{
+			ProductFileID: productFileID,
+			Timeout:       options.TimeoutPerFile,
+		}
+	}
+
+	return err
+}
+
+func (o DownloadAllOptions) selects(pf ProductFile) bool {
+	for _, pattern := range o.Exclude {
+		if productFileMatches(pf, pattern) {
+			return false
+		}
+	}
+
+	if len(o.Include) == 0 {
+		return true
+	}
+
+	for _, pattern := range o.Include {
+		if productFileMatches(pf, pattern) {
+			return true
+		}
+	}
+
+	return false
+}
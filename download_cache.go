@@ -0,0 +1,293 @@
+package pivnet
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/pivotal-cf/go-pivnet/logger"
+)
+
+// download streams the product file to the writers, from the download cache
+// if it holds a verified copy and otherwise from Pivnet, populating the
+// cache.
+func (p ProductFilesService) download(
+	pf ProductFile,
+	options DownloadOptions,
+	writers ...io.Writer,
+) (VerifiedDownload, error) {
+	p = options.withTimeouts(p)
+
+	cacheDir := p.client.downloadCacheDir
+	checksum := strings.ToLower(pf.SHA256)
+
+	if cacheDir == "" {
+		return p.fetch(pf, options, writers...)
+	}
+
+	if !isSHA256(checksum) {
+		return p.downloadRevalidating(pf, options, writers...)
+	}
+
+	cachePath := filepath.Join(cacheDir, checksum)
+
+	if p.cachedCopyIsValid(cachePath, checksum, options) {
+		return p.copyFromCache(cachePath, pf, options, writers...)
+	}
+
+	err := os.MkdirAll(cacheDir, 0755)
+	if err != nil {
+		p.logCacheError("Failed to create download cache", cacheDir, err)
+		return p.fetch(pf, options, writers...)
+	}
+
+	tmp, err := ioutil.TempFile(cacheDir, "."+checksum+partialDownloadSuffix)
+	if err != nil {
+		p.logCacheError("Failed to create file in download cache", cacheDir, err)
+		return p.fetch(pf, options, writers...)
+	}
+
+	cache := &cacheWriter{writer: tmp}
+
+	verified, err := p.fetch(pf, options, append(append([]io.Writer{}, writers...), cache)...)
+
+	closeErr := tmp.Close()
+	if cache.err == nil {
+		cache.err = closeErr
+	}
+
+	if err == nil && cache.err == nil {
+		cache.err = os.Rename(tmp.Name(), cachePath)
+	}
+
+	if err != nil || cache.err != nil {
+		os.Remove(tmp.Name())
+	}
+
+	if err == nil && cache.err != nil {
+		p.logCacheError("Failed to add file to download cache", cachePath, cache.err)
+	}
+
+	return verified, err
+}
+
+// cachedCopyIsValid reports whether the cache holds the content with the
+// checksum, removing a cached file that does not match.
+func (p ProductFilesService) cachedCopyIsValid(
+	cachePath string,
+	checksum string,
+	options DownloadOptions,
+) bool {
+	f, err := os.Open(cachePath)
+	if err != nil {
+		if !os.IsNotExist(err) {
+			p.logCacheError("Failed to open cached file", cachePath, err)
+		}
+		return false
+	}
+	defer f.Close()
+
+	hash := options.newSHA256()
+	_, err = io.Copy(hash, f)
+	if err != nil {
+		p.logCacheError("Failed to read cached file", cachePath, err)
+		return false
+	}
+
+	if hex.EncodeToString(hash.Sum(nil)) != checksum {
+		p.client.logger.Info(
+			"Removing corrupt file from download cache",
+			logger.Data{"path": cachePath},
+		)
+		os.Remove(cachePath)
+		return false
+	}
+
+	return true
+}
+
+func (p ProductFilesService) copyFromCache(
+	cachePath string,
+	pf ProductFile,
+	options DownloadOptions,
+	writers ...io.Writer,
+) (VerifiedDownload, error) {
+	start := time.Now()
+
+	p.client.logger.Debug("Copying file from download cache", logger.Data{"path": cachePath})
+
+	f, err := os.Open(cachePath)
+	if err != nil {
+		return VerifiedDownload{}, err
+	}
+	defer f.Close()
+
+	writers = append([]io.Writer{}, writers...)
+
+	verifier := options.checksumVerifier(pf)
+	if verifier != nil {
+		writers = append(writers, verifier)
+	}
+
+	if options.Progress != nil {
+		info, err := f.Stat()
+		if err != nil {
+			return VerifiedDownload{}, err
+		}
+
+		writers = append(writers, &progressWriter{
+			total:    info.Size(),
+			progress: options.Progress,
+		})
+	}
+
+	n, err := io.Copy(io.MultiWriter(writers...), f)
+	if err != nil {
+		return VerifiedDownload{}, err
+	}
+
+	verified := VerifiedDownload{Size: n}
+	if verifier != nil {
+		err = verifier.verify()
+		if err != nil {
+			return VerifiedDownload{}, err
+		}
+
+		verified.Algorithm = verifier.algorithm
+		verified.Checksum = verifier.sum()
+	}
+
+	if options.OnComplete != nil {
+		options.OnComplete(newDownloadResult(verified, time.Since(start)))
+	}
+
+	return verified, nil
+}
+
+// downloadRevalidating caches product files that have no SHA256 by product
+// file ID, together with the Last-Modified of the download. A cached copy
+// is revalidated with If-Modified-Since and, if the file is unchanged,
+// served from the cache after checking any checksum Pivnet has for it.
+func (p ProductFilesService) downloadRevalidating(
+	pf ProductFile,
+	options DownloadOptions,
+	writers ...io.Writer,
+) (VerifiedDownload, error) {
+	cacheDir := p.client.downloadCacheDir
+	name := fmt.Sprintf("product-file-%d", pf.ID)
+	cachePath := filepath.Join(cacheDir, name)
+	lastModifiedPath := cachePath + lastModifiedSuffix
+
+	var ifModifiedSince string
+	if _, err := os.Stat(cachePath); err == nil {
+		contents, err := ioutil.ReadFile(lastModifiedPath)
+		if err == nil {
+			ifModifiedSince = strings.TrimSpace(string(contents))
+		}
+	}
+
+	err := os.MkdirAll(cacheDir, 0755)
+	if err != nil {
+		p.logCacheError("Failed to create download cache", cacheDir, err)
+		return p.fetch(pf, options, writers...)
+	}
+
+	tmp, err := ioutil.TempFile(cacheDir, "."+name+partialDownloadSuffix)
+	if err != nil {
+		p.logCacheError("Failed to create file in download cache", cacheDir, err)
+		return p.fetch(pf, options, writers...)
+	}
+	defer os.Remove(tmp.Name())
+
+	cache := &cacheWriter{writer: tmp}
+
+	verified, lastModified, err := p.fetchIfModifiedSince(
+		pf,
+		options,
+		ifModifiedSince,
+		append(append([]io.Writer{}, writers...), cache)...,
+	)
+
+	closeErr := tmp.Close()
+	if cache.err == nil {
+		cache.err = closeErr
+	}
+
+	if err == errNotModified {
+		var verifyErr error
+		if newChecksumVerifier(pf) != nil {
+			verifyErr = verifyLocalFile(pf, cachePath)
+		}
+
+		if verifyErr == nil {
+			return p.copyFromCache(cachePath, pf, options, writers...)
+		}
+
+		p.client.logger.Info(
+			"Removing corrupt file from download cache",
+			logger.Data{"path": cachePath, "error": verifyErr.Error()},
+		)
+		os.Remove(lastModifiedPath)
+		os.Remove(cachePath)
+
+		return p.fetch(pf, options, writers...)
+	}
+
+	if err != nil {
+		return verified, err
+	}
+
+	os.Remove(lastModifiedPath)
+
+	if lastModified == "" {
+		os.Remove(cachePath)
+		return verified, nil
+	}
+
+	if cache.err == nil {
+		cache.err = os.Rename(tmp.Name(), cachePath)
+	}
+
+	if cache.err == nil {
+		cache.err = ioutil.WriteFile(lastModifiedPath, []byte(lastModified), 0644)
+	}
+
+	if cache.err != nil {
+		p.logCacheError("Failed to add file to download cache", cachePath, cache.err)
+	}
+
+	return verified, nil
+}
+
+func (p ProductFilesService) logCacheError(message string, path string, err error) {
+	p.client.logger.Info(message, logger.Data{"path": path, "error": err.Error()})
+}
+
+// lastModifiedSuffix names the file holding the Last-Modified of a file in
+// the download cache.
+const lastModifiedSuffix = ".last-modified"
+
+func isSHA256(s string) bool {
+	b, err := hex.DecodeString(s)
+	return err == nil && len(b) == sha256.Size
+}
+
+// cacheWriter writes to the cache file until a write fails, after which it
+// discards its input so that the download itself is unaffected.
+type cacheWriter struct {
+	writer io.Writer
+	err    error
+}
+
+func (w *cacheWriter) Write(p []byte) (int, error) {
+	if w.err == nil {
+		_, w.err = w.writer.Write(p)
+	}
+	return len(p), nil
+}
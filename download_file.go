@@ -0,0 +1,164 @@
+package pivnet
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"os"
+)
+
+const partialDownloadSuffix = ".partial"
+
+// ErrOnDiskChecksumMismatch is returned when a file re-read from disk after
+// being downloaded does not match what was received.
+type ErrOnDiskChecksumMismatch struct {
+	Path     string
+	InFlight string
+	OnDisk   string
+}
+
+func (e ErrOnDiskChecksumMismatch) Error() string {
+	return fmt.Sprintf(
+		"%s changed after being written - sha256 of downloaded content: '%s', on disk: '%s'",
+		e.Path,
+		e.InFlight,
+		e.OnDisk,
+	)
+}
+
+// DownloadToFile downloads the product file to path. The content is
+// written to path with a ".partial" suffix and only renamed to path once it
+// has been downloaded and verified completely, so path never contains a
+// partial or corrupt download. The partial file is removed if the download
+// fails.
+//
+// If options.Connections is greater than one, the file is downloaded in that
+// many concurrent byte ranges, provided the server supports range requests.
+//
+// The partial file is given options.FileMode, regardless of the umask, before
+// any content is written, so the file has its final permissions as soon as
+// it appears at path.
+func (p ProductFilesService) DownloadToFile(
+	productSlug string,
+	releaseID int,
+	productFileID int,
+	path string,
+	options DownloadOptions,
+) error {
+	pf, err := p.GetForRelease(
+		productSlug,
+		releaseID,
+		productFileID,
+	)
+	if err != nil {
+		return err
+	}
+
+	return p.downloadToFile(productSlug, releaseID, pf, path, options)
+}
+
+func (p ProductFilesService) downloadToFile(
+	productSlug string,
+	releaseID int,
+	pf ProductFile,
+	path string,
+	options DownloadOptions,
+) error {
+	var err error
+	if options.ChecksumSidecar {
+		pf, err = p.withSidecarChecksum(productSlug, releaseID, pf)
+		if err != nil {
+			return err
+		}
+	}
+
+	err = requireChecksum(pf, options)
+	if err != nil {
+		return err
+	}
+
+	partialPath := path + partialDownloadSuffix
+
+	mode := options.FileMode
+	if mode == 0 {
+		mode = DefaultFileMode
+	}
+
+	f, err := os.OpenFile(partialPath, os.O_CREATE|os.O_TRUNC|os.O_RDWR, mode)
+	if err != nil {
+		return err
+	}
+
+	// OpenFile applies the umask, and leaves the mode of an existing file
+	// unchanged.
+	err = f.Chmod(mode)
+	if err != nil {
+		f.Close()
+		os.Remove(partialPath)
+		return err
+	}
+
+	var hashers []io.Writer
+
+	inFlight := sha256.New()
+	if options.VerifyAfterRename {
+		hashers = append(hashers, inFlight)
+	}
+
+	if options.Connections > 1 {
+		_, err = p.downloadRanges(pf, options, f, hashers...)
+	} else {
+		_, err = p.download(pf, options, append([]io.Writer{f}, hashers...)...)
+	}
+	if err == nil {
+		err = f.Sync()
+	}
+
+	closeErr := f.Close()
+	if err == nil {
+		err = closeErr
+	}
+
+	if err != nil {
+		os.Remove(partialPath)
+		return p.eulaNotAccepted(productSlug, releaseID, err)
+	}
+
+	err = os.Rename(partialPath, path)
+	if err != nil {
+		os.Remove(partialPath)
+		return err
+	}
+
+	if options.VerifyAfterRename {
+		return verifyOnDisk(path, hex.EncodeToString(inFlight.Sum(nil)))
+	}
+
+	return nil
+}
+
+func verifyOnDisk(path string, inFlight string) error {
+	f, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	hash := sha256.New()
+	_, err = io.Copy(hash, f)
+	if err != nil {
+		return err
+	}
+
+	onDisk := hex.EncodeToString(hash.Sum(nil))
+	if onDisk != inFlight {
+		return ErrOnDiskChecksumMismatch{
+			Path:     path,
+			InFlight: inFlight,
+			OnDisk:   onDisk,
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,92 @@
+package pivnet
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"sync"
+)
+
+// DownloadManager downloads product files into a directory for callers
+// that may ask for the same file concurrently, such as the handlers of a
+// server. Concurrent requests for the same product file share a single
+// download. Once it completes, or fails, the next request downloads the
+// file again; set ClientConfig.DownloadCacheDir to avoid fetching content
+// that has already been downloaded.
+type DownloadManager struct {
+	productFiles ProductFilesService
+	dir          string
+	options      DownloadOptions
+
+	mutex sync.Mutex
+	calls map[string]*downloadCall
+}
+
+type downloadCall struct {
+	done chan struct{}
+	path string
+	err  error
+}
+
+// NewDownloadManager returns a DownloadManager that downloads into dir with
+// the options, using DownloadToFile.
+func NewDownloadManager(client Client, dir string, options DownloadOptions) *DownloadManager {
+	return &DownloadManager{
+		productFiles: ProductFilesService{client: client},
+		dir:          dir,
+		options:      options,
+		calls:        map[string]*downloadCall{},
+	}
+}
+
+// Download downloads the product file to "<dir>/<product file ID>/<file
+// name>" and returns the path. If the same product file is already being
+// downloaded, Download waits for that download and returns its result,
+// including its error, instead of starting another.
+func (m *DownloadManager) Download(productSlug string, releaseID int, productFileID int) (string, error) {
+	key := fmt.Sprintf("%s/%d", productSlug, productFileID)
+
+	m.mutex.Lock()
+	if call, ok := m.calls[key]; ok {
+		m.mutex.Unlock()
+		<-call.done
+		return call.path, call.err
+	}
+
+	call := &downloadCall{done: make(chan struct{})}
+	m.calls[key] = call
+	m.mutex.Unlock()
+
+	defer func() {
+		m.mutex.Lock()
+		delete(m.calls, key)
+		m.mutex.Unlock()
+
+		close(call.done)
+	}()
+
+	call.path, call.err = m.download(productSlug, releaseID, productFileID)
+	return call.path, call.err
+}
+
+func (m *DownloadManager) download(productSlug string, releaseID int, productFileID int) (string, error) {
+	pf, err := m.productFiles.GetForRelease(productSlug, releaseID, productFileID)
+	if err != nil {
+		return "", err
+	}
+
+	dir := filepath.Join(m.dir, strconv.Itoa(pf.ID))
+	err = os.MkdirAll(dir, 0755)
+	if err != nil {
+		return "", err
+	}
+
+	path := filepath.Join(dir, localFileName(pf))
+	err = m.productFiles.downloadToFile(productSlug, releaseID, pf, path, m.options)
+	if err != nil {
+		return "", err
+	}
+
+	return path, nil
+}
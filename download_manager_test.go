@@ -0,0 +1,182 @@
+package pivnet_test
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"path/filepath"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"github.com/onsi/gomega/ghttp"
+	"github.com/pivotal-cf/go-pivnet"
+	"github.com/pivotal-cf/go-pivnet/logger/loggerfakes"
+)
+
+var _ = Describe("DownloadManager", func() {
+	var (
+		server  *ghttp.Server
+		manager *pivnet.DownloadManager
+		dir     string
+
+		releaseID     = 1234
+		productFileID = 2345
+		fileContents  = []byte("some file contents")
+
+		downloads      int32
+		downloadStatus int32
+		release        chan struct{}
+	)
+
+	BeforeEach(func() {
+		server = ghttp.NewServer()
+
+		var err error
+		dir, err = ioutil.TempDir("", "go-pivnet-download-manager")
+		Expect(err).NotTo(HaveOccurred())
+
+		client := pivnet.NewClient(pivnet.ClientConfig{
+			Host:      server.URL(),
+			Token:     "my-auth-token",
+			UserAgent: "pivnet-resource/0.1.0 (some-url)",
+		}, &loggerfakes.FakeLogger{})
+		manager = pivnet.NewDownloadManager(client, dir, pivnet.DownloadOptions{})
+
+		sum := sha256.Sum256(fileContents)
+		server.RouteToHandler("GET",
+			fmt.Sprintf("%s/products/%s/releases/%d/product_files/%d", apiPrefix, productSlug, releaseID, productFileID),
+			ghttp.RespondWithJSONEncoded(http.StatusOK, pivnet.ProductFileResponse{pivnet.ProductFile{
+				ID:           productFileID,
+				AWSObjectKey: "product-files/banana/some-file.tgz",
+				SHA256:       hex.EncodeToString(sum[:]),
+				Links: &pivnet.Links{
+					Download: map[string]string{"href": "/some/download/link"},
+				},
+			}}),
+		)
+
+		downloads = 0
+		downloadStatus = http.StatusOK
+		release = make(chan struct{})
+		close(release)
+
+		server.RouteToHandler("POST", apiPrefix+"/some/download/link",
+			func(w http.ResponseWriter, req *http.Request) {
+				atomic.AddInt32(&downloads, 1)
+				<-release
+
+				status := int(atomic.LoadInt32(&downloadStatus))
+				w.WriteHeader(status)
+				if status == http.StatusOK {
+					w.Write(fileContents)
+				} else {
+					w.Write([]byte(`{"message":"foo message"}`))
+				}
+			},
+		)
+	})
+
+	AfterEach(func() {
+		server.Close()
+		os.RemoveAll(dir)
+	})
+
+	downloadConcurrently := func(n int) ([]string, []error) {
+		paths := make([]string, n)
+		errs := make([]error, n)
+
+		var wg sync.WaitGroup
+		for i := 0; i < n; i++ {
+			wg.Add(1)
+			go func(i int) {
+				defer wg.Done()
+				defer GinkgoRecover()
+				paths[i], errs[i] = manager.Download(productSlug, releaseID, productFileID)
+			}(i)
+		}
+
+		Eventually(func() int32 { return atomic.LoadInt32(&downloads) }).Should(BeEquivalentTo(1))
+		time.Sleep(50 * time.Millisecond)
+		close(release)
+
+		wg.Wait()
+		return paths, errs
+	}
+
+	It("downloads the file into the directory", func() {
+		path, err := manager.Download(productSlug, releaseID, productFileID)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(path).To(Equal(filepath.Join(dir, "2345", "some-file.tgz")))
+
+		contents, err := ioutil.ReadFile(path)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(contents).To(Equal(fileContents))
+	})
+
+	Context("when the product file has no file name", func() {
+		BeforeEach(func() {
+			sum := sha256.Sum256(fileContents)
+			server.RouteToHandler("GET",
+				fmt.Sprintf("%s/products/%s/releases/%d/product_files/%d", apiPrefix, productSlug, releaseID, productFileID),
+				ghttp.RespondWithJSONEncoded(http.StatusOK, pivnet.ProductFileResponse{pivnet.ProductFile{
+					ID:     productFileID,
+					SHA256: hex.EncodeToString(sum[:]),
+					Links: &pivnet.Links{
+						Download: map[string]string{"href": "/some/download/link"},
+					},
+				}}),
+			)
+		})
+
+		It("names the file after the product file ID", func() {
+			path, err := manager.Download(productSlug, releaseID, productFileID)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(path).To(Equal(filepath.Join(dir, "2345", "product-file-2345")))
+
+			contents, err := ioutil.ReadFile(path)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(contents).To(Equal(fileContents))
+		})
+	})
+
+	It("shares a single download between concurrent requests for the file", func() {
+		release = make(chan struct{})
+
+		paths, errs := downloadConcurrently(5)
+
+		for i := range paths {
+			Expect(errs[i]).NotTo(HaveOccurred())
+			Expect(paths[i]).To(Equal(filepath.Join(dir, "2345", "some-file.tgz")))
+		}
+		Expect(atomic.LoadInt32(&downloads)).To(BeEquivalentTo(1))
+	})
+
+	Context("when the shared download fails", func() {
+		BeforeEach(func() {
+			downloadStatus = http.StatusTeapot
+		})
+
+		It("returns the error to every waiter and retries on the next request", func() {
+			release = make(chan struct{})
+
+			_, errs := downloadConcurrently(3)
+			for _, err := range errs {
+				Expect(err).To(MatchError(ContainSubstring("foo message")))
+			}
+			Expect(atomic.LoadInt32(&downloads)).To(BeEquivalentTo(1))
+
+			atomic.StoreInt32(&downloadStatus, http.StatusOK)
+
+			path, err := manager.Download(productSlug, releaseID, productFileID)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(path).To(BeAnExistingFile())
+			Expect(atomic.LoadInt32(&downloads)).To(BeEquivalentTo(2))
+		})
+	})
+})
@@ -0,0 +1,348 @@
+package pivnet
+
+import (
+	"bufio"
+	"crypto/sha256"
+	"fmt"
+	"hash"
+	"io"
+	"mime"
+	"net/http"
+	"os"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+const (
+	DefaultThroughputWindow = 30 * time.Second
+	DefaultFileMode         = os.FileMode(0644)
+)
+
+type DownloadOptions struct {
+	// Progress, if set, is called each time a chunk of the download has
+	// been written.
+	Progress func(DownloadProgress)
+
+	// MinThroughput aborts the download with ErrThroughputTooLow if fewer
+	// than MinThroughput bytes per second are received over a whole
+	// ThroughputWindow. Zero disables the check.
+	MinThroughput int64
+
+	// ThroughputWindow is the period over which MinThroughput is measured.
+	// Zero uses DefaultThroughputWindow.
+	ThroughputWindow time.Duration
+
+	// MaxBytesPerSec limits the rate at which the download is read. Zero
+	// means no limit.
+	MaxBytesPerSec int64
+
+	// VerifyAfterRename makes DownloadToFile re-read the file once it is in
+	// place and compare it with the content that was downloaded.
+	VerifyAfterRename bool
+
+	// OnComplete, if set, is called with the statistics of the download
+	// once it has completed and been verified.
+	OnComplete func(DownloadResult)
+
+	// Connections, if greater than one, makes DownloadToFile fetch the file
+	// as that many byte ranges in parallel and verify it once assembled.
+	// MaxBytesPerSec is shared between the connections. MinThroughput, the
+	// content type check and the download cache only apply to single-stream
+	// downloads, which are used when the server does not support ranges.
+	Connections int
+
+	// FileMode is the permissions of the file created by DownloadToFile.
+	// Zero uses DefaultFileMode.
+	FileMode os.FileMode
+
+	// ChecksumSidecar makes DownloadWithOptions and DownloadToFile verify a
+	// product file that Pivnet has no checksum for against a ".sha256"
+	// checksum file in the same release, if there is one. A checksum in
+	// Pivnet's metadata always takes precedence.
+	ChecksumSidecar bool
+
+	// RequireChecksum makes DownloadWithOptions and DownloadToFile fail
+	// without downloading if the product file has no SHA256, SHA1 or MD5
+	// checksum to verify against. By default such files are downloaded
+	// unverified.
+	RequireChecksum bool
+
+	// StreamRetry controls how a single-stream download is resumed if
+	// reading it fails part way through.
+	StreamRetry StreamRetryPolicy
+
+	// SkipContentTypeCheck allows downloads that look like an HTML or JSON
+	// error page, for product files that legitimately have that content.
+	SkipContentTypeCheck bool
+
+	// NewSHA256, if set, replaces sha256.New when verifying downloads
+	// against a SHA256 checksum, e.g. with an accelerated implementation.
+	// The hash must compute SHA256: its sum is compared with the product
+	// file's SHA256 checksum as is. It is not used for SHA1 or MD5
+	// checksums.
+	NewSHA256 func() hash.Hash
+
+	// ConnectTimeout limits how long connecting to the server, including
+	// the TLS handshake, may take for the requests that transfer the file,
+	// so that an unreachable host fails fast. Zero uses
+	// ClientConfig.DialTimeout and TLSHandshakeTimeout and a negative value
+	// means no limit.
+	ConnectTimeout time.Duration
+
+	// TransferTimeout limits how long each request that transfers the file
+	// may take, including reading the body. ClientConfig.RequestTimeout
+	// limits every call made by the client, so a value suited to API calls
+	// can abort a slow but steady download: set TransferTimeout to a
+	// negative value to lift the limit for downloads, or to a longer one.
+	// Zero uses ClientConfig.RequestTimeout.
+	//
+	// Neither timeout applies to a custom ClientConfig.Transport. Use
+	// MinThroughput to abort downloads that stall once connected.
+	TransferTimeout time.Duration
+}
+
+// withTimeouts returns the service with ConnectTimeout and TransferTimeout
+// applied to its client.
+func (o DownloadOptions) withTimeouts(p ProductFilesService) ProductFilesService {
+	if o.ConnectTimeout != 0 {
+		p.client.dialTimeout = timeoutOrDefault(o.ConnectTimeout, 0)
+		p.client.tlsHandshakeTimeout = timeoutOrDefault(o.ConnectTimeout, 0)
+	}
+
+	if o.TransferTimeout != 0 {
+		p.client.requestTimeout = timeoutOrDefault(o.TransferTimeout, 0)
+	}
+
+	return p
+}
+
+func (o DownloadOptions) newSHA256() hash.Hash {
+	if o.NewSHA256 != nil {
+		return o.NewSHA256()
+	}
+	return sha256.New()
+}
+
+// checksumVerifier is newChecksumVerifier, using NewSHA256 for SHA256
+// checksums.
+func (o DownloadOptions) checksumVerifier(pf ProductFile) *checksumVerifier {
+	verifier := newChecksumVerifier(pf)
+	if verifier != nil && verifier.algorithm == "sha256" && o.NewSHA256 != nil {
+		verifier.hash = o.NewSHA256()
+	}
+	return verifier
+}
+
+type DownloadProgress struct {
+	BytesWritten int64
+
+	// TotalBytes is zero if the size of the download is unknown.
+	TotalBytes int64
+}
+
+// DownloadResult summarises a completed download.
+type DownloadResult struct {
+	Bytes          int64
+	Duration       time.Duration
+	BytesPerSecond float64
+
+	// Resumptions is how many times the stream was resumed after a read
+	// failed. See DownloadOptions.StreamRetry.
+	Resumptions int
+
+	// Algorithm is the checksum the download was verified against:
+	// "sha256", "sha1" or "md5". Algorithm and Checksum are empty if the
+	// product file has no checksum to verify against.
+	Algorithm string
+	Checksum  string
+}
+
+func newDownloadResult(verified VerifiedDownload, duration time.Duration) DownloadResult {
+	result := DownloadResult{
+		Bytes:     verified.Size,
+		Duration:  duration,
+		Algorithm: verified.Algorithm,
+		Checksum:  verified.Checksum,
+	}
+
+	if duration > 0 {
+		result.BytesPerSecond = float64(verified.Size) / duration.Seconds()
+	}
+
+	return result
+}
+
+type ErrThroughputTooLow struct {
+	BytesPerSecond float64
+	MinThroughput  int64
+	Window         time.Duration
+}
+
+func (e ErrThroughputTooLow) Error() string {
+	return fmt.Sprintf(
+		"download aborted - received %.0f bytes/s over %s, minimum is %d bytes/s",
+		e.BytesPerSecond,
+		e.Window,
+		e.MinThroughput,
+	)
+}
+
+type ErrUnexpectedContentType struct {
+	ContentType string
+}
+
+func (e ErrUnexpectedContentType) Error() string {
+	return fmt.Sprintf(
+		"download returned '%s' content, which looks like an error page rather than the product file",
+		e.ContentType,
+	)
+}
+
+// checkDownloadContentType returns ErrUnexpectedContentType if the response
+// declares, or its first bytes look like, an HTML or JSON document.
+func checkDownloadContentType(header string, body *bufio.Reader) error {
+	mediaType, _, _ := mime.ParseMediaType(header)
+	if mediaType == "text/html" || mediaType == "application/json" {
+		return ErrUnexpectedContentType{ContentType: mediaType}
+	}
+
+	start, err := body.Peek(512)
+	if err != nil && err != io.EOF && err != bufio.ErrBufferFull {
+		return err
+	}
+
+	sniffed, _, _ := mime.ParseMediaType(http.DetectContentType(start))
+	if sniffed == "text/html" {
+		return ErrUnexpectedContentType{ContentType: sniffed}
+	}
+
+	return nil
+}
+
+// throttledReader limits reads so that on average no more than rate bytes
+// are read per second since the first read.
+type throttledReader struct {
+	reader io.Reader
+	rate   int64
+
+	start time.Time
+	read  int64
+}
+
+func (r *throttledReader) Read(p []byte) (int, error) {
+	if r.start.IsZero() {
+		r.start = time.Now()
+	}
+
+	if int64(len(p)) > r.rate {
+		p = p[:r.rate]
+	}
+
+	n, err := r.reader.Read(p)
+	r.read += int64(n)
+
+	due := time.Duration(float64(r.read) / float64(r.rate) * float64(time.Second))
+	if wait := due - time.Since(r.start); wait > 0 {
+		time.Sleep(wait)
+	}
+
+	return n, err
+}
+
+type progressWriter struct {
+	written  int64
+	total    int64
+	progress func(DownloadProgress)
+}
+
+func (w *progressWriter) Write(p []byte) (int, error) {
+	w.written += int64(len(p))
+	w.progress(DownloadProgress{
+		BytesWritten: w.written,
+		TotalBytes:   w.total,
+	})
+	return len(p), nil
+}
+
+// throughputWatchdog counts the bytes written to it and closes body if too
+// few arrive within a window, which aborts any read blocked on body.
+type throughputWatchdog struct {
+	minThroughput int64
+	window        time.Duration
+	body          io.Closer
+
+	bytes int64
+	done  chan struct{}
+
+	mutex sync.Mutex
+	err   error
+}
+
+func startThroughputWatchdog(
+	minThroughput int64,
+	window time.Duration,
+	body io.Closer,
+) *throughputWatchdog {
+	if window <= 0 {
+		window = DefaultThroughputWindow
+	}
+
+	w := &throughputWatchdog{
+		minThroughput: minThroughput,
+		window:        window,
+		body:          body,
+		done:          make(chan struct{}),
+	}
+
+	go w.run()
+
+	return w
+}
+
+func (w *throughputWatchdog) Write(p []byte) (int, error) {
+	atomic.AddInt64(&w.bytes, int64(len(p)))
+	return len(p), nil
+}
+
+func (w *throughputWatchdog) run() {
+	ticker := time.NewTicker(w.window)
+	defer ticker.Stop()
+
+	var last int64
+	for {
+		select {
+		case <-w.done:
+			return
+		case <-ticker.C:
+			current := atomic.LoadInt64(&w.bytes)
+			rate := float64(current-last) / w.window.Seconds()
+
+			if rate < float64(w.minThroughput) {
+				w.mutex.Lock()
+				w.err = ErrThroughputTooLow{
+					BytesPerSecond: rate,
+					MinThroughput:  w.minThroughput,
+					Window:         w.window,
+				}
+				w.mutex.Unlock()
+
+				w.body.Close()
+				return
+			}
+
+			last = current
+		}
+	}
+}
+
+// stop stops the watchdog and returns the error it aborted the download
+// with, if any.
+func (w *throughputWatchdog) stop() error {
+	close(w.done)
+
+	w.mutex.Lock()
+	defer w.mutex.Unlock()
+
+	return w.err
+}
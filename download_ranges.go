@@ -0,0 +1,262 @@
+package pivnet
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/pivotal-cf/go-pivnet/logger"
+)
+
+// downloadRanges downloads the product file into f using options.Connections
+// concurrent range requests against its signed download URL. Once all ranges
+// have been written the file is read back to verify its checksum, and its
+// content is also written to writers. If the server does not support range
+// requests the file is downloaded in a single stream instead.
+func (p ProductFilesService) downloadRanges(
+	pf ProductFile,
+	options DownloadOptions,
+	f *os.File,
+	writers ...io.Writer,
+) (VerifiedDownload, error) {
+	p = options.withTimeouts(p)
+
+	start := time.Now()
+
+	location, err := p.signedDownloadURL(pf)
+	if err != nil {
+		return VerifiedDownload{}, err
+	}
+
+	total, err := p.rangeDownloadSize(location)
+	if err != nil {
+		return VerifiedDownload{}, err
+	}
+
+	if total <= 0 {
+		p.client.logger.Debug(
+			"Range requests not supported, downloading in a single stream",
+			logger.Data{"product_file_id": pf.ID},
+		)
+		return p.download(pf, options, append([]io.Writer{f}, writers...)...)
+	}
+
+	err = f.Truncate(total)
+	if err != nil {
+		return VerifiedDownload{}, err
+	}
+
+	connections := int64(options.Connections)
+	if connections > total {
+		connections = total
+	}
+	chunk := (total + connections - 1) / connections
+	connections = (total + chunk - 1) / chunk
+
+	var rate int64
+	if options.MaxBytesPerSec > 0 {
+		rate = options.MaxBytesPerSec / connections
+		if rate < 1 {
+			rate = 1
+		}
+	}
+
+	var progress io.Writer
+	if options.Progress != nil {
+		progress = &syncWriter{writer: &progressWriter{
+			total:    total,
+			progress: options.Progress,
+		}}
+	}
+
+	p.client.logger.Debug("Downloading file in ranges", logger.Data{
+		"product_file_id": pf.ID,
+		"connections":     connections,
+	})
+
+	errs := make([]error, connections)
+	forEachConcurrently(int(connections), int(connections), func(i int) {
+		first := int64(i) * chunk
+		last := first + chunk - 1
+		if last >= total {
+			last = total - 1
+		}
+
+		errs[i] = p.downloadRange(location, first, last, rate, f, progress)
+	})
+
+	for _, err := range errs {
+		if err != nil {
+			return VerifiedDownload{}, err
+		}
+	}
+
+	verifier := options.checksumVerifier(pf)
+	if verifier != nil {
+		writers = append(append([]io.Writer{}, writers...), verifier)
+	}
+
+	_, err = io.Copy(io.MultiWriter(writers...), io.NewSectionReader(f, 0, total))
+	if err != nil {
+		return VerifiedDownload{}, err
+	}
+
+	verified := VerifiedDownload{Size: total}
+	if verifier != nil {
+		err = verifier.verify()
+		if err != nil {
+			return VerifiedDownload{}, err
+		}
+
+		verified.Algorithm = verifier.algorithm
+		verified.Checksum = verifier.sum()
+	}
+
+	if options.OnComplete != nil {
+		options.OnComplete(newDownloadResult(verified, time.Since(start)))
+	}
+
+	return verified, nil
+}
+
+// rangeDownloadSize requests the first byte of the download to find out
+// whether the server supports range requests. It returns the size of the
+// download, or zero if ranges are not supported.
+func (p ProductFilesService) rangeDownloadSize(location string) (int64, error) {
+	resp, err := p.rangeRequest(location, 0, 0)
+	if err != nil {
+		return 0, err
+	}
+	defer resp.Body.Close()
+
+	switch resp.StatusCode {
+	case http.StatusOK:
+		return 0, nil
+	case http.StatusPartialContent:
+		return contentRangeSize(resp.Header.Get("Content-Range")), nil
+	default:
+		return 0, fmt.Errorf("Unexpected status code %d requesting download range", resp.StatusCode)
+	}
+}
+
+func (p ProductFilesService) downloadRange(
+	location string,
+	first int64,
+	last int64,
+	rate int64,
+	f *os.File,
+	progress io.Writer,
+) error {
+	resp, err := p.rangeRequest(location, first, last)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusPartialContent {
+		return fmt.Errorf(
+			"Unexpected status code %d requesting download range %d-%d",
+			resp.StatusCode,
+			first,
+			last,
+		)
+	}
+
+	var reader io.Reader = resp.Body
+	if rate > 0 {
+		reader = &throttledReader{reader: reader, rate: rate}
+	}
+
+	var writer io.Writer = io.NewOffsetWriter(f, first)
+	if progress != nil {
+		writer = io.MultiWriter(writer, progress)
+	}
+
+	n, err := io.Copy(writer, io.LimitReader(reader, last-first+1))
+	if err != nil {
+		return err
+	}
+
+	if n != last-first+1 {
+		return fmt.Errorf("Received %d bytes for download range %d-%d", n, first, last)
+	}
+
+	return nil
+}
+
+// rangeRequest requests bytes first to last of the signed download URL, or
+// to the end if last is negative. The request goes to the storage provider
+// rather than Pivnet, so it does not carry the API token.
+func (p ProductFilesService) rangeRequest(location string, first int64, last int64) (*http.Response, error) {
+	header := http.Header{}
+	if last < 0 {
+		header.Set("Range", fmt.Sprintf("bytes=%d-", first))
+	} else {
+		header.Set("Range", fmt.Sprintf("bytes=%d-%d", first, last))
+	}
+
+	return p.signedURLRequest(location, header)
+}
+
+// signedURLRequest makes a GET request for a signed download URL with the
+// headers, without the client's authorization or retries.
+func (p ProductFilesService) signedURLRequest(location string, header http.Header) (*http.Response, error) {
+	req, err := http.NewRequest("GET", location, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	for key, values := range header {
+		req.Header[key] = values
+	}
+	req.Header.Set("User-Agent", p.client.userAgent)
+
+	ctx, cancel, err := p.client.requestContext()
+	if err != nil {
+		return nil, err
+	}
+
+	resp, err := p.client.do(p.client.httpClient(), req.WithContext(ctx))
+	if err != nil {
+		cancel()
+		return nil, err
+	}
+
+	resp.Body = &cancelingBody{ReadCloser: resp.Body, cancel: cancel}
+
+	return resp, nil
+}
+
+// contentRangeSize returns the complete length from a Content-Range header
+// such as "bytes 0-0/1234", or zero if it is not known.
+func contentRangeSize(header string) int64 {
+	i := strings.LastIndex(header, "/")
+	if !strings.HasPrefix(header, "bytes ") || i < 0 {
+		return 0
+	}
+
+	size, err := strconv.ParseInt(header[i+1:], 10, 64)
+	if err != nil || size < 0 {
+		return 0
+	}
+
+	return size
+}
+
+// syncWriter serialises writes from concurrent range downloads.
+type syncWriter struct {
+	mutex  sync.Mutex
+	writer io.Writer
+}
+
+func (w *syncWriter) Write(p []byte) (int, error) {
+	w.mutex.Lock()
+	defer w.mutex.Unlock()
+
+	return w.writer.Write(p)
+}
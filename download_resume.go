@@ -0,0 +1,174 @@
+package pivnet
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/pivotal-cf/go-pivnet/logger"
+)
+
+const (
+	DefaultStreamRetryMaxAttempts = 3
+	DefaultStreamRetryDelay       = 500 * time.Millisecond
+)
+
+// StreamRetryPolicy controls how a single-stream download is resumed when
+// reading its content fails part way through, for example because the
+// connection to the storage provider was reset. The download continues with
+// a range request from the last byte received; if that request fails, the
+// download fails with the original error. It is independent of
+// ClientConfig.RetryPolicy, which applies to the requests the client makes.
+type StreamRetryPolicy struct {
+	// MaxAttempts is the number of times a download is resumed. Zero uses
+	// DefaultStreamRetryMaxAttempts and a negative value disables resuming.
+	MaxAttempts int
+
+	// Delay is the wait before each resume. Zero uses
+	// DefaultStreamRetryDelay.
+	Delay time.Duration
+}
+
+func (p StreamRetryPolicy) maxAttempts() int {
+	if p.MaxAttempts == 0 {
+		return DefaultStreamRetryMaxAttempts
+	}
+	return p.MaxAttempts
+}
+
+func (p StreamRetryPolicy) delay() time.Duration {
+	if p.Delay <= 0 {
+		return DefaultStreamRetryDelay
+	}
+	return p.Delay
+}
+
+// resumingReader reads a download, reopening it at the current offset when a
+// read fails. Closing it stops any further resumes, which lets the
+// throughput watchdog abort the download.
+type resumingReader struct {
+	resume func(offset int64) (io.ReadCloser, error)
+	policy StreamRetryPolicy
+	logger logger.Logger
+
+	offset   int64
+	attempts int
+
+	mutex  sync.Mutex
+	body   io.ReadCloser
+	closed bool
+}
+
+func (r *resumingReader) Read(p []byte) (int, error) {
+	for {
+		n, err := r.current().Read(p)
+		r.offset += int64(n)
+
+		if err == nil || err == io.EOF || isContextError(err) || !r.resumable() {
+			return n, err
+		}
+
+		resumeErr := r.reopen(err)
+		if resumeErr != nil {
+			r.logger.Debug("Failed to resume download", logger.Data{
+				"offset": r.offset,
+				"error":  resumeErr.Error(),
+			})
+			return n, err
+		}
+
+		if n > 0 {
+			return n, nil
+		}
+	}
+}
+
+func isContextError(err error) bool {
+	return errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded)
+}
+
+func (r *resumingReader) Close() error {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	r.closed = true
+	return r.body.Close()
+}
+
+func (r *resumingReader) current() io.Reader {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	return r.body
+}
+
+func (r *resumingReader) resumable() bool {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	return !r.closed && r.attempts < r.policy.maxAttempts()
+}
+
+func (r *resumingReader) reopen(cause error) error {
+	r.attempts++
+
+	r.logger.Debug("Resuming download", logger.Data{
+		"offset":  r.offset,
+		"attempt": r.attempts,
+		"error":   cause.Error(),
+	})
+
+	r.mutex.Lock()
+	r.body.Close()
+	r.mutex.Unlock()
+
+	time.Sleep(r.policy.delay())
+
+	body, err := r.resume(r.offset)
+	if err != nil {
+		return err
+	}
+
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	if r.closed {
+		body.Close()
+		return fmt.Errorf("download was closed while resuming")
+	}
+
+	r.body = body
+	return nil
+}
+
+// resumeDownload requests the product file from offset onwards, using a
+// freshly signed download URL in case the previous one has expired.
+func (p ProductFilesService) resumeDownload(pf ProductFile, offset int64) (io.ReadCloser, error) {
+	location, err := p.signedDownloadURL(pf)
+	if err != nil {
+		return nil, err
+	}
+
+	resp, err := p.rangeRequest(location, offset, -1)
+	if err != nil {
+		return nil, err
+	}
+
+	contentRange := resp.Header.Get("Content-Range")
+	if resp.StatusCode != http.StatusPartialContent ||
+		!strings.HasPrefix(contentRange, fmt.Sprintf("bytes %d-", offset)) {
+		resp.Body.Close()
+		return nil, fmt.Errorf(
+			"Could not resume download at byte %d - status code %d",
+			offset,
+			resp.StatusCode,
+		)
+	}
+
+	return resp.Body, nil
+}
@@ -0,0 +1,720 @@
+package pivnet
+
+import (
+	"bufio"
+	"crypto/md5"
+	"crypto/sha1"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"hash"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"os"
+	"path"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/pivotal-cf/go-pivnet/logger"
+)
+
+type ErrChecksumMismatch struct {
+	Algorithm string
+	Expected  string
+	Actual    string
+}
+
+func (e ErrChecksumMismatch) Error() string {
+	return fmt.Sprintf(
+		"%s checksum mismatch - expected: '%s', actual: '%s'",
+		e.Algorithm,
+		e.Expected,
+		e.Actual,
+	)
+}
+
+type ErrSizeMismatch struct {
+	Expected int64
+	Actual   int64
+}
+
+func (e ErrSizeMismatch) Error() string {
+	return fmt.Sprintf(
+		"size mismatch - expected: %d bytes, actual: %d bytes",
+		e.Expected,
+		e.Actual,
+	)
+}
+
+// ErrEULANotAccepted is returned by downloads when the EULA of the release
+// has to be accepted, e.g. with EULAs.Accept, before the file can be
+// downloaded.
+type ErrEULANotAccepted struct {
+	ProductSlug string
+	ReleaseID   int
+	EULASlug    string
+	RequestID   string
+}
+
+func (e ErrEULANotAccepted) Error() string {
+	return withRequestID(fmt.Sprintf(
+		"The EULA '%s' for release %d of product '%s' has not been accepted",
+		e.EULASlug,
+		e.ReleaseID,
+		e.ProductSlug,
+	), e.RequestID)
+}
+
+func (e ErrEULANotAccepted) Unwrap() error {
+	err := newErrUnavailableForLegalReasons()
+	err.RequestID = e.RequestID
+	return err
+}
+
+// eulaNotAccepted converts err to ErrEULANotAccepted if it reports that the
+// EULA of the release has not been accepted. If the EULA cannot be
+// determined, err is returned unchanged.
+func (p ProductFilesService) eulaNotAccepted(productSlug string, releaseID int, err error) error {
+	legalErr, ok := err.(ErrUnavailableForLegalReasons)
+	if !ok {
+		return err
+	}
+
+	release, getErr := ReleasesService{client: p.client, l: p.client.logger}.Get(productSlug, releaseID)
+	if getErr != nil || release.EULA == nil {
+		return err
+	}
+
+	return ErrEULANotAccepted{
+		ProductSlug: productSlug,
+		ReleaseID:   releaseID,
+		EULASlug:    release.EULA.Slug,
+		RequestID:   legalErr.RequestID,
+	}
+}
+
+type checksumVerifier struct {
+	algorithm string
+	expected  string
+	hash      hash.Hash
+}
+
+// newChecksumVerifier returns a verifier for the strongest checksum present
+// on the product file, trying SHA256, SHA1 and then MD5, or nil if the
+// product file has no checksum.
+func newChecksumVerifier(pf ProductFile) *checksumVerifier {
+	switch {
+	case pf.SHA256 != "":
+		return &checksumVerifier{
+			algorithm: "sha256",
+			expected:  pf.SHA256,
+			hash:      sha256.New(),
+		}
+	case pf.SHA1 != "":
+		return &checksumVerifier{
+			algorithm: "sha1",
+			expected:  pf.SHA1,
+			hash:      sha1.New(),
+		}
+	case pf.MD5 != "":
+		return &checksumVerifier{
+			algorithm: "md5",
+			expected:  pf.MD5,
+			hash:      md5.New(),
+		}
+	default:
+		return nil
+	}
+}
+
+func (v *checksumVerifier) Write(p []byte) (int, error) {
+	return v.hash.Write(p)
+}
+
+func (v *checksumVerifier) sum() string {
+	return hex.EncodeToString(v.hash.Sum(nil))
+}
+
+func (v *checksumVerifier) verify() error {
+	actual := v.sum()
+	if !strings.EqualFold(actual, v.expected) {
+		return ErrChecksumMismatch{
+			Algorithm: v.algorithm,
+			Expected:  v.expected,
+			Actual:    actual,
+		}
+	}
+	return nil
+}
+
+// DownloadTo streams the product file to each of the provided writers in a
+// single pass. Writers receive each chunk in the order they are provided; if
+// any writer returns an error the download stops and that error is returned,
+// so later writers will not have received the failed chunk.
+//
+// The content is verified against the product file's SHA256 checksum, or
+// its SHA1 or MD5 checksum if no SHA256 is present, once the download
+// completes.
+//
+// If ClientConfig.DownloadCacheDir is set, a product file with a SHA256
+// checksum is copied from the cache when a verified copy is present there,
+// and added to the cache once it has been downloaded.
+//
+// Content that looks like an HTML or JSON error page is written like any
+// other, as some product files have such content; use DownloadWithOptions
+// to reject it with ErrUnexpectedContentType.
+func (p ProductFilesService) DownloadTo(
+	productSlug string,
+	releaseID int,
+	productFileID int,
+	writers ...io.Writer,
+) error {
+	pf, err := p.GetForRelease(
+		productSlug,
+		releaseID,
+		productFileID,
+	)
+	if err != nil {
+		return err
+	}
+
+	_, err = p.download(pf, DownloadOptions{SkipContentTypeCheck: true}, writers...)
+	return p.eulaNotAccepted(productSlug, releaseID, err)
+}
+
+// DownloadWithOptions behaves like DownloadTo, applying the provided
+// options to the download.
+func (p ProductFilesService) DownloadWithOptions(
+	productSlug string,
+	releaseID int,
+	productFileID int,
+	options DownloadOptions,
+	writers ...io.Writer,
+) error {
+	pf, err := p.GetForRelease(
+		productSlug,
+		releaseID,
+		productFileID,
+	)
+	if err != nil {
+		return err
+	}
+
+	if options.ChecksumSidecar {
+		pf, err = p.withSidecarChecksum(productSlug, releaseID, pf)
+		if err != nil {
+			return err
+		}
+	}
+
+	err = requireChecksum(pf, options)
+	if err != nil {
+		return err
+	}
+
+	_, err = p.download(pf, options, writers...)
+	return p.eulaNotAccepted(productSlug, releaseID, err)
+}
+
+// requireChecksum returns an error if options.RequireChecksum is set and the
+// product file has no checksum to verify the download against.
+func requireChecksum(pf ProductFile, options DownloadOptions) error {
+	if options.RequireChecksum && newChecksumVerifier(pf) == nil {
+		return fmt.Errorf("Product file %d has no checksum to verify against", pf.ID)
+	}
+	return nil
+}
+
+// VerifiedDownload describes content that was downloaded and matched the
+// checksum Pivnet records for the product file.
+type VerifiedDownload struct {
+	Size      int64
+	Algorithm string
+	Checksum  string
+}
+
+// VerifyDownload downloads the product file, discarding its content, and
+// verifies its size and checksum. It is intended for checking that a file
+// is available and intact without storing it, so it never uses the
+// download cache.
+func (p ProductFilesService) VerifyDownload(
+	productSlug string,
+	releaseID int,
+	productFileID int,
+) (VerifiedDownload, error) {
+	pf, err := p.GetForRelease(
+		productSlug,
+		releaseID,
+		productFileID,
+	)
+	if err != nil {
+		return VerifiedDownload{}, err
+	}
+
+	if newChecksumVerifier(pf) == nil {
+		return VerifiedDownload{}, fmt.Errorf("Product file %d has no checksum to verify against", pf.ID)
+	}
+
+	verified, err := p.fetch(pf, DownloadOptions{SkipContentTypeCheck: true}, ioutil.Discard)
+	if err != nil {
+		return VerifiedDownload{}, p.eulaNotAccepted(productSlug, releaseID, err)
+	}
+
+	if pf.Size > 0 && verified.Size != int64(pf.Size) {
+		return VerifiedDownload{}, ErrSizeMismatch{
+			Expected: int64(pf.Size),
+			Actual:   verified.Size,
+		}
+	}
+
+	return verified, nil
+}
+
+// VerifyFile checks a file that was downloaded out-of-band against the
+// size and checksum Pivnet records for the product file, without
+// downloading it again.
+func (p ProductFilesService) VerifyFile(
+	productSlug string,
+	releaseID int,
+	productFileID int,
+	filepath string,
+) error {
+	return p.VerifyFileWithOptions(productSlug, releaseID, productFileID, filepath, VerifyOptions{})
+}
+
+type VerifyOptions struct {
+	// ChecksumSidecar makes VerifyFileWithOptions verify a product file
+	// that Pivnet has no checksum for against a ".sha256" checksum file in
+	// the same release, if there is one. A checksum in Pivnet's metadata
+	// always takes precedence.
+	ChecksumSidecar bool
+}
+
+// VerifyFileWithOptions behaves like VerifyFile, applying the provided
+// options.
+func (p ProductFilesService) VerifyFileWithOptions(
+	productSlug string,
+	releaseID int,
+	productFileID int,
+	filepath string,
+	options VerifyOptions,
+) error {
+	pf, err := p.GetForRelease(
+		productSlug,
+		releaseID,
+		productFileID,
+	)
+	if err != nil {
+		return err
+	}
+
+	if options.ChecksumSidecar {
+		pf, err = p.withSidecarChecksum(productSlug, releaseID, pf)
+		if err != nil {
+			return err
+		}
+	}
+
+	return verifyLocalFile(pf, filepath)
+}
+
+func verifyLocalFile(pf ProductFile, filepath string) error {
+	verifier := newChecksumVerifier(pf)
+	if verifier == nil {
+		return fmt.Errorf("Product file %d has no checksum to verify against", pf.ID)
+	}
+
+	f, err := os.Open(filepath)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	n, err := io.Copy(verifier, f)
+	if err != nil {
+		return err
+	}
+
+	if pf.Size > 0 && n != int64(pf.Size) {
+		return ErrSizeMismatch{
+			Expected: int64(pf.Size),
+			Actual:   n,
+		}
+	}
+
+	return verifier.verify()
+}
+
+// SignedDownloadURL returns the URL Pivnet redirects to when downloading
+// the product file, without following the redirect. The EULA for the
+// release must already have been accepted.
+func (p ProductFilesService) SignedDownloadURL(
+	productSlug string,
+	releaseID int,
+	productFileID int,
+) (string, error) {
+	pf, err := p.GetForRelease(
+		productSlug,
+		releaseID,
+		productFileID,
+	)
+	if err != nil {
+		return "", err
+	}
+
+	location, err := p.signedDownloadURL(pf)
+	if err != nil {
+		return "", p.eulaNotAccepted(productSlug, releaseID, err)
+	}
+
+	return location, nil
+}
+
+type SignedDownload struct {
+	ProductFileID int
+	FileName      string
+	SHA256        string
+	MD5           string
+	URL           string
+
+	// ExpiresAt is when the signed URL stops working, or zero if it could
+	// not be determined from the URL.
+	ExpiresAt time.Time
+}
+
+// SignedDownloadURLs returns the signed download URL of every product file
+// of the release, e.g. to hand them to an external downloader. The EULA for
+// the release must already have been accepted.
+func (p ProductFilesService) SignedDownloadURLs(productSlug string, releaseID int) ([]SignedDownload, error) {
+	productFiles, err := p.ListForRelease(productSlug, releaseID)
+	if err != nil {
+		return nil, err
+	}
+
+	return p.signedDownloads(productSlug, releaseID, productFiles)
+}
+
+// SignedDownloadURLsByFileType returns the signed download URL of each
+// product file of the release whose file type matches fileType, ignoring
+// case, e.g. to mirror all of its "Software" files. It returns an empty slice
+// if no file matches. The EULA for the release must already have been
+// accepted.
+func (p ProductFilesService) SignedDownloadURLsByFileType(
+	productSlug string,
+	releaseID int,
+	fileType string,
+) ([]SignedDownload, error) {
+	productFiles, err := p.ListForReleaseByFileType(productSlug, releaseID, fileType)
+	if err != nil {
+		return nil, err
+	}
+
+	return p.signedDownloads(productSlug, releaseID, productFiles)
+}
+
+func (p ProductFilesService) signedDownloads(
+	productSlug string,
+	releaseID int,
+	productFiles []ProductFile,
+) ([]SignedDownload, error) {
+	signedDownloads := []SignedDownload{}
+	for _, pf := range productFiles {
+		location, err := p.signedDownloadURL(pf)
+		if err != nil {
+			return nil, p.eulaNotAccepted(productSlug, releaseID, err)
+		}
+
+		signedDownloads = append(signedDownloads, SignedDownload{
+			ProductFileID: pf.ID,
+			FileName:      productFileName(pf),
+			SHA256:        pf.SHA256,
+			MD5:           pf.MD5,
+			URL:           location,
+			ExpiresAt:     signedURLExpiry(location),
+		})
+	}
+
+	return signedDownloads, nil
+}
+
+func (p ProductFilesService) signedDownloadURL(pf ProductFile) (string, error) {
+	downloadLink, err := pf.DownloadLink()
+	if err != nil {
+		return "", err
+	}
+
+	if location, ok := p.client.signedURLs.get(pf.ID, p.client.clock()); ok {
+		return location, nil
+	}
+
+	client := p.client
+	client.disableRedirects = true
+	client.auditLogger = nil
+
+	resp, err := client.MakeRequest(
+		"POST",
+		downloadLink,
+		http.StatusFound,
+		nil,
+	)
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+
+	location := resp.Header.Get("Location")
+	if location == "" {
+		return "", fmt.Errorf("Could not determine signed download URL - no Location header in response")
+	}
+
+	p.client.signedURLs.set(pf.ID, location)
+
+	return location, nil
+}
+
+// signedURLExpiry reads the expiry of an S3 pre-signed URL, supporting
+// both signature version 4 and version 2 query parameters.
+func signedURLExpiry(rawURL string) time.Time {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return time.Time{}
+	}
+
+	query := u.Query()
+
+	if date, expires := query.Get("X-Amz-Date"), query.Get("X-Amz-Expires"); date != "" && expires != "" {
+		signedAt, err := time.Parse("20060102T150405Z", date)
+		if err != nil {
+			return time.Time{}
+		}
+
+		seconds, err := strconv.Atoi(expires)
+		if err != nil {
+			return time.Time{}
+		}
+
+		return signedAt.Add(time.Duration(seconds) * time.Second)
+	}
+
+	if expires := query.Get("Expires"); expires != "" {
+		seconds, err := strconv.ParseInt(expires, 10, 64)
+		if err != nil {
+			return time.Time{}
+		}
+
+		return time.Unix(seconds, 0).UTC()
+	}
+
+	return time.Time{}
+}
+
+// DownloadMatching downloads the single product file of the release whose
+// file name (the base name of its AWS object key) or name matches the glob
+// pattern. It returns an error if no file or more than one file matches.
+func (p ProductFilesService) DownloadMatching(
+	productSlug string,
+	releaseID int,
+	namePattern string,
+	writer io.Writer,
+) error {
+	if _, err := path.Match(namePattern, ""); err != nil {
+		return err
+	}
+
+	productFiles, err := p.ListForRelease(productSlug, releaseID)
+	if err != nil {
+		return err
+	}
+
+	var matches []ProductFile
+	for _, pf := range productFiles {
+		if productFileMatches(pf, namePattern) {
+			matches = append(matches, pf)
+		}
+	}
+
+	switch len(matches) {
+	case 0:
+		return newErrNotFound(fmt.Sprintf(
+			"No product file matching '%s' found for release %d",
+			namePattern,
+			releaseID,
+		))
+	case 1:
+		return p.DownloadTo(productSlug, releaseID, matches[0].ID, writer)
+	default:
+		var names []string
+		for _, pf := range matches {
+			names = append(names, productFileName(pf))
+		}
+
+		return fmt.Errorf(
+			"Multiple product files matching '%s' found for release %d: %s",
+			namePattern,
+			releaseID,
+			strings.Join(names, ", "),
+		)
+	}
+}
+
+func productFileName(pf ProductFile) string {
+	if pf.AWSObjectKey == "" {
+		return pf.Name
+	}
+	return path.Base(pf.AWSObjectKey)
+}
+
+// localFileName is the name a product file is written to on disk: the base
+// of its file name, or one derived from its ID if that does not name a file.
+func localFileName(pf ProductFile) string {
+	name := filepath.Base(productFileName(pf))
+	if name == "." || name == ".." || name == string(filepath.Separator) {
+		return fmt.Sprintf("product-file-%d", pf.ID)
+	}
+	return name
+}
+
+func productFileMatches(pf ProductFile, pattern string) bool {
+	if matched, _ := path.Match(pattern, productFileName(pf)); matched {
+		return true
+	}
+
+	matched, _ := path.Match(pattern, pf.Name)
+	return matched
+}
+
+// fetch downloads the product file from Pivnet, streaming it to the writers,
+// and verifies its checksum if it has one. The returned VerifiedDownload has
+// no checksum if there was none to verify against.
+func (p ProductFilesService) fetch(
+	pf ProductFile,
+	options DownloadOptions,
+	writers ...io.Writer,
+) (VerifiedDownload, error) {
+	verified, _, err := p.fetchIfModifiedSince(pf, options, "", writers...)
+	return verified, err
+}
+
+// errNotModified is returned by fetchIfModifiedSince when the file has not
+// changed since the given time.
+var errNotModified = errors.New("product file has not been modified")
+
+// fetchIfModifiedSince is fetch, sending If-Modified-Since when
+// ifModifiedSince is not empty. It also returns the Last-Modified header of
+// the response.
+func (p ProductFilesService) fetchIfModifiedSince(
+	pf ProductFile,
+	options DownloadOptions,
+	ifModifiedSince string,
+	writers ...io.Writer,
+) (VerifiedDownload, string, error) {
+	start := time.Now()
+
+	downloadLink, err := pf.DownloadLink()
+	if err != nil {
+		return VerifiedDownload{}, "", err
+	}
+
+	p.client.logger.Debug("Downloading file", logger.Data{"downloadLink": downloadLink})
+
+	resp, err := p.openDownload(pf, downloadLink, ifModifiedSince)
+	if err != nil {
+		return VerifiedDownload{}, "", err
+	}
+
+	if resp.StatusCode == http.StatusNotModified {
+		resp.Body.Close()
+		return VerifiedDownload{}, "", errNotModified
+	}
+
+	lastModified := resp.Header.Get("Last-Modified")
+
+	stream := &resumingReader{
+		body: resp.Body,
+		resume: func(offset int64) (io.ReadCloser, error) {
+			return p.resumeDownload(pf, offset)
+		},
+		policy: options.StreamRetry,
+		logger: p.client.logger,
+	}
+	defer stream.Close()
+
+	verifier := options.checksumVerifier(pf)
+	if verifier != nil {
+		writers = append(writers, verifier)
+	}
+
+	if options.Progress != nil {
+		total := int64(pf.Size)
+		if total <= 0 && resp.ContentLength > 0 {
+			total = resp.ContentLength
+		}
+
+		writers = append(writers, &progressWriter{
+			total:    total,
+			progress: options.Progress,
+		})
+	}
+
+	var watchdog *throughputWatchdog
+	if options.MinThroughput > 0 {
+		watchdog = startThroughputWatchdog(
+			options.MinThroughput,
+			options.ThroughputWindow,
+			stream,
+		)
+		writers = append(writers, watchdog)
+	}
+
+	p.client.logger.Debug("Copying body", logger.Data{"downloadLink": downloadLink})
+
+	var n int64
+	body := bufio.NewReader(stream)
+	if !options.SkipContentTypeCheck {
+		err = checkDownloadContentType(resp.Header.Get("Content-Type"), body)
+	}
+
+	if err == nil {
+		var reader io.Reader = body
+		if options.MaxBytesPerSec > 0 {
+			reader = &throttledReader{reader: body, rate: options.MaxBytesPerSec}
+		}
+
+		n, err = io.Copy(io.MultiWriter(writers...), reader)
+	}
+
+	if watchdog != nil {
+		watchdogErr := watchdog.stop()
+		if err != nil && watchdogErr != nil {
+			err = watchdogErr
+		}
+	}
+
+	if err != nil {
+		return VerifiedDownload{}, "", err
+	}
+
+	verified := VerifiedDownload{Size: n}
+	if verifier != nil {
+		err = verifier.verify()
+		if err != nil {
+			return VerifiedDownload{}, "", err
+		}
+
+		verified.Algorithm = verifier.algorithm
+		verified.Checksum = verifier.sum()
+	}
+
+	if options.OnComplete != nil {
+		result := newDownloadResult(verified, time.Since(start))
+		result.Resumptions = stream.attempts
+		options.OnComplete(result)
+	}
+
+	return verified, lastModified, nil
+}
@@ -0,0 +1,2050 @@
+package pivnet_test
+
+import (
+	"bytes"
+	"crypto/md5"
+	"crypto/sha1"
+	"crypto/sha256"
+	"crypto/sha512"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"hash"
+	"io/ioutil"
+	"net"
+	"net/http"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"github.com/onsi/gomega/ghttp"
+	"github.com/pivotal-cf/go-pivnet"
+	"github.com/pivotal-cf/go-pivnet/logger"
+	"github.com/pivotal-cf/go-pivnet/logger/loggerfakes"
+)
+
+var _ = Describe("PivnetClient - downloads", func() {
+	var (
+		server     *ghttp.Server
+		client     pivnet.Client
+		token      string
+		apiAddress string
+		userAgent  string
+
+		newClientConfig pivnet.ClientConfig
+		fakeLogger      logger.Logger
+
+		releaseID     int
+		productFileID int
+
+		downloadLink string
+		fileContents []byte
+
+		productFile pivnet.ProductFile
+
+		downloadLinkResponseStatusCode int
+	)
+
+	BeforeEach(func() {
+		server = ghttp.NewServer()
+		apiAddress = server.URL()
+		token = "my-auth-token"
+		userAgent = "pivnet-resource/0.1.0 (some-url)"
+
+		fakeLogger = &loggerfakes.FakeLogger{}
+		newClientConfig = pivnet.ClientConfig{
+			Host:      apiAddress,
+			Token:     token,
+			UserAgent: userAgent,
+		}
+
+		releaseID = 1234
+		productFileID = 2345
+
+		downloadLink = "/some/download/link"
+		fileContents = []byte("some file contents")
+
+		sha256Sum := sha256.Sum256(fileContents)
+
+		productFile = pivnet.ProductFile{
+			ID:     productFileID,
+			SHA256: hex.EncodeToString(sha256Sum[:]),
+			Links: &pivnet.Links{
+				Download: map[string]string{
+					"href": downloadLink,
+				},
+			},
+		}
+
+		downloadLinkResponseStatusCode = http.StatusOK
+	})
+
+	appendDownloadHandlers := func() {
+		server.AppendHandlers(
+			ghttp.CombineHandlers(
+				ghttp.VerifyRequest(
+					"GET",
+					fmt.Sprintf(
+						"%s/products/%s/releases/%d/product_files/%d",
+						apiPrefix,
+						productSlug,
+						releaseID,
+						productFileID,
+					),
+				),
+				ghttp.RespondWithJSONEncoded(http.StatusOK, pivnet.ProductFileResponse{productFile}),
+			),
+			ghttp.CombineHandlers(
+				ghttp.VerifyRequest("POST", fmt.Sprintf(
+					"%s%s",
+					apiPrefix,
+					downloadLink,
+				)),
+				ghttp.RespondWith(downloadLinkResponseStatusCode, fileContents),
+			),
+		)
+	}
+
+	JustBeforeEach(func() {
+		client = pivnet.NewClient(newClientConfig, fakeLogger)
+	})
+
+	AfterEach(func() {
+		server.Close()
+	})
+
+	Describe("DownloadTo", func() {
+		JustBeforeEach(func() {
+			appendDownloadHandlers()
+		})
+
+		It("writes the file contents to every writer", func() {
+			first := bytes.NewBuffer(nil)
+			second := bytes.NewBuffer(nil)
+
+			err := client.ProductFiles.DownloadTo(
+				productSlug,
+				releaseID,
+				productFileID,
+				first,
+				second,
+			)
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(first.Bytes()).To(Equal(fileContents))
+			Expect(second.Bytes()).To(Equal(fileContents))
+		})
+
+		Context("when the product file is an HTML document", func() {
+			BeforeEach(func() {
+				fileContents = []byte("<!DOCTYPE html><html><body>Release notes</body></html>")
+				sha256Sum := sha256.Sum256(fileContents)
+				productFile.SHA256 = hex.EncodeToString(sha256Sum[:])
+			})
+
+			It("downloads the content without checking its type", func() {
+				buffer := bytes.NewBuffer(nil)
+
+				err := client.ProductFiles.DownloadTo(
+					productSlug,
+					releaseID,
+					productFileID,
+					buffer,
+				)
+				Expect(err).NotTo(HaveOccurred())
+				Expect(buffer.Bytes()).To(Equal(fileContents))
+			})
+		})
+
+		Context("when the SHA256 checksum does not match", func() {
+			BeforeEach(func() {
+				productFile.SHA256 = "abcdef"
+			})
+
+			It("returns an ErrChecksumMismatch", func() {
+				err := client.ProductFiles.DownloadTo(
+					productSlug,
+					releaseID,
+					productFileID,
+					bytes.NewBuffer(nil),
+				)
+				Expect(err).To(HaveOccurred())
+
+				mismatch, ok := err.(pivnet.ErrChecksumMismatch)
+				Expect(ok).To(BeTrue())
+				Expect(mismatch.Algorithm).To(Equal("sha256"))
+				Expect(mismatch.Expected).To(Equal("abcdef"))
+			})
+		})
+
+		Context("when only SHA1 and MD5 checksums are present", func() {
+			BeforeEach(func() {
+				sha1Sum := sha1.Sum(fileContents)
+
+				productFile.SHA256 = ""
+				productFile.SHA1 = hex.EncodeToString(sha1Sum[:])
+				productFile.MD5 = "not-the-md5"
+			})
+
+			It("verifies against the SHA1 checksum", func() {
+				err := client.ProductFiles.DownloadTo(
+					productSlug,
+					releaseID,
+					productFileID,
+					bytes.NewBuffer(nil),
+				)
+				Expect(err).NotTo(HaveOccurred())
+			})
+
+			Context("when the SHA1 checksum does not match", func() {
+				BeforeEach(func() {
+					productFile.SHA1 = "abcdef"
+				})
+
+				It("returns an ErrChecksumMismatch", func() {
+					err := client.ProductFiles.DownloadTo(
+						productSlug,
+						releaseID,
+						productFileID,
+						bytes.NewBuffer(nil),
+					)
+					Expect(err).To(MatchError(ContainSubstring("sha1 checksum mismatch")))
+				})
+			})
+		})
+
+		Context("when only an MD5 checksum is present", func() {
+			BeforeEach(func() {
+				md5Sum := md5.Sum(fileContents)
+
+				productFile.SHA256 = ""
+				productFile.MD5 = hex.EncodeToString(md5Sum[:])
+			})
+
+			It("verifies against the MD5 checksum", func() {
+				err := client.ProductFiles.DownloadTo(
+					productSlug,
+					releaseID,
+					productFileID,
+					bytes.NewBuffer(nil),
+				)
+				Expect(err).NotTo(HaveOccurred())
+			})
+
+			Context("when the MD5 checksum does not match", func() {
+				BeforeEach(func() {
+					productFile.MD5 = "abcdef"
+				})
+
+				It("returns an ErrChecksumMismatch", func() {
+					err := client.ProductFiles.DownloadTo(
+						productSlug,
+						releaseID,
+						productFileID,
+						bytes.NewBuffer(nil),
+					)
+					Expect(err).To(MatchError(ContainSubstring("md5 checksum mismatch")))
+				})
+			})
+		})
+
+		Context("when a writer returns an error", func() {
+			It("stops the download and returns the error", func() {
+				second := bytes.NewBuffer(nil)
+
+				err := client.ProductFiles.DownloadTo(
+					productSlug,
+					releaseID,
+					productFileID,
+					errWriter{},
+					second,
+				)
+				Expect(err).To(MatchError("error writing"))
+
+				Expect(second.Len()).To(BeZero())
+			})
+		})
+
+		Context("when the download request returns an error", func() {
+			BeforeEach(func() {
+				downloadLinkResponseStatusCode = http.StatusTeapot
+				fileContents = []byte(`{"message":"foo message"}`)
+			})
+
+			It("forwards the error", func() {
+				err := client.ProductFiles.DownloadTo(
+					productSlug,
+					releaseID,
+					productFileID,
+					bytes.NewBuffer(nil),
+				)
+				Expect(err).To(MatchError(ContainSubstring("foo message")))
+			})
+		})
+
+		Context("when the EULA has not been accepted", func() {
+			BeforeEach(func() {
+				downloadLinkResponseStatusCode = http.StatusUnavailableForLegalReasons
+				fileContents = []byte(`{"message":"eula not accepted"}`)
+			})
+
+			It("returns an ErrEULANotAccepted with the EULA slug", func() {
+				server.AppendHandlers(
+					ghttp.CombineHandlers(
+						ghttp.VerifyRequest("GET", fmt.Sprintf("%s/products/%s/releases/%d", apiPrefix, productSlug, releaseID)),
+						ghttp.RespondWith(http.StatusOK, `{"id":1234,"eula":{"slug":"some-eula"}}`),
+					),
+				)
+
+				err := client.ProductFiles.DownloadTo(
+					productSlug,
+					releaseID,
+					productFileID,
+					bytes.NewBuffer(nil),
+				)
+				Expect(err).To(Equal(pivnet.ErrEULANotAccepted{
+					ProductSlug: productSlug,
+					ReleaseID:   releaseID,
+					EULASlug:    "some-eula",
+				}))
+
+				var legalErr pivnet.ErrUnavailableForLegalReasons
+				Expect(errors.As(err, &legalErr)).To(BeTrue())
+			})
+
+			Context("when the release cannot be fetched", func() {
+				It("returns the original error", func() {
+					server.AppendHandlers(
+						ghttp.RespondWith(http.StatusTeapot, `{"message":"foo message"}`),
+					)
+
+					err := client.ProductFiles.DownloadTo(
+						productSlug,
+						releaseID,
+						productFileID,
+						bytes.NewBuffer(nil),
+					)
+					Expect(err).To(BeAssignableToTypeOf(pivnet.ErrUnavailableForLegalReasons{}))
+				})
+			})
+		})
+	})
+
+	Describe("DownloadWithOptions", func() {
+		var (
+			options pivnet.DownloadOptions
+		)
+
+		BeforeEach(func() {
+			options = pivnet.DownloadOptions{}
+		})
+
+		Context("when a progress callback is provided", func() {
+			var (
+				progress []pivnet.DownloadProgress
+			)
+
+			BeforeEach(func() {
+				progress = nil
+				productFile.Size = len(fileContents)
+
+				options.Progress = func(p pivnet.DownloadProgress) {
+					progress = append(progress, p)
+				}
+			})
+
+			It("reports the bytes written", func() {
+				appendDownloadHandlers()
+
+				err := client.ProductFiles.DownloadWithOptions(
+					productSlug,
+					releaseID,
+					productFileID,
+					options,
+					ioutil.Discard,
+				)
+				Expect(err).NotTo(HaveOccurred())
+
+				Expect(progress).NotTo(BeEmpty())
+				Expect(progress[len(progress)-1]).To(Equal(pivnet.DownloadProgress{
+					BytesWritten: int64(len(fileContents)),
+					TotalBytes:   int64(len(fileContents)),
+				}))
+			})
+		})
+
+		Context("when a completion callback is provided", func() {
+			var (
+				result    pivnet.DownloadResult
+				completed int
+			)
+
+			BeforeEach(func() {
+				result = pivnet.DownloadResult{}
+				completed = 0
+
+				newClientConfig.Clock = func() time.Time {
+					return time.Date(2016, 1, 2, 3, 4, 5, 0, time.UTC)
+				}
+
+				options.OnComplete = func(r pivnet.DownloadResult) {
+					result = r
+					completed++
+				}
+			})
+
+			It("reports the statistics of the download, timed regardless of the client's clock", func() {
+				appendDownloadHandlers()
+
+				err := client.ProductFiles.DownloadWithOptions(
+					productSlug,
+					releaseID,
+					productFileID,
+					options,
+					ioutil.Discard,
+				)
+				Expect(err).NotTo(HaveOccurred())
+
+				Expect(completed).To(Equal(1))
+				Expect(result.Duration).To(BeNumerically(">", 0))
+				Expect(result).To(Equal(pivnet.DownloadResult{
+					Bytes:          int64(len(fileContents)),
+					Duration:       result.Duration,
+					BytesPerSecond: float64(len(fileContents)) / result.Duration.Seconds(),
+					Algorithm:      "sha256",
+					Checksum:       productFile.SHA256,
+				}))
+			})
+
+			Context("when the download fails", func() {
+				BeforeEach(func() {
+					productFile.SHA256 = "not-the-checksum"
+				})
+
+				It("does not call the callback", func() {
+					appendDownloadHandlers()
+
+					err := client.ProductFiles.DownloadWithOptions(
+						productSlug,
+						releaseID,
+						productFileID,
+						options,
+						ioutil.Discard,
+					)
+					Expect(err).To(HaveOccurred())
+					Expect(completed).To(BeZero())
+				})
+			})
+
+			Context("when the product file only has an MD5 checksum", func() {
+				BeforeEach(func() {
+					md5Sum := md5.Sum(fileContents)
+
+					productFile.SHA256 = ""
+					productFile.MD5 = hex.EncodeToString(md5Sum[:])
+				})
+
+				It("reports that MD5 was used to verify the download", func() {
+					appendDownloadHandlers()
+
+					err := client.ProductFiles.DownloadWithOptions(
+						productSlug,
+						releaseID,
+						productFileID,
+						options,
+						ioutil.Discard,
+					)
+					Expect(err).NotTo(HaveOccurred())
+
+					Expect(result.Algorithm).To(Equal("md5"))
+					Expect(result.Checksum).To(Equal(productFile.MD5))
+				})
+			})
+		})
+
+		Context("when RequireChecksum is set", func() {
+			BeforeEach(func() {
+				options.RequireChecksum = true
+			})
+
+			Context("when the product file has no checksum", func() {
+				BeforeEach(func() {
+					productFile.SHA256 = ""
+				})
+
+				It("returns an error without downloading", func() {
+					appendDownloadHandlers()
+
+					err := client.ProductFiles.DownloadWithOptions(
+						productSlug,
+						releaseID,
+						productFileID,
+						options,
+						ioutil.Discard,
+					)
+					Expect(err).To(MatchError(fmt.Sprintf("Product file %d has no checksum to verify against", productFileID)))
+					Expect(server.ReceivedRequests()).To(HaveLen(1))
+				})
+			})
+		})
+
+		Context("when a SHA256 hash factory is provided", func() {
+			var (
+				calls int
+			)
+
+			BeforeEach(func() {
+				calls = 0
+				options.NewSHA256 = func() hash.Hash {
+					calls++
+					return sha256.New()
+				}
+			})
+
+			It("verifies the download with the injected hash", func() {
+				appendDownloadHandlers()
+
+				err := client.ProductFiles.DownloadWithOptions(
+					productSlug,
+					releaseID,
+					productFileID,
+					options,
+					ioutil.Discard,
+				)
+				Expect(err).NotTo(HaveOccurred())
+				Expect(calls).To(Equal(1))
+			})
+
+			Context("when the injected hash computes a different digest", func() {
+				BeforeEach(func() {
+					options.NewSHA256 = func() hash.Hash {
+						return sha512.New512_256()
+					}
+				})
+
+				It("returns an ErrChecksumMismatch", func() {
+					appendDownloadHandlers()
+
+					err := client.ProductFiles.DownloadWithOptions(
+						productSlug,
+						releaseID,
+						productFileID,
+						options,
+						ioutil.Discard,
+					)
+					Expect(err).To(BeAssignableToTypeOf(pivnet.ErrChecksumMismatch{}))
+				})
+			})
+
+			Context("when the product file only has an MD5 checksum", func() {
+				BeforeEach(func() {
+					md5Sum := md5.Sum(fileContents)
+					productFile.SHA256 = ""
+					productFile.MD5 = hex.EncodeToString(md5Sum[:])
+				})
+
+				It("does not use the injected hash", func() {
+					appendDownloadHandlers()
+
+					err := client.ProductFiles.DownloadWithOptions(
+						productSlug,
+						releaseID,
+						productFileID,
+						options,
+						ioutil.Discard,
+					)
+					Expect(err).NotTo(HaveOccurred())
+					Expect(calls).To(Equal(0))
+				})
+			})
+		})
+
+		Context("when the download returns an error page", func() {
+			var (
+				contentType string
+			)
+
+			BeforeEach(func() {
+				productFile.SHA256 = ""
+				contentType = "application/octet-stream"
+				fileContents = []byte("<!DOCTYPE html><html><body>Expired</body></html>")
+			})
+
+			appendErrorPageHandlers := func() {
+				server.AppendHandlers(
+					ghttp.RespondWithJSONEncoded(http.StatusOK, pivnet.ProductFileResponse{productFile}),
+					ghttp.RespondWith(http.StatusOK, fileContents, http.Header{"Content-Type": []string{contentType}}),
+				)
+			}
+
+			It("detects HTML content and returns ErrUnexpectedContentType", func() {
+				appendErrorPageHandlers()
+
+				buffer := bytes.NewBuffer(nil)
+				err := client.ProductFiles.DownloadWithOptions(
+					productSlug,
+					releaseID,
+					productFileID,
+					options,
+					buffer,
+				)
+				Expect(err).To(Equal(pivnet.ErrUnexpectedContentType{ContentType: "text/html"}))
+				Expect(buffer.Len()).To(BeZero())
+			})
+
+			Context("when the response declares JSON", func() {
+				BeforeEach(func() {
+					contentType = "application/json; charset=utf-8"
+					fileContents = []byte(`{"error":"expired"}`)
+				})
+
+				It("returns ErrUnexpectedContentType", func() {
+					appendErrorPageHandlers()
+
+					err := client.ProductFiles.DownloadWithOptions(
+						productSlug,
+						releaseID,
+						productFileID,
+						options,
+						ioutil.Discard,
+					)
+					Expect(err).To(Equal(pivnet.ErrUnexpectedContentType{ContentType: "application/json"}))
+				})
+			})
+
+			Context("when SkipContentTypeCheck is set", func() {
+				BeforeEach(func() {
+					options.SkipContentTypeCheck = true
+				})
+
+				It("downloads the content", func() {
+					appendErrorPageHandlers()
+
+					buffer := bytes.NewBuffer(nil)
+					err := client.ProductFiles.DownloadWithOptions(
+						productSlug,
+						releaseID,
+						productFileID,
+						options,
+						buffer,
+					)
+					Expect(err).NotTo(HaveOccurred())
+					Expect(buffer.Bytes()).To(Equal(fileContents))
+				})
+			})
+		})
+
+		Context("when MaxBytesPerSec is set", func() {
+			BeforeEach(func() {
+				options.MaxBytesPerSec = 60
+				fileContents = bytes.Repeat([]byte("a"), 30)
+
+				sha256Sum := sha256.Sum256(fileContents)
+				productFile.SHA256 = hex.EncodeToString(sha256Sum[:])
+			})
+
+			It("limits the download rate and still verifies the content", func() {
+				appendDownloadHandlers()
+
+				buffer := bytes.NewBuffer(nil)
+				start := time.Now()
+
+				err := client.ProductFiles.DownloadWithOptions(
+					productSlug,
+					releaseID,
+					productFileID,
+					options,
+					buffer,
+				)
+				Expect(err).NotTo(HaveOccurred())
+
+				Expect(time.Since(start)).To(BeNumerically(">=", 500*time.Millisecond))
+				Expect(buffer.Bytes()).To(Equal(fileContents))
+			})
+		})
+
+		Context("when MinThroughput is set", func() {
+			BeforeEach(func() {
+				options.MinThroughput = 1024
+				options.ThroughputWindow = 50 * time.Millisecond
+			})
+
+			It("downloads the file when throughput is sufficient", func() {
+				appendDownloadHandlers()
+
+				buffer := bytes.NewBuffer(nil)
+				err := client.ProductFiles.DownloadWithOptions(
+					productSlug,
+					releaseID,
+					productFileID,
+					options,
+					buffer,
+				)
+				Expect(err).NotTo(HaveOccurred())
+				Expect(buffer.Bytes()).To(Equal(fileContents))
+			})
+
+			Context("when the download stalls", func() {
+				It("aborts with ErrThroughputTooLow", func() {
+					server.AppendHandlers(
+						ghttp.RespondWithJSONEncoded(http.StatusOK, pivnet.ProductFileResponse{productFile}),
+						func(w http.ResponseWriter, r *http.Request) {
+							w.WriteHeader(http.StatusOK)
+							w.Write(fileContents[:4])
+							w.(http.Flusher).Flush()
+
+							time.Sleep(500 * time.Millisecond)
+						},
+					)
+
+					err := client.ProductFiles.DownloadWithOptions(
+						productSlug,
+						releaseID,
+						productFileID,
+						options,
+						ioutil.Discard,
+					)
+					Expect(err).To(BeAssignableToTypeOf(pivnet.ErrThroughputTooLow{}))
+					Expect(err.(pivnet.ErrThroughputTooLow).MinThroughput).To(Equal(int64(1024)))
+				})
+			})
+		})
+	})
+
+	Describe("download timeouts", func() {
+		var options pivnet.DownloadOptions
+
+		BeforeEach(func() {
+			options = pivnet.DownloadOptions{}
+		})
+
+		Context("when the storage host accepts connections but never responds", func() {
+			var listener net.Listener
+
+			BeforeEach(func() {
+				var err error
+				listener, err = net.Listen("tcp", "127.0.0.1:0")
+				Expect(err).NotTo(HaveOccurred())
+
+				go func() {
+					var conns []net.Conn
+					defer func() {
+						for _, conn := range conns {
+							conn.Close()
+						}
+					}()
+
+					for {
+						conn, err := listener.Accept()
+						if err != nil {
+							return
+						}
+						conns = append(conns, conn)
+					}
+				}()
+
+				options.ConnectTimeout = 100 * time.Millisecond
+			})
+
+			AfterEach(func() {
+				listener.Close()
+			})
+
+			It("fails within ConnectTimeout", func() {
+				server.AppendHandlers(
+					ghttp.RespondWithJSONEncoded(http.StatusOK, pivnet.ProductFileResponse{productFile}),
+					ghttp.RespondWith(http.StatusFound, nil, http.Header{
+						"Location": []string{"https://" + listener.Addr().String() + "/product-file"},
+					}),
+				)
+
+				start := time.Now()
+				err := client.ProductFiles.DownloadWithOptions(
+					productSlug,
+					releaseID,
+					productFileID,
+					options,
+					ioutil.Discard,
+				)
+				Expect(err).To(MatchError(ContainSubstring("TLS handshake timeout")))
+				Expect(time.Since(start)).To(BeNumerically("<", 2*time.Second))
+			})
+		})
+
+		Context("when the transfer takes longer than ClientConfig.RequestTimeout", func() {
+			BeforeEach(func() {
+				newClientConfig.RequestTimeout = 200 * time.Millisecond
+			})
+
+			appendSlowDownloadHandlers := func() {
+				server.AppendHandlers(
+					ghttp.RespondWithJSONEncoded(http.StatusOK, pivnet.ProductFileResponse{productFile}),
+					func(w http.ResponseWriter, r *http.Request) {
+						w.WriteHeader(http.StatusOK)
+						for _, b := range fileContents {
+							w.Write([]byte{b})
+							w.(http.Flusher).Flush()
+							time.Sleep(25 * time.Millisecond)
+						}
+					},
+				)
+			}
+
+			It("completes when TransferTimeout lifts the limit", func() {
+				appendSlowDownloadHandlers()
+				options.TransferTimeout = -1
+
+				buffer := bytes.NewBuffer(nil)
+				err := client.ProductFiles.DownloadWithOptions(
+					productSlug,
+					releaseID,
+					productFileID,
+					options,
+					buffer,
+				)
+				Expect(err).NotTo(HaveOccurred())
+				Expect(buffer.Bytes()).To(Equal(fileContents))
+			})
+
+			It("is aborted by the request timeout by default", func() {
+				appendSlowDownloadHandlers()
+
+				err := client.ProductFiles.DownloadWithOptions(
+					productSlug,
+					releaseID,
+					productFileID,
+					options,
+					ioutil.Discard,
+				)
+				Expect(err).To(HaveOccurred())
+			})
+		})
+	})
+
+	Describe("resuming a download", func() {
+		var (
+			options       pivnet.DownloadOptions
+			storagePath   string
+			resets        int
+			storageRanges []string
+		)
+
+		// resetAfter writes ten bytes of the file from first, declaring the
+		// length of the rest of the file, and then drops the connection.
+		resetAfter := func(w http.ResponseWriter, status int, first int, header http.Header) {
+			for key, values := range header {
+				w.Header()[key] = values
+			}
+			w.Header().Set("Content-Length", fmt.Sprintf("%d", len(fileContents)-first))
+			w.WriteHeader(status)
+			w.Write(fileContents[first : first+10])
+			w.(http.Flusher).Flush()
+
+			conn, _, err := w.(http.Hijacker).Hijack()
+			Expect(err).NotTo(HaveOccurred())
+			conn.Close()
+		}
+
+		BeforeEach(func() {
+			fileContents = bytes.Repeat([]byte("0123456789"), 10)
+			sha256Sum := sha256.Sum256(fileContents)
+			productFile.SHA256 = hex.EncodeToString(sha256Sum[:])
+
+			storagePath = "/storage/some-file.tgz"
+			resets = 0
+			storageRanges = nil
+
+			options = pivnet.DownloadOptions{
+				StreamRetry: pivnet.StreamRetryPolicy{Delay: time.Millisecond},
+			}
+		})
+
+		JustBeforeEach(func() {
+			server.RouteToHandler("GET", fmt.Sprintf(
+				"%s/products/%s/releases/%d/product_files/%d",
+				apiPrefix,
+				productSlug,
+				releaseID,
+				productFileID,
+			), ghttp.RespondWithJSONEncoded(http.StatusOK, pivnet.ProductFileResponse{productFile}))
+
+			var posts int
+			server.RouteToHandler("POST", apiPrefix+downloadLink, func(w http.ResponseWriter, r *http.Request) {
+				posts++
+				if posts == 1 {
+					resetAfter(w, http.StatusOK, 0, nil)
+					return
+				}
+
+				w.Header().Set("Location", server.URL()+storagePath)
+				w.WriteHeader(http.StatusFound)
+			})
+
+			server.RouteToHandler("GET", storagePath, func(w http.ResponseWriter, r *http.Request) {
+				storageRanges = append(storageRanges, r.Header.Get("Range"))
+
+				var first int
+				fmt.Sscanf(r.Header.Get("Range"), "bytes=%d-", &first)
+
+				if len(storageRanges) < resets {
+					resetAfter(w, http.StatusPartialContent, first, http.Header{
+						"Content-Range": []string{fmt.Sprintf("bytes %d-%d/%d", first, len(fileContents)-1, len(fileContents))},
+					})
+					return
+				}
+
+				http.ServeContent(w, r, "some-file.tgz", time.Time{}, bytes.NewReader(fileContents))
+			})
+		})
+
+		It("resumes from the last byte received", func() {
+			var result pivnet.DownloadResult
+			options.OnComplete = func(r pivnet.DownloadResult) {
+				result = r
+			}
+
+			buffer := bytes.NewBuffer(nil)
+			err := client.ProductFiles.DownloadWithOptions(productSlug, releaseID, productFileID, options, buffer)
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(buffer.Bytes()).To(Equal(fileContents))
+			Expect(storageRanges).To(Equal([]string{"bytes=10-"}))
+			Expect(result.Resumptions).To(Equal(1))
+		})
+
+		Context("when the stream is reset repeatedly", func() {
+			BeforeEach(func() {
+				resets = 3
+			})
+
+			It("resumes up to the stream retry limit", func() {
+				var result pivnet.DownloadResult
+				options.OnComplete = func(r pivnet.DownloadResult) {
+					result = r
+				}
+
+				buffer := bytes.NewBuffer(nil)
+				err := client.ProductFiles.DownloadWithOptions(productSlug, releaseID, productFileID, options, buffer)
+				Expect(err).NotTo(HaveOccurred())
+
+				Expect(buffer.Bytes()).To(Equal(fileContents))
+				Expect(storageRanges).To(Equal([]string{"bytes=10-", "bytes=20-", "bytes=30-"}))
+				Expect(result.Resumptions).To(Equal(3))
+			})
+
+			Context("when it is reset more often than MaxAttempts allows", func() {
+				BeforeEach(func() {
+					options.StreamRetry.MaxAttempts = 2
+				})
+
+				It("returns the read error", func() {
+					err := client.ProductFiles.DownloadWithOptions(productSlug, releaseID, productFileID, options, ioutil.Discard)
+					Expect(err).To(HaveOccurred())
+
+					Expect(storageRanges).To(HaveLen(2))
+				})
+			})
+		})
+
+		Context("when resuming is disabled", func() {
+			BeforeEach(func() {
+				options.StreamRetry.MaxAttempts = -1
+			})
+
+			It("returns the read error without resuming", func() {
+				err := client.ProductFiles.DownloadWithOptions(productSlug, releaseID, productFileID, options, ioutil.Discard)
+				Expect(err).To(HaveOccurred())
+
+				Expect(storageRanges).To(BeEmpty())
+			})
+		})
+
+		Context("when the storage provider does not support ranges", func() {
+			JustBeforeEach(func() {
+				server.RouteToHandler("GET", storagePath, func(w http.ResponseWriter, r *http.Request) {
+					storageRanges = append(storageRanges, r.Header.Get("Range"))
+					w.Write(fileContents)
+				})
+			})
+
+			It("returns the read error without trying again", func() {
+				err := client.ProductFiles.DownloadWithOptions(productSlug, releaseID, productFileID, options, ioutil.Discard)
+				Expect(err).To(HaveOccurred())
+
+				Expect(storageRanges).To(HaveLen(1))
+			})
+		})
+	})
+
+	Describe("DownloadMatching", func() {
+		var (
+			productFilesResponse string
+		)
+
+		BeforeEach(func() {
+			productFilesResponse = fmt.Sprintf(
+				`{"product_files":[{"id":%d,"aws_object_key":"product-files/some-product.pivotal","name":"Some Product"},{"id":1,"aws_object_key":"product-files/some-docs.pdf","name":"Docs"}]}`,
+				productFileID,
+			)
+		})
+
+		JustBeforeEach(func() {
+			server.AppendHandlers(
+				ghttp.CombineHandlers(
+					ghttp.VerifyRequest(
+						"GET",
+						fmt.Sprintf(
+							"%s/products/%s/releases/%d/product_files",
+							apiPrefix,
+							productSlug,
+							releaseID,
+						),
+					),
+					ghttp.RespondWith(http.StatusOK, productFilesResponse),
+				),
+			)
+		})
+
+		It("downloads the product file matching the pattern", func() {
+			appendDownloadHandlers()
+
+			writer := bytes.NewBuffer(nil)
+
+			err := client.ProductFiles.DownloadMatching(
+				productSlug,
+				releaseID,
+				"*.pivotal",
+				writer,
+			)
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(writer.Bytes()).To(Equal(fileContents))
+		})
+
+		Context("when no product file matches", func() {
+			It("returns an ErrNotFound", func() {
+				err := client.ProductFiles.DownloadMatching(
+					productSlug,
+					releaseID,
+					"*.tgz",
+					bytes.NewBuffer(nil),
+				)
+				Expect(err).To(BeAssignableToTypeOf(pivnet.ErrNotFound{}))
+			})
+		})
+
+		Context("when multiple product files match", func() {
+			It("returns an error listing the matches", func() {
+				err := client.ProductFiles.DownloadMatching(
+					productSlug,
+					releaseID,
+					"some-*",
+					bytes.NewBuffer(nil),
+				)
+				Expect(err).To(HaveOccurred())
+				Expect(err.Error()).To(ContainSubstring("some-product.pivotal, some-docs.pdf"))
+			})
+		})
+
+		Context("when the pattern is malformed", func() {
+			It("returns an error without making a request", func() {
+				err := client.ProductFiles.DownloadMatching(
+					productSlug,
+					releaseID,
+					"[",
+					bytes.NewBuffer(nil),
+				)
+				Expect(err).To(HaveOccurred())
+				Expect(server.ReceivedRequests()).To(BeEmpty())
+			})
+		})
+	})
+
+	Describe("SignedDownloadURL", func() {
+		var (
+			signedURL string
+		)
+
+		BeforeEach(func() {
+			signedURL = "https://s3.example.com/some-file?signature=abc"
+		})
+
+		JustBeforeEach(func() {
+			server.AppendHandlers(
+				ghttp.CombineHandlers(
+					ghttp.VerifyRequest(
+						"GET",
+						fmt.Sprintf(
+							"%s/products/%s/releases/%d/product_files/%d",
+							apiPrefix,
+							productSlug,
+							releaseID,
+							productFileID,
+						),
+					),
+					ghttp.RespondWithJSONEncoded(http.StatusOK, pivnet.ProductFileResponse{productFile}),
+				),
+			)
+		})
+
+		It("returns the redirect location without following it", func() {
+			server.AppendHandlers(
+				ghttp.CombineHandlers(
+					ghttp.VerifyRequest("POST", apiPrefix+downloadLink),
+					ghttp.RespondWith(http.StatusFound, nil, http.Header{"Location": []string{signedURL}}),
+				),
+			)
+
+			url, err := client.ProductFiles.SignedDownloadURL(productSlug, releaseID, productFileID)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(url).To(Equal(signedURL))
+		})
+
+		Context("when the server does not redirect", func() {
+			It("returns an error", func() {
+				server.AppendHandlers(
+					ghttp.CombineHandlers(
+						ghttp.VerifyRequest("POST", apiPrefix+downloadLink),
+						ghttp.RespondWith(http.StatusTeapot, `{"message":"foo message"}`),
+					),
+				)
+
+				_, err := client.ProductFiles.SignedDownloadURL(productSlug, releaseID, productFileID)
+				Expect(err).To(MatchError(ContainSubstring("foo message")))
+			})
+		})
+	})
+
+	Describe("SignedDownloadURLs", func() {
+		It("returns the signed URL and its expiry for every file of the release", func() {
+			productFile.AWSObjectKey = "product/some-file.tgz"
+
+			otherFile := pivnet.ProductFile{
+				ID:   3456,
+				Name: "other file",
+				Links: &pivnet.Links{
+					Download: map[string]string{"href": "/other/download/link"},
+				},
+			}
+
+			v4URL := "https://s3.example.com/some-file.tgz?X-Amz-Date=20160102T030405Z&X-Amz-Expires=300&X-Amz-Signature=abc"
+			v2URL := "https://s3.example.com/other?Expires=1451703845&Signature=abc"
+
+			server.AppendHandlers(
+				ghttp.CombineHandlers(
+					ghttp.VerifyRequest("GET", fmt.Sprintf("%s/products/%s/releases/%d/product_files", apiPrefix, productSlug, releaseID)),
+					ghttp.RespondWithJSONEncoded(http.StatusOK, pivnet.ProductFilesResponse{
+						ProductFiles: []pivnet.ProductFile{productFile, otherFile},
+					}),
+				),
+				ghttp.CombineHandlers(
+					ghttp.VerifyRequest("POST", apiPrefix+downloadLink),
+					ghttp.RespondWith(http.StatusFound, nil, http.Header{"Location": []string{v4URL}}),
+				),
+				ghttp.CombineHandlers(
+					ghttp.VerifyRequest("POST", apiPrefix+"/other/download/link"),
+					ghttp.RespondWith(http.StatusFound, nil, http.Header{"Location": []string{v2URL}}),
+				),
+			)
+
+			signedDownloads, err := client.ProductFiles.SignedDownloadURLs(productSlug, releaseID)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(signedDownloads).To(HaveLen(2))
+
+			Expect(signedDownloads[0].ProductFileID).To(Equal(productFileID))
+			Expect(signedDownloads[0].FileName).To(Equal("some-file.tgz"))
+			Expect(signedDownloads[0].SHA256).To(Equal(productFile.SHA256))
+			Expect(signedDownloads[0].URL).To(Equal(v4URL))
+			Expect(signedDownloads[0].ExpiresAt).To(BeTemporally("==", time.Date(2016, time.January, 2, 3, 9, 5, 0, time.UTC)))
+
+			Expect(signedDownloads[1].ProductFileID).To(Equal(3456))
+			Expect(signedDownloads[1].FileName).To(Equal("other file"))
+			Expect(signedDownloads[1].URL).To(Equal(v2URL))
+			Expect(signedDownloads[1].ExpiresAt).To(BeTemporally("==", time.Date(2016, time.January, 2, 3, 4, 5, 0, time.UTC)))
+		})
+	})
+
+	Describe("SignedDownloadURLsByFileType", func() {
+		var licenseFile pivnet.ProductFile
+
+		BeforeEach(func() {
+			productFile.FileType = "Software"
+			licenseFile = pivnet.ProductFile{
+				ID:       3456,
+				FileType: pivnet.FileTypeOpenSourceLicense,
+				Links: &pivnet.Links{
+					Download: map[string]string{"href": "/license/download/link"},
+				},
+			}
+
+			server.AppendHandlers(
+				ghttp.CombineHandlers(
+					ghttp.VerifyRequest("GET", fmt.Sprintf("%s/products/%s/releases/%d/product_files", apiPrefix, productSlug, releaseID)),
+					ghttp.RespondWithJSONEncoded(http.StatusOK, pivnet.ProductFilesResponse{
+						ProductFiles: []pivnet.ProductFile{productFile, licenseFile},
+					}),
+				),
+			)
+		})
+
+		It("returns the signed URLs of the files of the type, ignoring case", func() {
+			signedURL := "https://s3.example.com/some-file.tgz"
+			server.AppendHandlers(
+				ghttp.CombineHandlers(
+					ghttp.VerifyRequest("POST", apiPrefix+downloadLink),
+					ghttp.RespondWith(http.StatusFound, nil, http.Header{"Location": []string{signedURL}}),
+				),
+			)
+
+			signedDownloads, err := client.ProductFiles.SignedDownloadURLsByFileType(productSlug, releaseID, "software")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(signedDownloads).To(HaveLen(1))
+			Expect(signedDownloads[0].ProductFileID).To(Equal(productFileID))
+			Expect(signedDownloads[0].URL).To(Equal(signedURL))
+
+			Expect(server.ReceivedRequests()).To(HaveLen(2))
+		})
+
+		Context("when no file has the type", func() {
+			It("returns an empty slice", func() {
+				signedDownloads, err := client.ProductFiles.SignedDownloadURLsByFileType(productSlug, releaseID, "Documentation")
+				Expect(err).NotTo(HaveOccurred())
+				Expect(signedDownloads).NotTo(BeNil())
+				Expect(signedDownloads).To(BeEmpty())
+			})
+		})
+	})
+
+	Describe("VerifyDownload", func() {
+		It("downloads and verifies the file, returning its size and checksum", func() {
+			appendDownloadHandlers()
+
+			verified, err := client.ProductFiles.VerifyDownload(productSlug, releaseID, productFileID)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(verified).To(Equal(pivnet.VerifiedDownload{
+				Size:      int64(len(fileContents)),
+				Algorithm: "sha256",
+				Checksum:  productFile.SHA256,
+			}))
+		})
+
+		Context("when the content does not match the checksum", func() {
+			BeforeEach(func() {
+				productFile.SHA256 = "not-the-checksum"
+			})
+
+			It("returns an ErrChecksumMismatch", func() {
+				appendDownloadHandlers()
+
+				_, err := client.ProductFiles.VerifyDownload(productSlug, releaseID, productFileID)
+				Expect(err).To(BeAssignableToTypeOf(pivnet.ErrChecksumMismatch{}))
+			})
+		})
+
+		Context("when the size does not match", func() {
+			BeforeEach(func() {
+				productFile.Size = len(fileContents) + 1
+			})
+
+			It("returns an ErrSizeMismatch", func() {
+				appendDownloadHandlers()
+
+				_, err := client.ProductFiles.VerifyDownload(productSlug, releaseID, productFileID)
+				Expect(err).To(Equal(pivnet.ErrSizeMismatch{
+					Expected: int64(len(fileContents) + 1),
+					Actual:   int64(len(fileContents)),
+				}))
+			})
+		})
+
+		Context("when the product file has no checksum", func() {
+			BeforeEach(func() {
+				productFile.SHA256 = ""
+			})
+
+			It("returns an error without downloading", func() {
+				server.AppendHandlers(
+					ghttp.RespondWithJSONEncoded(http.StatusOK, pivnet.ProductFileResponse{productFile}),
+				)
+
+				_, err := client.ProductFiles.VerifyDownload(productSlug, releaseID, productFileID)
+				Expect(err).To(MatchError(ContainSubstring("no checksum")))
+				Expect(server.ReceivedRequests()).To(HaveLen(1))
+			})
+		})
+	})
+
+	Describe("DownloadToFile", func() {
+		var (
+			dir         string
+			destination string
+			options     pivnet.DownloadOptions
+		)
+
+		BeforeEach(func() {
+			var err error
+			dir, err = ioutil.TempDir("", "go-pivnet-download")
+			Expect(err).NotTo(HaveOccurred())
+
+			destination = filepath.Join(dir, "some-file.tgz")
+			options = pivnet.DownloadOptions{}
+		})
+
+		AfterEach(func() {
+			os.RemoveAll(dir)
+		})
+
+		It("writes the file to the destination", func() {
+			appendDownloadHandlers()
+
+			err := client.ProductFiles.DownloadToFile(productSlug, releaseID, productFileID, destination, options)
+			Expect(err).NotTo(HaveOccurred())
+
+			contents, err := ioutil.ReadFile(destination)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(contents).To(Equal(fileContents))
+
+			_, err = os.Stat(destination + ".partial")
+			Expect(os.IsNotExist(err)).To(BeTrue())
+		})
+
+		It("creates the file with the default mode", func() {
+			appendDownloadHandlers()
+
+			err := client.ProductFiles.DownloadToFile(productSlug, releaseID, productFileID, destination, options)
+			Expect(err).NotTo(HaveOccurred())
+
+			info, err := os.Stat(destination)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(info.Mode().Perm()).To(Equal(pivnet.DefaultFileMode))
+		})
+
+		Context("when FileMode is set", func() {
+			BeforeEach(func() {
+				options.FileMode = 0775
+			})
+
+			It("creates the file with that mode", func() {
+				appendDownloadHandlers()
+
+				err := client.ProductFiles.DownloadToFile(productSlug, releaseID, productFileID, destination, options)
+				Expect(err).NotTo(HaveOccurred())
+
+				info, err := os.Stat(destination)
+				Expect(err).NotTo(HaveOccurred())
+				Expect(info.Mode().Perm()).To(Equal(os.FileMode(0775)))
+			})
+		})
+
+		Context("when the download fails verification", func() {
+			BeforeEach(func() {
+				productFile.SHA256 = "not-the-checksum"
+			})
+
+			It("leaves neither the file nor the partial file behind", func() {
+				appendDownloadHandlers()
+
+				err := client.ProductFiles.DownloadToFile(productSlug, releaseID, productFileID, destination, options)
+				Expect(err).To(BeAssignableToTypeOf(pivnet.ErrChecksumMismatch{}))
+
+				entries, err := ioutil.ReadDir(dir)
+				Expect(err).NotTo(HaveOccurred())
+				Expect(entries).To(BeEmpty())
+			})
+		})
+
+		Context("when Connections is greater than one", func() {
+			var (
+				storagePath     string
+				supportsRanges  bool
+				storageRequests []*http.Request
+			)
+
+			BeforeEach(func() {
+				fileContents = bytes.Repeat([]byte("0123456789"), 100)
+				sha256Sum := sha256.Sum256(fileContents)
+				productFile.SHA256 = hex.EncodeToString(sha256Sum[:])
+
+				storagePath = "/storage/some-file.tgz"
+				supportsRanges = true
+				storageRequests = nil
+
+				options.Connections = 4
+			})
+
+			JustBeforeEach(func() {
+				server.RouteToHandler("GET", fmt.Sprintf(
+					"%s/products/%s/releases/%d/product_files/%d",
+					apiPrefix,
+					productSlug,
+					releaseID,
+					productFileID,
+				), ghttp.RespondWithJSONEncoded(http.StatusOK, pivnet.ProductFileResponse{productFile}))
+
+				server.RouteToHandler("POST", apiPrefix+downloadLink, ghttp.RespondWith(
+					http.StatusFound,
+					nil,
+					http.Header{"Location": []string{server.URL() + storagePath}},
+				))
+
+				var mutex sync.Mutex
+				server.RouteToHandler("GET", storagePath, func(w http.ResponseWriter, r *http.Request) {
+					mutex.Lock()
+					storageRequests = append(storageRequests, r)
+					mutex.Unlock()
+
+					if !supportsRanges {
+						r.Header.Del("Range")
+					}
+					http.ServeContent(w, r, "some-file.tgz", time.Time{}, bytes.NewReader(fileContents))
+				})
+			})
+
+			It("downloads the file in ranges", func() {
+				err := client.ProductFiles.DownloadToFile(productSlug, releaseID, productFileID, destination, options)
+				Expect(err).NotTo(HaveOccurred())
+
+				contents, err := ioutil.ReadFile(destination)
+				Expect(err).NotTo(HaveOccurred())
+				Expect(contents).To(Equal(fileContents))
+
+				Expect(storageRequests).To(HaveLen(5))
+				for _, r := range storageRequests {
+					Expect(r.Header.Get("Range")).NotTo(BeEmpty())
+					Expect(r.Header.Get("Authorization")).To(BeEmpty())
+				}
+			})
+
+			Context("when the assembled file does not match the checksum", func() {
+				BeforeEach(func() {
+					productFile.SHA256 = "not-the-checksum"
+				})
+
+				It("returns an ErrChecksumMismatch and leaves no file behind", func() {
+					err := client.ProductFiles.DownloadToFile(productSlug, releaseID, productFileID, destination, options)
+					Expect(err).To(BeAssignableToTypeOf(pivnet.ErrChecksumMismatch{}))
+
+					entries, err := ioutil.ReadDir(dir)
+					Expect(err).NotTo(HaveOccurred())
+					Expect(entries).To(BeEmpty())
+				})
+			})
+
+			Context("when the server does not support ranges", func() {
+				BeforeEach(func() {
+					supportsRanges = false
+				})
+
+				It("downloads the file in a single stream", func() {
+					err := client.ProductFiles.DownloadToFile(productSlug, releaseID, productFileID, destination, options)
+					Expect(err).NotTo(HaveOccurred())
+
+					contents, err := ioutil.ReadFile(destination)
+					Expect(err).NotTo(HaveOccurred())
+					Expect(contents).To(Equal(fileContents))
+
+					Expect(storageRequests).To(HaveLen(2))
+				})
+			})
+		})
+
+		Context("when VerifyAfterRename is set", func() {
+			BeforeEach(func() {
+				options.VerifyAfterRename = true
+			})
+
+			It("verifies the file on disk", func() {
+				appendDownloadHandlers()
+
+				err := client.ProductFiles.DownloadToFile(productSlug, releaseID, productFileID, destination, options)
+				Expect(err).NotTo(HaveOccurred())
+			})
+
+			Context("when the file on disk differs from the downloaded content", func() {
+				BeforeEach(func() {
+					productFile.Size = len(fileContents)
+
+					options.Progress = func(p pivnet.DownloadProgress) {
+						if p.BytesWritten < p.TotalBytes {
+							return
+						}
+
+						f, err := os.OpenFile(destination+".partial", os.O_WRONLY, 0)
+						Expect(err).NotTo(HaveOccurred())
+						defer f.Close()
+
+						_, err = f.WriteAt([]byte("X"), 0)
+						Expect(err).NotTo(HaveOccurred())
+					}
+				})
+
+				It("returns an ErrOnDiskChecksumMismatch", func() {
+					appendDownloadHandlers()
+
+					err := client.ProductFiles.DownloadToFile(productSlug, releaseID, productFileID, destination, options)
+					Expect(err).To(BeAssignableToTypeOf(pivnet.ErrOnDiskChecksumMismatch{}))
+					Expect(err.(pivnet.ErrOnDiskChecksumMismatch).Path).To(Equal(destination))
+				})
+			})
+		})
+	})
+
+	Context("when MaxResponseBytes is smaller than the file", func() {
+		BeforeEach(func() {
+			fileContents = bytes.Repeat([]byte("a"), 4096)
+			sha256Sum := sha256.Sum256(fileContents)
+			productFile.SHA256 = hex.EncodeToString(sha256Sum[:])
+
+			newClientConfig.MaxResponseBytes = 1024
+		})
+
+		It("does not limit the download", func() {
+			appendDownloadHandlers()
+
+			var buffer bytes.Buffer
+			err := client.ProductFiles.DownloadTo(productSlug, releaseID, productFileID, &buffer)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(buffer.Bytes()).To(Equal(fileContents))
+		})
+	})
+
+	Describe("download cache", func() {
+		var (
+			cacheDir  string
+			cachePath string
+		)
+
+		BeforeEach(func() {
+			var err error
+			cacheDir, err = ioutil.TempDir("", "go-pivnet-cache")
+			Expect(err).NotTo(HaveOccurred())
+
+			newClientConfig.DownloadCacheDir = cacheDir
+			cachePath = filepath.Join(cacheDir, productFile.SHA256)
+		})
+
+		AfterEach(func() {
+			os.RemoveAll(cacheDir)
+		})
+
+		It("adds the downloaded file to the cache", func() {
+			appendDownloadHandlers()
+
+			var buffer bytes.Buffer
+			err := client.ProductFiles.DownloadTo(productSlug, releaseID, productFileID, &buffer)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(buffer.Bytes()).To(Equal(fileContents))
+
+			cached, err := ioutil.ReadFile(cachePath)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(cached).To(Equal(fileContents))
+
+			entries, err := ioutil.ReadDir(cacheDir)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(entries).To(HaveLen(1))
+		})
+
+		Context("when the file is in the cache", func() {
+			BeforeEach(func() {
+				err := ioutil.WriteFile(cachePath, fileContents, 0644)
+				Expect(err).NotTo(HaveOccurred())
+			})
+
+			It("copies the file from the cache without downloading it", func() {
+				server.AppendHandlers(
+					ghttp.RespondWithJSONEncoded(http.StatusOK, pivnet.ProductFileResponse{productFile}),
+				)
+
+				var buffer bytes.Buffer
+				err := client.ProductFiles.DownloadTo(productSlug, releaseID, productFileID, &buffer)
+				Expect(err).NotTo(HaveOccurred())
+				Expect(buffer.Bytes()).To(Equal(fileContents))
+
+				Expect(server.ReceivedRequests()).To(HaveLen(1))
+			})
+		})
+
+		Context("when the cached file is corrupt", func() {
+			BeforeEach(func() {
+				err := ioutil.WriteFile(cachePath, []byte("corrupt contents"), 0644)
+				Expect(err).NotTo(HaveOccurred())
+			})
+
+			It("downloads the file again and replaces the cached copy", func() {
+				appendDownloadHandlers()
+
+				var buffer bytes.Buffer
+				err := client.ProductFiles.DownloadTo(productSlug, releaseID, productFileID, &buffer)
+				Expect(err).NotTo(HaveOccurred())
+				Expect(buffer.Bytes()).To(Equal(fileContents))
+
+				cached, err := ioutil.ReadFile(cachePath)
+				Expect(err).NotTo(HaveOccurred())
+				Expect(cached).To(Equal(fileContents))
+			})
+		})
+
+		Context("when the download fails verification", func() {
+			BeforeEach(func() {
+				otherSum := sha256.Sum256([]byte("other contents"))
+				productFile.SHA256 = hex.EncodeToString(otherSum[:])
+			})
+
+			It("does not add the file to the cache", func() {
+				appendDownloadHandlers()
+
+				err := client.ProductFiles.DownloadTo(productSlug, releaseID, productFileID, ioutil.Discard)
+				Expect(err).To(BeAssignableToTypeOf(pivnet.ErrChecksumMismatch{}))
+
+				entries, err := ioutil.ReadDir(cacheDir)
+				Expect(err).NotTo(HaveOccurred())
+				Expect(entries).To(BeEmpty())
+			})
+		})
+
+		Context("when the product file has no SHA256", func() {
+			var (
+				lastModified     string
+				lastModifiedPath string
+			)
+
+			BeforeEach(func() {
+				md5Sum := md5.Sum(fileContents)
+				productFile.SHA256 = ""
+				productFile.MD5 = hex.EncodeToString(md5Sum[:])
+
+				lastModified = "Wed, 21 Oct 2015 07:28:00 GMT"
+				cachePath = filepath.Join(cacheDir, fmt.Sprintf("product-file-%d", productFileID))
+				lastModifiedPath = cachePath + ".last-modified"
+			})
+
+			appendConditionalDownloadHandlers := func(download http.HandlerFunc) {
+				server.AppendHandlers(
+					ghttp.RespondWithJSONEncoded(http.StatusOK, pivnet.ProductFileResponse{productFile}),
+					download,
+				)
+			}
+
+			It("caches the file with its Last-Modified", func() {
+				appendConditionalDownloadHandlers(ghttp.RespondWith(
+					http.StatusOK,
+					fileContents,
+					http.Header{"Last-Modified": []string{lastModified}},
+				))
+
+				var buffer bytes.Buffer
+				err := client.ProductFiles.DownloadTo(productSlug, releaseID, productFileID, &buffer)
+				Expect(err).NotTo(HaveOccurred())
+				Expect(buffer.Bytes()).To(Equal(fileContents))
+
+				cached, err := ioutil.ReadFile(cachePath)
+				Expect(err).NotTo(HaveOccurred())
+				Expect(cached).To(Equal(fileContents))
+
+				stored, err := ioutil.ReadFile(lastModifiedPath)
+				Expect(err).NotTo(HaveOccurred())
+				Expect(string(stored)).To(Equal(lastModified))
+			})
+
+			Context("when the response has no Last-Modified", func() {
+				It("does not cache the file", func() {
+					appendConditionalDownloadHandlers(ghttp.RespondWith(http.StatusOK, fileContents))
+
+					err := client.ProductFiles.DownloadTo(productSlug, releaseID, productFileID, ioutil.Discard)
+					Expect(err).NotTo(HaveOccurred())
+
+					entries, err := ioutil.ReadDir(cacheDir)
+					Expect(err).NotTo(HaveOccurred())
+					Expect(entries).To(BeEmpty())
+				})
+			})
+
+			Context("when the file is in the cache", func() {
+				var cachedContents []byte
+
+				BeforeEach(func() {
+					cachedContents = fileContents
+				})
+
+				JustBeforeEach(func() {
+					err := ioutil.WriteFile(cachePath, cachedContents, 0644)
+					Expect(err).NotTo(HaveOccurred())
+
+					err = ioutil.WriteFile(lastModifiedPath, []byte(lastModified), 0644)
+					Expect(err).NotTo(HaveOccurred())
+				})
+
+				It("copies the file from the cache when Pivnet responds 304", func() {
+					appendConditionalDownloadHandlers(ghttp.CombineHandlers(
+						ghttp.VerifyHeaderKV("If-Modified-Since", lastModified),
+						ghttp.RespondWith(http.StatusNotModified, nil),
+					))
+
+					var buffer bytes.Buffer
+					err := client.ProductFiles.DownloadTo(productSlug, releaseID, productFileID, &buffer)
+					Expect(err).NotTo(HaveOccurred())
+					Expect(buffer.Bytes()).To(Equal(fileContents))
+
+					Expect(server.ReceivedRequests()).To(HaveLen(2))
+				})
+
+				It("replaces the cached copy when the file has changed", func() {
+					newContents := []byte("new file contents")
+					md5Sum := md5.Sum(newContents)
+					productFile.MD5 = hex.EncodeToString(md5Sum[:])
+
+					newLastModified := "Thu, 22 Oct 2015 07:28:00 GMT"
+					appendConditionalDownloadHandlers(ghttp.RespondWith(
+						http.StatusOK,
+						newContents,
+						http.Header{"Last-Modified": []string{newLastModified}},
+					))
+
+					var buffer bytes.Buffer
+					err := client.ProductFiles.DownloadTo(productSlug, releaseID, productFileID, &buffer)
+					Expect(err).NotTo(HaveOccurred())
+					Expect(buffer.Bytes()).To(Equal(newContents))
+
+					cached, err := ioutil.ReadFile(cachePath)
+					Expect(err).NotTo(HaveOccurred())
+					Expect(cached).To(Equal(newContents))
+
+					stored, err := ioutil.ReadFile(lastModifiedPath)
+					Expect(err).NotTo(HaveOccurred())
+					Expect(string(stored)).To(Equal(newLastModified))
+				})
+
+				Context("when the cached copy does not match the checksum", func() {
+					BeforeEach(func() {
+						cachedContents = []byte("corrupt contents")
+					})
+
+					It("downloads the file again without revalidating", func() {
+						appendConditionalDownloadHandlers(ghttp.RespondWith(http.StatusNotModified, nil))
+						server.AppendHandlers(
+							ghttp.CombineHandlers(
+								func(w http.ResponseWriter, req *http.Request) {
+									Expect(req.Header.Get("If-Modified-Since")).To(BeEmpty())
+								},
+								ghttp.RespondWith(http.StatusOK, fileContents),
+							),
+						)
+
+						var buffer bytes.Buffer
+						err := client.ProductFiles.DownloadTo(productSlug, releaseID, productFileID, &buffer)
+						Expect(err).NotTo(HaveOccurred())
+						Expect(buffer.Bytes()).To(Equal(fileContents))
+
+						Expect(cachePath).NotTo(BeAnExistingFile())
+						Expect(lastModifiedPath).NotTo(BeAnExistingFile())
+					})
+				})
+			})
+		})
+
+		Context("when verifying a download", func() {
+			BeforeEach(func() {
+				err := ioutil.WriteFile(cachePath, fileContents, 0644)
+				Expect(err).NotTo(HaveOccurred())
+			})
+
+			It("ignores the cache", func() {
+				appendDownloadHandlers()
+
+				_, err := client.ProductFiles.VerifyDownload(productSlug, releaseID, productFileID)
+				Expect(err).NotTo(HaveOccurred())
+
+				Expect(server.ReceivedRequests()).To(HaveLen(2))
+			})
+		})
+	})
+
+	Describe("DownloadAll", func() {
+		var (
+			dir          string
+			options      pivnet.DownloadAllOptions
+			files        map[string][]byte
+			productFiles []pivnet.ProductFile
+			failingID    int
+			stuckID      int
+		)
+
+		BeforeEach(func() {
+			var err error
+			dir, err = ioutil.TempDir("", "go-pivnet-download-all")
+			Expect(err).NotTo(HaveOccurred())
+
+			options = pivnet.DownloadAllOptions{Concurrency: 2}
+			failingID = 0
+			stuckID = 0
+
+			files = map[string][]byte{
+				"product.pivotal": []byte("pivotal contents"),
+				"config.yml":      []byte("yml contents"),
+				"docs.pdf":        []byte("pdf contents"),
+			}
+
+			productFiles = nil
+			for i, name := range []string{"product.pivotal", "config.yml", "docs.pdf"} {
+				sum := sha256.Sum256(files[name])
+				productFiles = append(productFiles, pivnet.ProductFile{
+					ID:           i + 1,
+					AWSObjectKey: "product-files/" + name,
+					SHA256:       hex.EncodeToString(sum[:]),
+					Links: &pivnet.Links{Download: map[string]string{
+						"href": fmt.Sprintf("/download/%d", i+1),
+					}},
+				})
+			}
+		})
+
+		JustBeforeEach(func() {
+			releaseURL := fmt.Sprintf("%s/products/%s/releases/%d", apiPrefix, productSlug, releaseID)
+
+			server.RouteToHandler("GET", releaseURL+"/product_files",
+				ghttp.RespondWithJSONEncoded(http.StatusOK, pivnet.ProductFilesResponse{ProductFiles: productFiles}),
+			)
+
+			for _, pf := range productFiles {
+				server.RouteToHandler("GET", fmt.Sprintf("%s/product_files/%d", releaseURL, pf.ID),
+					ghttp.RespondWithJSONEncoded(http.StatusOK, pivnet.ProductFileResponse{pf}),
+				)
+
+				status := http.StatusOK
+				if pf.ID == failingID {
+					status = http.StatusTeapot
+				}
+
+				handler := ghttp.RespondWith(status, files[filepath.Base(pf.AWSObjectKey)])
+				if pf.ID == stuckID {
+					handler = func(w http.ResponseWriter, req *http.Request) {
+						w.WriteHeader(http.StatusOK)
+						w.Write([]byte("partial"))
+						w.(http.Flusher).Flush()
+						<-req.Context().Done()
+					}
+				}
+
+				server.RouteToHandler("POST", fmt.Sprintf("%s/download/%d", apiPrefix, pf.ID), handler)
+			}
+		})
+
+		AfterEach(func() {
+			os.RemoveAll(dir)
+		})
+
+		downloadedNames := func() []string {
+			entries, err := ioutil.ReadDir(dir)
+			Expect(err).NotTo(HaveOccurred())
+
+			var names []string
+			for _, entry := range entries {
+				names = append(names, entry.Name())
+
+				contents, err := ioutil.ReadFile(filepath.Join(dir, entry.Name()))
+				Expect(err).NotTo(HaveOccurred())
+				Expect(contents).To(Equal(files[entry.Name()]))
+			}
+			return names
+		}
+
+		It("downloads every product file of the release", func() {
+			downloaded, err := client.ProductFiles.DownloadAll(productSlug, releaseID, dir, options)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(downloaded).To(HaveLen(3))
+
+			Expect(downloadedNames()).To(ConsistOf("product.pivotal", "config.yml", "docs.pdf"))
+		})
+
+		Context("when include and exclude patterns are provided", func() {
+			BeforeEach(func() {
+				options.Include = []string{"*.pivotal", "*.yml"}
+				options.Exclude = []string{"config.*"}
+			})
+
+			It("downloads the files matching an include and no exclude", func() {
+				downloaded, err := client.ProductFiles.DownloadAll(productSlug, releaseID, dir, options)
+				Expect(err).NotTo(HaveOccurred())
+				Expect(downloaded).To(HaveLen(1))
+				Expect(downloaded[0].ID).To(Equal(1))
+
+				Expect(downloadedNames()).To(ConsistOf("product.pivotal"))
+			})
+		})
+
+		Context("when a pattern is invalid", func() {
+			BeforeEach(func() {
+				options.Exclude = []string{"["}
+			})
+
+			It("returns an error without making requests", func() {
+				_, err := client.ProductFiles.DownloadAll(productSlug, releaseID, dir, options)
+				Expect(err).To(HaveOccurred())
+
+				Expect(server.ReceivedRequests()).To(BeEmpty())
+			})
+		})
+
+		Context("when a download fails", func() {
+			BeforeEach(func() {
+				failingID = 2
+			})
+
+			It("downloads the other files and returns a MultiError", func() {
+				downloaded, err := client.ProductFiles.DownloadAll(productSlug, releaseID, dir, options)
+				Expect(downloaded).To(HaveLen(2))
+
+				var multiErr pivnet.MultiError
+				Expect(errors.As(err, &multiErr)).To(BeTrue())
+				Expect(multiErr.Errors).To(HaveLen(1))
+				Expect(multiErr.Errors[0].ID).To(Equal(2))
+
+				Expect(downloadedNames()).To(ConsistOf("product.pivotal", "docs.pdf"))
+			})
+		})
+
+		Context("when a download takes longer than the per-file timeout", func() {
+			BeforeEach(func() {
+				stuckID = 3
+				options.TimeoutPerFile = 200 * time.Millisecond
+			})
+
+			It("abandons the file and reports it as timed out", func() {
+				downloaded, err := client.ProductFiles.DownloadAll(productSlug, releaseID, dir, options)
+				Expect(downloaded).To(HaveLen(2))
+
+				var multiErr pivnet.MultiError
+				Expect(errors.As(err, &multiErr)).To(BeTrue())
+				Expect(multiErr.Errors).To(HaveLen(1))
+				Expect(multiErr.Errors[0].ID).To(Equal(3))
+				Expect(multiErr.Errors[0].Err).To(Equal(pivnet.ErrDownloadTimedOut{
+					ProductFileID: 3,
+					Timeout:       200 * time.Millisecond,
+				}))
+
+				Expect(downloadedNames()).To(ConsistOf("product.pivotal", "config.yml"))
+			})
+		})
+
+		Context("when selected files have the same file name", func() {
+			BeforeEach(func() {
+				productFiles[2].AWSObjectKey = "other-product-files/config.yml"
+			})
+
+			It("downloads neither and reports both", func() {
+				downloaded, err := client.ProductFiles.DownloadAll(productSlug, releaseID, dir, options)
+				Expect(downloaded).To(HaveLen(1))
+				Expect(downloaded[0].ID).To(Equal(1))
+
+				var multiErr pivnet.MultiError
+				Expect(errors.As(err, &multiErr)).To(BeTrue())
+				Expect(multiErr.Errors).To(HaveLen(2))
+				for _, idErr := range multiErr.Errors {
+					Expect(idErr.Err).To(Equal(pivnet.ErrDuplicateFileName{
+						ProductFileIDs: []int{2, 3},
+						Path:           filepath.Join(dir, "config.yml"),
+					}))
+				}
+
+				Expect(downloadedNames()).To(ConsistOf("product.pivotal"))
+				for _, req := range server.ReceivedRequests() {
+					Expect(req.URL.Path).NotTo(HaveSuffix("/download/2"))
+					Expect(req.URL.Path).NotTo(HaveSuffix("/download/3"))
+				}
+			})
+		})
+	})
+
+	Describe("VerifyFile", func() {
+		var (
+			localFilePath string
+		)
+
+		BeforeEach(func() {
+			productFile.Size = len(fileContents)
+
+			f, err := ioutil.TempFile("", "go-pivnet-verify")
+			Expect(err).NotTo(HaveOccurred())
+			defer f.Close()
+
+			_, err = f.Write(fileContents)
+			Expect(err).NotTo(HaveOccurred())
+
+			localFilePath = f.Name()
+		})
+
+		JustBeforeEach(func() {
+			server.AppendHandlers(
+				ghttp.CombineHandlers(
+					ghttp.VerifyRequest(
+						"GET",
+						fmt.Sprintf(
+							"%s/products/%s/releases/%d/product_files/%d",
+							apiPrefix,
+							productSlug,
+							releaseID,
+							productFileID,
+						),
+					),
+					ghttp.RespondWithJSONEncoded(http.StatusOK, pivnet.ProductFileResponse{productFile}),
+				),
+			)
+		})
+
+		AfterEach(func() {
+			os.Remove(localFilePath)
+		})
+
+		It("verifies the local file without downloading it", func() {
+			err := client.ProductFiles.VerifyFile(productSlug, releaseID, productFileID, localFilePath)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(server.ReceivedRequests()).To(HaveLen(1))
+		})
+
+		Context("when the size does not match", func() {
+			BeforeEach(func() {
+				productFile.Size = 1
+			})
+
+			It("returns an ErrSizeMismatch", func() {
+				err := client.ProductFiles.VerifyFile(productSlug, releaseID, productFileID, localFilePath)
+				Expect(err).To(Equal(pivnet.ErrSizeMismatch{
+					Expected: 1,
+					Actual:   int64(len(fileContents)),
+				}))
+			})
+		})
+
+		Context("when the checksum does not match", func() {
+			BeforeEach(func() {
+				productFile.SHA256 = "abcdef"
+			})
+
+			It("returns an ErrChecksumMismatch", func() {
+				err := client.ProductFiles.VerifyFile(productSlug, releaseID, productFileID, localFilePath)
+				Expect(err).To(BeAssignableToTypeOf(pivnet.ErrChecksumMismatch{}))
+			})
+		})
+
+		Context("when the product file has no checksum", func() {
+			BeforeEach(func() {
+				productFile.SHA256 = ""
+			})
+
+			It("returns an error", func() {
+				err := client.ProductFiles.VerifyFile(productSlug, releaseID, productFileID, localFilePath)
+				Expect(err).To(MatchError(ContainSubstring("no checksum")))
+			})
+		})
+
+		Context("when the local file does not exist", func() {
+			It("returns an error", func() {
+				err := client.ProductFiles.VerifyFile(productSlug, releaseID, productFileID, "/not/a/real/file")
+				Expect(err).To(HaveOccurred())
+			})
+		})
+	})
+})
@@ -0,0 +1,116 @@
+package pivnet
+
+import (
+	"fmt"
+	"os"
+	"strconv"
+	"time"
+
+	"github.com/pivotal-cf/go-pivnet/logger"
+)
+
+// Environment variables read by ConfigFromEnv. Durations use the
+// time.ParseDuration format, e.g. "30s".
+const (
+	EnvHost                  = "PIVNET_HOST"
+	EnvAPIToken              = "PIVNET_API_TOKEN"
+	EnvUserAgent             = "PIVNET_USER_AGENT"
+	EnvSkipSSLValidation     = "PIVNET_SKIP_SSL_VALIDATION"
+	EnvDialTimeout           = "PIVNET_DIAL_TIMEOUT"
+	EnvTLSHandshakeTimeout   = "PIVNET_TLS_HANDSHAKE_TIMEOUT"
+	EnvResponseHeaderTimeout = "PIVNET_RESPONSE_HEADER_TIMEOUT"
+	EnvRetryMaxAttempts      = "PIVNET_RETRY_MAX_ATTEMPTS"
+	EnvRetryDelay            = "PIVNET_RETRY_DELAY"
+	EnvRetryMaxDelay         = "PIVNET_RETRY_MAX_DELAY"
+)
+
+// ConfigFromEnv returns a ClientConfig read from the environment:
+//
+//	PIVNET_HOST                     Host, defaults to DefaultHost
+//	PIVNET_API_TOKEN                Token, required
+//	PIVNET_USER_AGENT               UserAgent
+//	PIVNET_SKIP_SSL_VALIDATION      SkipSSLValidation, e.g. "true"
+//	PIVNET_DIAL_TIMEOUT             DialTimeout
+//	PIVNET_TLS_HANDSHAKE_TIMEOUT    TLSHandshakeTimeout
+//	PIVNET_RESPONSE_HEADER_TIMEOUT  ResponseHeaderTimeout
+//	PIVNET_RETRY_MAX_ATTEMPTS       RetryPolicy.MaxAttempts
+//	PIVNET_RETRY_DELAY              RetryPolicy.Delay
+//	PIVNET_RETRY_MAX_DELAY          RetryPolicy.MaxDelay
+//
+// Unset variables leave the field at its zero value. An error is returned if
+// the token is missing, a value cannot be parsed or the host is invalid.
+func ConfigFromEnv() (ClientConfig, error) {
+	config := ClientConfig{
+		Host:      os.Getenv(EnvHost),
+		Token:     os.Getenv(EnvAPIToken),
+		UserAgent: os.Getenv(EnvUserAgent),
+	}
+
+	if config.Host == "" {
+		config.Host = DefaultHost
+	}
+
+	if config.Token == "" {
+		return ClientConfig{}, fmt.Errorf("%s must be set", EnvAPIToken)
+	}
+
+	var err error
+	if value := os.Getenv(EnvSkipSSLValidation); value != "" {
+		config.SkipSSLValidation, err = strconv.ParseBool(value)
+		if err != nil {
+			return ClientConfig{}, envError(EnvSkipSSLValidation, value, err)
+		}
+	}
+
+	if value := os.Getenv(EnvRetryMaxAttempts); value != "" {
+		config.RetryPolicy.MaxAttempts, err = strconv.Atoi(value)
+		if err != nil {
+			return ClientConfig{}, envError(EnvRetryMaxAttempts, value, err)
+		}
+	}
+
+	durations := []struct {
+		name  string
+		field *time.Duration
+	}{
+		{EnvDialTimeout, &config.DialTimeout},
+		{EnvTLSHandshakeTimeout, &config.TLSHandshakeTimeout},
+		{EnvResponseHeaderTimeout, &config.ResponseHeaderTimeout},
+		{EnvRetryDelay, &config.RetryPolicy.Delay},
+		{EnvRetryMaxDelay, &config.RetryPolicy.MaxDelay},
+	}
+
+	for _, d := range durations {
+		value := os.Getenv(d.name)
+		if value == "" {
+			continue
+		}
+
+		*d.field, err = time.ParseDuration(value)
+		if err != nil {
+			return ClientConfig{}, envError(d.name, value, err)
+		}
+	}
+
+	err = config.Validate()
+	if err != nil {
+		return ClientConfig{}, err
+	}
+
+	return config, nil
+}
+
+// NewClientFromEnv returns a client configured by ConfigFromEnv. NewClient
+// remains the way to configure a client explicitly.
+func NewClientFromEnv(logger logger.Logger) (Client, error) {
+	config, err := ConfigFromEnv()
+	if err != nil {
+		return Client{}, err
+	}
+
+	return NewClient(config, logger), nil
+}
+
+func envError(name string, value string, err error) error {
+	return fmt.Errorf("Invalid %s '%s': %s", name, value, err.Error())
+}
@@ -0,0 +1,144 @@
+package pivnet_test
+
+import (
+	"os"
+	"time"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"github.com/pivotal-cf/go-pivnet"
+	"github.com/pivotal-cf/go-pivnet/logger/loggerfakes"
+)
+
+var _ = Describe("Configuration from the environment", func() {
+	var env map[string]string
+
+	BeforeEach(func() {
+		env = map[string]string{
+			pivnet.EnvAPIToken: "my-auth-token",
+		}
+	})
+
+	unsetAll := func() {
+		for _, name := range []string{
+			pivnet.EnvHost,
+			pivnet.EnvAPIToken,
+			pivnet.EnvUserAgent,
+			pivnet.EnvSkipSSLValidation,
+			pivnet.EnvDialTimeout,
+			pivnet.EnvTLSHandshakeTimeout,
+			pivnet.EnvResponseHeaderTimeout,
+			pivnet.EnvRetryMaxAttempts,
+			pivnet.EnvRetryDelay,
+			pivnet.EnvRetryMaxDelay,
+		} {
+			os.Unsetenv(name)
+		}
+	}
+
+	JustBeforeEach(func() {
+		unsetAll()
+		for name, value := range env {
+			os.Setenv(name, value)
+		}
+	})
+
+	AfterEach(unsetAll)
+
+	Describe("ConfigFromEnv", func() {
+		It("defaults the host", func() {
+			config, err := pivnet.ConfigFromEnv()
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(config).To(Equal(pivnet.ClientConfig{
+				Host:  pivnet.DefaultHost,
+				Token: "my-auth-token",
+			}))
+		})
+
+		Context("when every variable is set", func() {
+			BeforeEach(func() {
+				env[pivnet.EnvHost] = "https://example.com"
+				env[pivnet.EnvUserAgent] = "my-service/1.0"
+				env[pivnet.EnvSkipSSLValidation] = "true"
+				env[pivnet.EnvDialTimeout] = "5s"
+				env[pivnet.EnvTLSHandshakeTimeout] = "6s"
+				env[pivnet.EnvResponseHeaderTimeout] = "1m"
+				env[pivnet.EnvRetryMaxAttempts] = "4"
+				env[pivnet.EnvRetryDelay] = "2s"
+				env[pivnet.EnvRetryMaxDelay] = "30s"
+			})
+
+			It("reads them into the config", func() {
+				config, err := pivnet.ConfigFromEnv()
+				Expect(err).NotTo(HaveOccurred())
+
+				Expect(config).To(Equal(pivnet.ClientConfig{
+					Host:                  "https://example.com",
+					Token:                 "my-auth-token",
+					UserAgent:             "my-service/1.0",
+					SkipSSLValidation:     true,
+					DialTimeout:           5 * time.Second,
+					TLSHandshakeTimeout:   6 * time.Second,
+					ResponseHeaderTimeout: time.Minute,
+					RetryPolicy: pivnet.RetryPolicy{
+						MaxAttempts: 4,
+						Delay:       2 * time.Second,
+						MaxDelay:    30 * time.Second,
+					},
+				}))
+			})
+		})
+
+		Context("when the token is missing", func() {
+			BeforeEach(func() {
+				delete(env, pivnet.EnvAPIToken)
+			})
+
+			It("returns an error", func() {
+				_, err := pivnet.ConfigFromEnv()
+				Expect(err).To(MatchError("PIVNET_API_TOKEN must be set"))
+			})
+		})
+
+		Context("when a value cannot be parsed", func() {
+			BeforeEach(func() {
+				env[pivnet.EnvDialTimeout] = "soon"
+			})
+
+			It("returns an error naming the variable", func() {
+				_, err := pivnet.ConfigFromEnv()
+				Expect(err).To(MatchError(ContainSubstring("Invalid PIVNET_DIAL_TIMEOUT 'soon'")))
+			})
+		})
+
+		Context("when the host is invalid", func() {
+			BeforeEach(func() {
+				env[pivnet.EnvHost] = "example.com"
+			})
+
+			It("returns an error", func() {
+				_, err := pivnet.ConfigFromEnv()
+				Expect(err).To(MatchError(ContainSubstring("must start with http://")))
+			})
+		})
+	})
+
+	Describe("NewClientFromEnv", func() {
+		It("returns a client", func() {
+			_, err := pivnet.NewClientFromEnv(&loggerfakes.FakeLogger{})
+			Expect(err).NotTo(HaveOccurred())
+		})
+
+		Context("when the environment is incomplete", func() {
+			BeforeEach(func() {
+				delete(env, pivnet.EnvAPIToken)
+			})
+
+			It("returns an error", func() {
+				_, err := pivnet.NewClientFromEnv(&loggerfakes.FakeLogger{})
+				Expect(err).To(HaveOccurred())
+			})
+		})
+	})
+})
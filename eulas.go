@@ -9,6 +9,7 @@ import (
 
 type EULAsService struct {
 	client Client
+	cache  *ttlCache
 }
 
 type EULA struct {
@@ -29,7 +30,15 @@ type EULAAcceptanceResponse struct {
 	Links      *Links `json:"_links,omitempty"`
 }
 
+const eulaListCacheKey = "/eulas"
+
+// List returns all EULAs. The result is cached for
+// ClientConfig.EULACacheTTL.
 func (e EULAsService) List() ([]EULA, error) {
+	if eulas, ok := e.cache.get(eulaListCacheKey); ok {
+		return append([]EULA{}, eulas.([]EULA)...), nil
+	}
+
 	url := "/eulas"
 
 	var response EULAsResponse
@@ -49,12 +58,20 @@ func (e EULAsService) List() ([]EULA, error) {
 		return nil, err
 	}
 
+	e.cache.set(eulaListCacheKey, append([]EULA{}, response.EULAs...))
+
 	return response.EULAs, nil
 }
 
+// Get returns the EULA with the slug. The result is cached for
+// ClientConfig.EULACacheTTL.
 func (e EULAsService) Get(eulaSlug string) (EULA, error) {
 	url := fmt.Sprintf("/eulas/%s", eulaSlug)
 
+	if eula, ok := e.cache.get(url); ok {
+		return eula.(EULA), nil
+	}
+
 	var response EULA
 	resp, err := e.client.MakeRequest(
 		"GET",
@@ -72,9 +89,18 @@ func (e EULAsService) Get(eulaSlug string) (EULA, error) {
 		return EULA{}, err
 	}
 
+	e.cache.set(url, response)
+
 	return response, nil
 }
 
+// ClearCache forgets all EULAs cached by List and Get, so that the next
+// calls fetch them again. The cache is shared by clients derived from the
+// same NewClient call.
+func (e EULAsService) ClearCache() {
+	e.cache.clear()
+}
+
 func (e EULAsService) Accept(productSlug string, releaseID int) error {
 	url := fmt.Sprintf(
 		"/products/%s/releases/%d/eula_acceptance",
@@ -1,8 +1,10 @@
 package pivnet_test
 
 import (
+	"errors"
 	"fmt"
 	"net/http"
+	"time"
 
 	. "github.com/onsi/ginkgo"
 	. "github.com/onsi/gomega"
@@ -104,6 +106,20 @@ var _ = Describe("PivnetClient - EULA", func() {
 		})
 	})
 
+	Context("when getting a EULA that does not exist", func() {
+		It("returns an error matching ErrNotFound", func() {
+			server.AppendHandlers(
+				ghttp.CombineHandlers(
+					ghttp.VerifyRequest("GET", fmt.Sprintf("%s/eulas/%s", apiPrefix, "some-eula")),
+					ghttp.RespondWith(http.StatusNotFound, `{"message":"not found"}`),
+				),
+			)
+
+			_, err := client.EULA.Get("some-eula")
+			Expect(errors.Is(err, pivnet.ErrNotFound{})).To(BeTrue())
+		})
+	})
+
 	Describe("Get", func() {
 		var (
 			eulaSlug string
@@ -170,6 +186,111 @@ var _ = Describe("PivnetClient - EULA", func() {
 		})
 	})
 
+	Describe("caching", func() {
+		var (
+			now time.Time
+		)
+
+		BeforeEach(func() {
+			now = time.Date(2016, time.February, 3, 4, 5, 6, 0, time.UTC)
+			newClientConfig.Clock = func() time.Time { return now }
+			client = pivnet.NewClient(newClientConfig, fakeLogger)
+
+			server.RouteToHandler("GET", fmt.Sprintf("%s/eulas", apiPrefix),
+				ghttp.RespondWith(http.StatusOK, `{"eulas": [{"id":1,"slug":"eula1"}]}`),
+			)
+			server.RouteToHandler("GET", fmt.Sprintf("%s/eulas/eula1", apiPrefix),
+				ghttp.RespondWith(http.StatusOK, `{"id":1,"slug":"eula1"}`),
+			)
+		})
+
+		It("caches the EULAs returned by List and Get", func() {
+			for i := 0; i < 2; i++ {
+				eulas, err := client.EULA.List()
+				Expect(err).NotTo(HaveOccurred())
+				Expect(eulas).To(Equal([]pivnet.EULA{{ID: 1, Slug: "eula1"}}))
+
+				eula, err := client.EULA.Get("eula1")
+				Expect(err).NotTo(HaveOccurred())
+				Expect(eula).To(Equal(pivnet.EULA{ID: 1, Slug: "eula1"}))
+			}
+
+			Expect(server.ReceivedRequests()).To(HaveLen(2))
+		})
+
+		It("does not let callers modify the cached list", func() {
+			eulas, err := client.EULA.List()
+			Expect(err).NotTo(HaveOccurred())
+			eulas[0].Slug = "modified"
+
+			eulas, err = client.EULA.List()
+			Expect(err).NotTo(HaveOccurred())
+			Expect(eulas[0].Slug).To(Equal("eula1"))
+		})
+
+		It("fetches the EULAs again once the TTL has passed", func() {
+			_, err := client.EULA.Get("eula1")
+			Expect(err).NotTo(HaveOccurred())
+
+			now = now.Add(pivnet.DefaultEULACacheTTL)
+
+			_, err = client.EULA.Get("eula1")
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(server.ReceivedRequests()).To(HaveLen(2))
+		})
+
+		It("fetches the EULAs again once the cache is cleared", func() {
+			_, err := client.EULA.List()
+			Expect(err).NotTo(HaveOccurred())
+
+			client.EULA.ClearCache()
+
+			_, err = client.EULA.List()
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(server.ReceivedRequests()).To(HaveLen(2))
+		})
+
+		Context("when caching is disabled", func() {
+			BeforeEach(func() {
+				newClientConfig.EULACacheTTL = -1
+				client = pivnet.NewClient(newClientConfig, fakeLogger)
+			})
+
+			It("requests the EULAs every time", func() {
+				for i := 0; i < 2; i++ {
+					_, err := client.EULA.List()
+					Expect(err).NotTo(HaveOccurred())
+
+					_, err = client.EULA.Get("eula1")
+					Expect(err).NotTo(HaveOccurred())
+				}
+
+				Expect(server.ReceivedRequests()).To(HaveLen(4))
+			})
+
+			It("can still be cleared", func() {
+				client.EULA.ClearCache()
+			})
+		})
+
+		Context("when the request fails", func() {
+			It("does not cache the error", func() {
+				server.RouteToHandler("GET", fmt.Sprintf("%s/eulas/missing", apiPrefix),
+					ghttp.RespondWith(http.StatusNotFound, `{"message":"not found"}`),
+				)
+
+				for i := 0; i < 2; i++ {
+					_, err := client.EULA.Get("missing")
+					Expect(err).To(HaveOccurred())
+				}
+
+				Expect(server.ReceivedRequests()).To(HaveLen(2))
+			})
+		})
+	})
+
 	Describe("Accept", func() {
 		var (
 			releaseID         int
@@ -0,0 +1,176 @@
+package pivnet
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"sync"
+)
+
+const (
+	ExportRecordTypeProduct = "product"
+	ExportRecordTypeRelease = "release"
+	ExportRecordTypeError   = "error"
+)
+
+type ExportRecord struct {
+	Type         string               `json:"type" yaml:"type"`
+	ProductSlug  string               `json:"product_slug,omitempty" yaml:"product_slug,omitempty"`
+	Product      *Product             `json:"product,omitempty" yaml:"product,omitempty"`
+	Release      *Release             `json:"release,omitempty" yaml:"release,omitempty"`
+	ProductFiles []ProductFile        `json:"product_files,omitempty" yaml:"product_files,omitempty"`
+	UpgradePaths []ReleaseUpgradePath `json:"upgrade_paths,omitempty" yaml:"upgrade_paths,omitempty"`
+	ReleaseID    int                  `json:"release_id,omitempty" yaml:"release_id,omitempty"`
+	Error        string               `json:"error,omitempty" yaml:"error,omitempty"`
+}
+
+type ExportProgress struct {
+	ProductsTotal    int
+	ProductsExported int
+	ReleasesExported int
+	Errors           int
+}
+
+// ExportAll writes a snapshot of every product visible to the client, its
+// releases, their product files and upgrade paths to writer as
+// newline-delimited JSON ExportRecords. Releases of each product are
+// fetched using at most concurrency simultaneous workers.
+//
+// Failures to fetch an individual resource are written as error records and
+// do not abort the export. The export stops, returning the error, if writing
+// fails or ctx is done; ctx also cancels requests in flight. progress, if
+// not nil, is called after each product.
+func (c Client) ExportAll(
+	ctx context.Context,
+	writer io.Writer,
+	concurrency int,
+	progress func(ExportProgress),
+) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	c = c.WithContext(ctx)
+
+	e := &exporter{
+		client:  c,
+		encoder: json.NewEncoder(writer),
+	}
+
+	products, err := c.Products.List()
+	if err != nil {
+		return err
+	}
+
+	e.progress.ProductsTotal = len(products)
+
+	for i := range products {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+
+		err := e.exportProduct(ctx, products[i], concurrency)
+		if err != nil {
+			return err
+		}
+
+		e.progress.ProductsExported++
+		if progress != nil {
+			progress(e.progress)
+		}
+	}
+
+	return nil
+}
+
+type exporter struct {
+	client  Client
+	encoder *json.Encoder
+
+	mutex    sync.Mutex
+	progress ExportProgress
+	writeErr error
+}
+
+func (e *exporter) write(record ExportRecord) {
+	e.mutex.Lock()
+	defer e.mutex.Unlock()
+
+	if record.Type == ExportRecordTypeError {
+		e.progress.Errors++
+	}
+	if record.Type == ExportRecordTypeRelease {
+		e.progress.ReleasesExported++
+	}
+
+	if e.writeErr == nil {
+		e.writeErr = e.encoder.Encode(record)
+	}
+}
+
+func (e *exporter) exportProduct(ctx context.Context, product Product, concurrency int) error {
+	e.write(ExportRecord{
+		Type:        ExportRecordTypeProduct,
+		ProductSlug: product.Slug,
+		Product:     &product,
+	})
+
+	releases, err := e.client.Releases.List(product.Slug)
+	if err != nil {
+		e.write(ExportRecord{
+			Type:        ExportRecordTypeError,
+			ProductSlug: product.Slug,
+			Error:       err.Error(),
+		})
+		if e.writeErr != nil {
+			return e.writeErr
+		}
+		return ctx.Err()
+	}
+
+	forEachConcurrently(len(releases), concurrency, func(i int) {
+		if ctx.Err() != nil {
+			return
+		}
+
+		e.exportRelease(product.Slug, releases[i])
+	})
+
+	if e.writeErr != nil {
+		return e.writeErr
+	}
+
+	return ctx.Err()
+}
+
+func (e *exporter) exportRelease(productSlug string, release Release) {
+	productFiles, err := e.client.ProductFiles.ListForRelease(productSlug, release.ID)
+	if err != nil {
+		e.write(ExportRecord{
+			Type:        ExportRecordTypeError,
+			ProductSlug: productSlug,
+			ReleaseID:   release.ID,
+			Error:       err.Error(),
+		})
+		return
+	}
+
+	upgradePaths, err := e.client.ReleaseUpgradePaths.Get(productSlug, release.ID)
+	if err != nil {
+		e.write(ExportRecord{
+			Type:        ExportRecordTypeError,
+			ProductSlug: productSlug,
+			ReleaseID:   release.ID,
+			Error:       err.Error(),
+		})
+		return
+	}
+
+	e.write(ExportRecord{
+		Type:         ExportRecordTypeRelease,
+		ProductSlug:  productSlug,
+		Release:      &release,
+		ProductFiles: productFiles,
+		UpgradePaths: upgradePaths,
+	})
+}
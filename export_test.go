@@ -0,0 +1,184 @@
+package pivnet_test
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"github.com/onsi/gomega/ghttp"
+	"github.com/pivotal-cf/go-pivnet"
+	"github.com/pivotal-cf/go-pivnet/logger"
+	"github.com/pivotal-cf/go-pivnet/logger/loggerfakes"
+)
+
+var _ = Describe("PivnetClient - export", func() {
+	var (
+		server     *ghttp.Server
+		client     pivnet.Client
+		token      string
+		apiAddress string
+		userAgent  string
+
+		newClientConfig pivnet.ClientConfig
+		fakeLogger      logger.Logger
+	)
+
+	BeforeEach(func() {
+		server = ghttp.NewServer()
+		apiAddress = server.URL()
+		token = "my-auth-token"
+		userAgent = "pivnet-resource/0.1.0 (some-url)"
+
+		fakeLogger = &loggerfakes.FakeLogger{}
+		newClientConfig = pivnet.ClientConfig{
+			Host:      apiAddress,
+			Token:     token,
+			UserAgent: userAgent,
+		}
+		client = pivnet.NewClient(newClientConfig, fakeLogger)
+	})
+
+	AfterEach(func() {
+		server.Close()
+	})
+
+	Describe("ExportAll", func() {
+		var (
+			output bytes.Buffer
+		)
+
+		BeforeEach(func() {
+			output.Reset()
+
+			server.RouteToHandler("GET", apiPrefix+"/products",
+				ghttp.RespondWith(http.StatusOK, `{"products":[{"id":1,"slug":"banana"},{"id":2,"slug":"apple"}]}`),
+			)
+			server.RouteToHandler("GET", apiPrefix+"/products/banana/releases",
+				ghttp.RespondWith(http.StatusOK, `{"releases":[{"id":10,"version":"1.0.0"},{"id":11,"version":"1.1.0"}]}`),
+			)
+			server.RouteToHandler("GET", apiPrefix+"/products/apple/releases",
+				ghttp.RespondWith(http.StatusTeapot, `{"message":"apple message"}`),
+			)
+
+			for _, id := range []int{10, 11} {
+				server.RouteToHandler("GET", fmt.Sprintf("%s/products/banana/releases/%d/product_files", apiPrefix, id),
+					ghttp.RespondWith(http.StatusOK, `{"product_files":[{"id":100}]}`),
+				)
+			}
+
+			server.RouteToHandler("GET", apiPrefix+"/products/banana/releases/10/upgrade_paths",
+				ghttp.RespondWith(http.StatusOK, `{"upgrade_paths":[]}`),
+			)
+			server.RouteToHandler("GET", apiPrefix+"/products/banana/releases/11/upgrade_paths",
+				ghttp.RespondWith(http.StatusOK, `{"upgrade_paths":[{"release":{"id":10,"version":"1.0.0"}}]}`),
+			)
+		})
+
+		readRecords := func() []pivnet.ExportRecord {
+			var records []pivnet.ExportRecord
+
+			scanner := bufio.NewScanner(&output)
+			for scanner.Scan() {
+				var record pivnet.ExportRecord
+				Expect(json.Unmarshal(scanner.Bytes(), &record)).To(Succeed())
+				records = append(records, record)
+			}
+
+			return records
+		}
+
+		It("writes a record per product and release, and error records for failures", func() {
+			var progress []pivnet.ExportProgress
+
+			err := client.ExportAll(context.Background(), &output, 2, func(p pivnet.ExportProgress) {
+				progress = append(progress, p)
+			})
+			Expect(err).NotTo(HaveOccurred())
+
+			records := readRecords()
+			Expect(records).To(HaveLen(5))
+
+			var types []string
+			releaseIDs := map[int]int{}
+			for _, record := range records {
+				types = append(types, record.Type)
+
+				if record.Type == pivnet.ExportRecordTypeRelease {
+					releaseIDs[record.Release.ID] = len(record.UpgradePaths)
+					Expect(record.ProductFiles).To(HaveLen(1))
+				}
+
+				if record.Type == pivnet.ExportRecordTypeError {
+					Expect(record.ProductSlug).To(Equal("apple"))
+					Expect(record.Error).To(ContainSubstring("apple message"))
+				}
+			}
+
+			Expect(types).To(ConsistOf("product", "release", "release", "product", "error"))
+			Expect(releaseIDs).To(Equal(map[int]int{10: 0, 11: 1}))
+
+			Expect(progress).To(Equal([]pivnet.ExportProgress{
+				{ProductsTotal: 2, ProductsExported: 1, ReleasesExported: 2},
+				{ProductsTotal: 2, ProductsExported: 2, ReleasesExported: 2, Errors: 1},
+			}))
+		})
+
+		Context("when the context is cancelled", func() {
+			It("stops and returns the context error", func() {
+				ctx, cancel := context.WithCancel(context.Background())
+				cancel()
+
+				err := client.ExportAll(ctx, &output, 2, nil)
+				Expect(err).To(Equal(context.Canceled))
+
+				Expect(output.Len()).To(BeZero())
+			})
+
+			Context("while a request is in flight", func() {
+				It("aborts the request and returns the context error", func() {
+					ctx, cancel := context.WithCancel(context.Background())
+					defer cancel()
+
+					server.RouteToHandler("GET", apiPrefix+"/products/banana/releases",
+						func(w http.ResponseWriter, r *http.Request) {
+							cancel()
+							<-r.Context().Done()
+						},
+					)
+
+					err := client.ExportAll(ctx, &output, 2, nil)
+					Expect(err).To(Equal(context.Canceled))
+
+					for _, req := range server.ReceivedRequests() {
+						Expect(req.URL.Path).NotTo(Equal(apiPrefix + "/products/apple/releases"))
+					}
+				})
+			})
+		})
+
+		Context("when listing products fails", func() {
+			BeforeEach(func() {
+				server.RouteToHandler("GET", apiPrefix+"/products",
+					ghttp.RespondWith(http.StatusTeapot, `{"message":"foo message"}`),
+				)
+			})
+
+			It("returns the error", func() {
+				err := client.ExportAll(context.Background(), &output, 2, nil)
+				Expect(err).To(MatchError(ContainSubstring("foo message")))
+			})
+		})
+
+		Context("when writing fails", func() {
+			It("returns the error", func() {
+				err := client.ExportAll(context.Background(), errWriter{}, 2, nil)
+				Expect(err).To(MatchError("error writing"))
+			})
+		})
+	})
+})
@@ -5,6 +5,8 @@ import (
 	"encoding/json"
 	"fmt"
 	"net/http"
+
+	"github.com/pivotal-cf/go-pivnet/logger"
 )
 
 type FileGroupsService struct {
@@ -202,6 +204,13 @@ func (p FileGroupsService) Delete(productSlug string, id int) (FileGroup, error)
 	return response, nil
 }
 
+// DeleteIfExists deletes the file group, treating a file group that does not
+// exist as already deleted. Use Delete to find out whether it existed.
+func (p FileGroupsService) DeleteIfExists(productSlug string, id int) error {
+	_, err := p.Delete(productSlug, id)
+	return ignoreNotFound(err)
+}
+
 func (p FileGroupsService) ListForRelease(productSlug string, releaseID int) ([]FileGroup, error) {
 	url := fmt.Sprintf("/products/%s/releases/%d/file_groups",
 		productSlug,
@@ -228,6 +237,34 @@ func (p FileGroupsService) ListForRelease(productSlug string, releaseID int) ([]
 	return response.FileGroups, nil
 }
 
+// Releases returns the releases of the product the file group is attached
+// to. Pivnet does not provide this directly, so it is derived by listing the
+// file groups of every release of the product, which costs one request per
+// release.
+func (p FileGroupsService) Releases(productSlug string, fileGroupID int) ([]Release, error) {
+	releases, err := ReleasesService{client: p.client}.List(productSlug)
+	if err != nil {
+		return nil, err
+	}
+
+	associated := []Release{}
+	for _, release := range releases {
+		fileGroups, err := p.ListForRelease(productSlug, release.ID)
+		if err != nil {
+			return nil, err
+		}
+
+		for _, fileGroup := range fileGroups {
+			if fileGroup.ID == fileGroupID {
+				associated = append(associated, release)
+				break
+			}
+		}
+	}
+
+	return associated, nil
+}
+
 func (r FileGroupsService) AddToRelease(
 	productSlug string,
 	releaseID int,
@@ -304,6 +341,60 @@ func (r FileGroupsService) RemoveFromRelease(
 	return nil
 }
 
+// CopyToRelease creates a file group with the name and product files of the
+// source file group and adds it to the target release. If some of the
+// product files cannot be added to the new group, the group is returned
+// with the files that were added and a MultiError identifying the others
+// by product file ID.
+//
+// If the new group cannot be added to the release it is deleted again. Should
+// that fail too, the group is returned with the error, and it is up to the
+// caller to delete it.
+func (p FileGroupsService) CopyToRelease(
+	productSlug string,
+	sourceFileGroupID int,
+	targetReleaseID int,
+) (FileGroup, error) {
+	source, err := p.Get(productSlug, sourceFileGroupID)
+	if err != nil {
+		return FileGroup{}, err
+	}
+
+	fileGroup, err := p.Create(productSlug, source.Name)
+	if err != nil {
+		return FileGroup{}, err
+	}
+
+	err = p.AddToRelease(productSlug, targetReleaseID, fileGroup.ID)
+	if err != nil {
+		_, deleteErr := p.Delete(productSlug, fileGroup.ID)
+		if deleteErr != nil {
+			p.client.logger.Info(
+				"Failed to delete file group that could not be added to release",
+				logger.Data{"file_group_id": fileGroup.ID, "error": deleteErr.Error()},
+			)
+			return fileGroup, err
+		}
+
+		return FileGroup{}, err
+	}
+
+	productFiles := ProductFilesService{client: p.client}
+
+	errs := map[int]error{}
+	fileGroup.ProductFiles = nil
+	for _, pf := range source.ProductFiles {
+		err := productFiles.AddToFileGroup(productSlug, fileGroup.ID, pf.ID)
+		if err != nil {
+			errs[pf.ID] = err
+			continue
+		}
+		fileGroup.ProductFiles = append(fileGroup.ProductFiles, pf)
+	}
+
+	return fileGroup, newMultiError(errs)
+}
+
 type addRemoveFileGroupBody struct {
 	FileGroup addRemoveFileGroupBodyFileGroup `json:"file_group"`
 }
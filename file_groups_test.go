@@ -1,6 +1,7 @@
 package pivnet_test
 
 import (
+	"errors"
 	"fmt"
 	"net/http"
 
@@ -204,6 +205,89 @@ var _ = Describe("PivnetClient - FileGroup", func() {
 		})
 	})
 
+	Describe("Releases", func() {
+		var (
+			releasesURL string
+		)
+
+		BeforeEach(func() {
+			releasesURL = fmt.Sprintf("%s/products/%s/releases", apiPrefix, productSlug)
+		})
+
+		It("returns the releases the file group is attached to", func() {
+			server.AppendHandlers(
+				ghttp.CombineHandlers(
+					ghttp.VerifyRequest("GET", releasesURL),
+					ghttp.RespondWith(http.StatusOK, `{"releases":[{"id":1,"version":"1.0.0"},{"id":2,"version":"2.0.0"}]}`),
+				),
+				ghttp.CombineHandlers(
+					ghttp.VerifyRequest("GET", releasesURL+"/1/file_groups"),
+					ghttp.RespondWith(http.StatusOK, `{"file_groups":[{"id":5},{"id":6}]}`),
+				),
+				ghttp.CombineHandlers(
+					ghttp.VerifyRequest("GET", releasesURL+"/2/file_groups"),
+					ghttp.RespondWith(http.StatusOK, `{"file_groups":[{"id":5}]}`),
+				),
+			)
+
+			releases, err := client.FileGroups.Releases(productSlug, 6)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(releases).To(Equal([]pivnet.Release{{ID: 1, Version: "1.0.0"}}))
+		})
+
+		Context("when the file group is not attached to any release", func() {
+			It("returns an empty slice", func() {
+				server.AppendHandlers(
+					ghttp.CombineHandlers(
+						ghttp.VerifyRequest("GET", releasesURL),
+						ghttp.RespondWith(http.StatusOK, `{"releases":[{"id":1}]}`),
+					),
+					ghttp.CombineHandlers(
+						ghttp.VerifyRequest("GET", releasesURL+"/1/file_groups"),
+						ghttp.RespondWith(http.StatusOK, `{"file_groups":[]}`),
+					),
+				)
+
+				releases, err := client.FileGroups.Releases(productSlug, 6)
+				Expect(err).NotTo(HaveOccurred())
+				Expect(releases).NotTo(BeNil())
+				Expect(releases).To(BeEmpty())
+			})
+		})
+
+		Context("when listing file groups for a release returns an error", func() {
+			It("forwards the error", func() {
+				server.AppendHandlers(
+					ghttp.CombineHandlers(
+						ghttp.VerifyRequest("GET", releasesURL),
+						ghttp.RespondWith(http.StatusOK, `{"releases":[{"id":1}]}`),
+					),
+					ghttp.CombineHandlers(
+						ghttp.VerifyRequest("GET", releasesURL+"/1/file_groups"),
+						ghttp.RespondWith(http.StatusTeapot, `{"message":"foo message"}`),
+					),
+				)
+
+				_, err := client.FileGroups.Releases(productSlug, 6)
+				Expect(err.Error()).To(ContainSubstring("foo message"))
+			})
+		})
+	})
+
+	Context("when getting a file group that does not exist", func() {
+		It("returns an error matching ErrNotFound", func() {
+			server.AppendHandlers(
+				ghttp.CombineHandlers(
+					ghttp.VerifyRequest("GET", fmt.Sprintf("%s/products/%s/file_groups/%d", apiPrefix, productSlug, 12)),
+					ghttp.RespondWith(http.StatusNotFound, `{"message":"not found"}`),
+				),
+			)
+
+			_, err := client.FileGroups.Get(productSlug, 12)
+			Expect(errors.Is(err, pivnet.ErrNotFound{})).To(BeTrue())
+		})
+	})
+
 	Describe("Get File group", func() {
 		var (
 			productSlug string
@@ -540,6 +624,58 @@ var _ = Describe("PivnetClient - FileGroup", func() {
 		})
 	})
 
+	Describe("Delete File Group If Exists", func() {
+		var (
+			id = 1234
+		)
+
+		It("deletes the file group", func() {
+			server.AppendHandlers(
+				ghttp.CombineHandlers(
+					ghttp.VerifyRequest(
+						"DELETE",
+						fmt.Sprintf("%s/products/%s/file_groups/%d", apiPrefix, productSlug, id)),
+					ghttp.RespondWith(http.StatusOK, `{"id":1234}`),
+				),
+			)
+
+			err := client.FileGroups.DeleteIfExists(productSlug, id)
+			Expect(err).NotTo(HaveOccurred())
+		})
+
+		Context("when the file group does not exist", func() {
+			It("does not return an error", func() {
+				server.AppendHandlers(
+					ghttp.CombineHandlers(
+						ghttp.VerifyRequest(
+							"DELETE",
+							fmt.Sprintf("%s/products/%s/file_groups/%d", apiPrefix, productSlug, id)),
+						ghttp.RespondWithJSONEncoded(http.StatusNotFound, pivnetErr{Message: "not found"}),
+					),
+				)
+
+				err := client.FileGroups.DeleteIfExists(productSlug, id)
+				Expect(err).NotTo(HaveOccurred())
+			})
+		})
+
+		Context("when the server responds with another non-2XX status code", func() {
+			It("returns an error", func() {
+				server.AppendHandlers(
+					ghttp.CombineHandlers(
+						ghttp.VerifyRequest(
+							"DELETE",
+							fmt.Sprintf("%s/products/%s/file_groups/%d", apiPrefix, productSlug, id)),
+						ghttp.RespondWithJSONEncoded(http.StatusTeapot, pivnetErr{Message: "foo message"}),
+					),
+				)
+
+				err := client.FileGroups.DeleteIfExists(productSlug, id)
+				Expect(err).To(HaveOccurred())
+				Expect(err.Error()).To(ContainSubstring("foo message"))
+			})
+		})
+	})
 	Describe("Add File Group", func() {
 		var (
 			productSlug = "some-product"
@@ -703,4 +839,123 @@ var _ = Describe("PivnetClient - FileGroup", func() {
 			})
 		})
 	})
+
+	Describe("CopyToRelease", func() {
+		var (
+			productSlug     = "banana"
+			sourceID        = 1234
+			targetReleaseID = 2345
+			newID           = 3456
+		)
+
+		BeforeEach(func() {
+			server.AppendHandlers(
+				ghttp.CombineHandlers(
+					ghttp.VerifyRequest("GET", fmt.Sprintf("%s/products/%s/file_groups/%d", apiPrefix, productSlug, sourceID)),
+					ghttp.RespondWithJSONEncoded(http.StatusOK, pivnet.FileGroup{
+						ID:           sourceID,
+						Name:         "some-group",
+						ProductFiles: []pivnet.ProductFile{{ID: 11}, {ID: 12}, {ID: 13}},
+					}),
+				),
+				ghttp.CombineHandlers(
+					ghttp.VerifyRequest("POST", fmt.Sprintf("%s/products/%s/file_groups", apiPrefix, productSlug)),
+					ghttp.VerifyJSON(`{"file_group":{"name":"some-group"}}`),
+					ghttp.RespondWithJSONEncoded(http.StatusCreated, pivnet.FileGroup{ID: newID, Name: "some-group"}),
+				),
+				ghttp.CombineHandlers(
+					ghttp.VerifyRequest("PATCH", fmt.Sprintf("%s/products/%s/releases/%d/add_file_group", apiPrefix, productSlug, targetReleaseID)),
+					ghttp.VerifyJSON(`{"file_group":{"id":3456}}`),
+					ghttp.RespondWith(http.StatusNoContent, nil),
+				),
+			)
+		})
+
+		addProductFileHandler := func(productFileID int, status int) http.HandlerFunc {
+			return ghttp.CombineHandlers(
+				ghttp.VerifyRequest("PATCH", fmt.Sprintf("%s/products/%s/file_groups/%d/add_product_file", apiPrefix, productSlug, newID)),
+				ghttp.VerifyJSON(fmt.Sprintf(`{"product_file":{"id":%d}}`, productFileID)),
+				ghttp.RespondWith(status, `{"message":"foo message"}`),
+			)
+		}
+
+		It("creates an equivalent file group on the target release", func() {
+			server.AppendHandlers(
+				addProductFileHandler(11, http.StatusNoContent),
+				addProductFileHandler(12, http.StatusNoContent),
+				addProductFileHandler(13, http.StatusNoContent),
+			)
+
+			fileGroup, err := client.FileGroups.CopyToRelease(productSlug, sourceID, targetReleaseID)
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(fileGroup.ID).To(Equal(newID))
+			Expect(fileGroup.Name).To(Equal("some-group"))
+			Expect(fileGroup.ProductFiles).To(Equal([]pivnet.ProductFile{{ID: 11}, {ID: 12}, {ID: 13}}))
+		})
+
+		Context("when some product files cannot be added", func() {
+			It("returns the group and reports the files that were not added", func() {
+				server.AppendHandlers(
+					addProductFileHandler(11, http.StatusNoContent),
+					addProductFileHandler(12, http.StatusTeapot),
+					addProductFileHandler(13, http.StatusNoContent),
+				)
+
+				fileGroup, err := client.FileGroups.CopyToRelease(productSlug, sourceID, targetReleaseID)
+				Expect(fileGroup.ID).To(Equal(newID))
+				Expect(fileGroup.ProductFiles).To(Equal([]pivnet.ProductFile{{ID: 11}, {ID: 13}}))
+
+				var multiErr pivnet.MultiError
+				Expect(errors.As(err, &multiErr)).To(BeTrue())
+				Expect(multiErr.Errors).To(HaveLen(1))
+				Expect(multiErr.Errors[0].ID).To(Equal(12))
+				Expect(multiErr.Errors[0].Err).To(MatchError(ContainSubstring("foo message")))
+			})
+		})
+
+		Context("when the group cannot be added to the release", func() {
+			var deleteStatus int
+
+			BeforeEach(func() {
+				deleteStatus = http.StatusOK
+
+				server.SetHandler(2, ghttp.CombineHandlers(
+					ghttp.VerifyRequest("PATCH", fmt.Sprintf("%s/products/%s/releases/%d/add_file_group", apiPrefix, productSlug, targetReleaseID)),
+					ghttp.RespondWith(http.StatusTeapot, `{"message":"foo message"}`),
+				))
+			})
+
+			JustBeforeEach(func() {
+				server.AppendHandlers(
+					ghttp.CombineHandlers(
+						ghttp.VerifyRequest("DELETE", fmt.Sprintf("%s/products/%s/file_groups/%d", apiPrefix, productSlug, newID)),
+						func(w http.ResponseWriter, r *http.Request) {
+							ghttp.RespondWithJSONEncoded(deleteStatus, pivnet.FileGroup{ID: newID})(w, r)
+						},
+					),
+				)
+			})
+
+			It("deletes the new group and returns the error", func() {
+				fileGroup, err := client.FileGroups.CopyToRelease(productSlug, sourceID, targetReleaseID)
+				Expect(err).To(MatchError(ContainSubstring("foo message")))
+				Expect(fileGroup).To(Equal(pivnet.FileGroup{}))
+
+				Expect(server.ReceivedRequests()).To(HaveLen(4))
+			})
+
+			Context("when the group cannot be deleted either", func() {
+				BeforeEach(func() {
+					deleteStatus = http.StatusTeapot
+				})
+
+				It("returns the group with the error so that the caller can clean it up", func() {
+					fileGroup, err := client.FileGroups.CopyToRelease(productSlug, sourceID, targetReleaseID)
+					Expect(err).To(MatchError(ContainSubstring("foo message")))
+					Expect(fileGroup.ID).To(Equal(newID))
+				})
+			})
+		})
+	})
 })
@@ -1,8 +1,23 @@
 package pivnet
 
+import "fmt"
+
 type Links struct {
 	EULA           map[string]string `json:"eula,omitempty" yaml:"eula,omitempty"`
 	Download       map[string]string `json:"download,omitempty" yaml:"download,omitempty"`
 	ProductFiles   map[string]string `json:"product_files,omitempty" yaml:"product_files,omitempty"`
 	EULAAcceptance map[string]string `json:"eula_acceptance,omitempty" yaml:"eula_acceptance,omitempty"`
 }
+
+// LinksMap holds every entry of a resource's _links object, keyed by link
+// name, including links not modelled on Links.
+type LinksMap map[string]map[string]string
+
+func (l LinksMap) Href(name string) (string, error) {
+	link, ok := l[name]
+	if !ok || link["href"] == "" {
+		return "", fmt.Errorf("Could not find link '%s' in links map", name)
+	}
+
+	return link["href"], nil
+}
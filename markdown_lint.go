@@ -0,0 +1,236 @@
+package pivnet
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/pivotal-cf/go-pivnet/logger"
+)
+
+// MarkdownLintMode controls whether release descriptions are checked with
+// LintMarkdown before Releases.Create and Releases.Update send them.
+type MarkdownLintMode int
+
+const (
+	// MarkdownLintOff sends descriptions without checking them.
+	MarkdownLintOff MarkdownLintMode = iota
+
+	// MarkdownLintWarn logs a warning for each problem and sends the
+	// description anyway.
+	MarkdownLintWarn
+
+	// MarkdownLintStrict returns a MarkdownLintError instead of sending a
+	// description with problems.
+	MarkdownLintStrict
+)
+
+type MarkdownWarning struct {
+	Line    int
+	Message string
+}
+
+func (w MarkdownWarning) String() string {
+	return fmt.Sprintf("line %d: %s", w.Line, w.Message)
+}
+
+type MarkdownLintError struct {
+	Warnings []MarkdownWarning
+}
+
+func (e MarkdownLintError) Error() string {
+	problems := make([]string, len(e.Warnings))
+	for i, w := range e.Warnings {
+		problems[i] = w.String()
+	}
+
+	return fmt.Sprintf(
+		"Release description is not well-formed markdown - %s",
+		strings.Join(problems, "; "),
+	)
+}
+
+var markdownEmphasisMarkers = []string{"**", "__", "~~"}
+
+// LintMarkdown returns likely formatting mistakes in text: unclosed code
+// blocks and inline code, "**", "__" and "~~" left open within a paragraph,
+// unbalanced square brackets and links with a missing or empty URL. It is a
+// heuristic rather than a markdown parser, and ignores the content of code.
+func LintMarkdown(text string) []MarkdownWarning {
+	lines := strings.Split(strings.Replace(text, "\r\n", "\n", -1), "\n")
+
+	var warnings []MarkdownWarning
+	var paragraph []string
+	var paragraphLine int
+
+	flush := func() {
+		if len(paragraph) > 0 {
+			warnings = append(warnings, lintParagraph(paragraphLine, strings.Join(paragraph, "\n"))...)
+			paragraph = nil
+		}
+	}
+
+	fence := ""
+	fenceLine := 0
+	for i, line := range lines {
+		trimmed := strings.TrimSpace(line)
+
+		if fence != "" {
+			if strings.HasPrefix(trimmed, fence) {
+				fence = ""
+			}
+			continue
+		}
+
+		if strings.HasPrefix(trimmed, "```") || strings.HasPrefix(trimmed, "~~~") {
+			flush()
+			fence = trimmed[:3]
+			fenceLine = i + 1
+			continue
+		}
+
+		if trimmed == "" {
+			flush()
+			continue
+		}
+
+		if len(paragraph) == 0 {
+			paragraphLine = i + 1
+		}
+		paragraph = append(paragraph, line)
+	}
+	flush()
+
+	if fence != "" {
+		warnings = append(warnings, MarkdownWarning{
+			Line:    fenceLine,
+			Message: "code block is not closed",
+		})
+	}
+
+	sort.SliceStable(warnings, func(i, j int) bool {
+		return warnings[i].Line < warnings[j].Line
+	})
+
+	return warnings
+}
+
+// lintParagraph checks a paragraph starting at line firstLine, returning
+// warnings in the order in which they occur.
+func lintParagraph(firstLine int, paragraph string) []MarkdownWarning {
+	var warnings []MarkdownWarning
+	var offsets []int
+	warn := func(offset int, message string) {
+		warnings = append(warnings, MarkdownWarning{
+			Line:    firstLine + strings.Count(paragraph[:offset], "\n"),
+			Message: message,
+		})
+		offsets = append(offsets, offset)
+	}
+
+	if strings.Count(paragraph, "`")%2 != 0 {
+		warn(strings.LastIndex(paragraph, "`"), "inline code is not closed")
+	}
+
+	text := maskInlineCode(paragraph)
+
+	for _, marker := range markdownEmphasisMarkers {
+		if strings.Count(text, marker)%2 != 0 {
+			warn(strings.LastIndex(text, marker), fmt.Sprintf("'%s' is not closed", marker))
+		}
+	}
+
+	depth := 0
+	for i, c := range text {
+		switch c {
+		case '[':
+			depth++
+		case ']':
+			if depth == 0 {
+				warn(i, "']' has no matching '['")
+				continue
+			}
+			depth--
+
+			rest := text[i+1:]
+			switch {
+			case strings.HasPrefix(rest, "()"):
+				warn(i, "link has no URL")
+			case strings.HasPrefix(rest, "("):
+				end := strings.IndexAny(rest, ")\n")
+				if end < 0 || rest[end] != ')' {
+					warn(i, "link URL is missing ')'")
+				}
+			case strings.HasPrefix(rest, " ("):
+				warn(i, "space between link text and URL")
+			}
+		}
+	}
+
+	if depth > 0 {
+		warn(strings.LastIndex(text, "["), "'[' is not closed")
+	}
+
+	sort.Sort(byOffset{warnings: warnings, offsets: offsets})
+
+	return warnings
+}
+
+type byOffset struct {
+	warnings []MarkdownWarning
+	offsets  []int
+}
+
+func (b byOffset) Len() int           { return len(b.offsets) }
+func (b byOffset) Less(i, j int) bool { return b.offsets[i] < b.offsets[j] }
+func (b byOffset) Swap(i, j int) {
+	b.warnings[i], b.warnings[j] = b.warnings[j], b.warnings[i]
+	b.offsets[i], b.offsets[j] = b.offsets[j], b.offsets[i]
+}
+
+// maskInlineCode blanks the content of inline code, keeping newlines so that
+// offsets still map to the same lines. Unclosed inline code is left as is.
+func maskInlineCode(text string) string {
+	if strings.Count(text, "`")%2 != 0 {
+		return text
+	}
+
+	masked := []byte(text)
+	inCode := false
+	for i, c := range masked {
+		switch {
+		case c == '`':
+			inCode = !inCode
+		case inCode && c != '\n':
+			masked[i] = ' '
+		}
+	}
+
+	return string(masked)
+}
+
+// lintDescription checks a release description according to the client's
+// MarkdownLintMode.
+func (r ReleasesService) lintDescription(description string) error {
+	if r.client.releaseDescriptionLint == MarkdownLintOff || description == "" {
+		return nil
+	}
+
+	warnings := LintMarkdown(description)
+	if len(warnings) == 0 {
+		return nil
+	}
+
+	if r.client.releaseDescriptionLint == MarkdownLintStrict {
+		return MarkdownLintError{Warnings: warnings}
+	}
+
+	for _, w := range warnings {
+		r.l.Info("Warning: release description may not render as intended", logger.Data{
+			"line":    w.Line,
+			"problem": w.Message,
+		})
+	}
+
+	return nil
+}
@@ -0,0 +1,56 @@
+package pivnet_test
+
+import (
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"github.com/pivotal-cf/go-pivnet"
+)
+
+var _ = Describe("LintMarkdown", func() {
+	It("returns no warnings for well-formed markdown", func() {
+		text := "# Release 1.2.3\n\n" +
+			"* **Fixed** a [bug](https://example.com/bug) in `foo_bar`\n" +
+			"* Added ~~nothing~~ something\n\n" +
+			"```\n[unbalanced **code\n```\n"
+
+		Expect(pivnet.LintMarkdown(text)).To(BeEmpty())
+	})
+
+	It("allows emphasis to span the lines of a paragraph", func() {
+		Expect(pivnet.LintMarkdown("some **bold\ntext** here")).To(BeEmpty())
+	})
+
+	It("reports emphasis that is not closed within a paragraph", func() {
+		Expect(pivnet.LintMarkdown("some **bold\n\ntext** here")).To(Equal([]pivnet.MarkdownWarning{
+			{Line: 1, Message: "'**' is not closed"},
+			{Line: 3, Message: "'**' is not closed"},
+		}))
+	})
+
+	It("reports broken link syntax", func() {
+		text := "a [link](https://example.com\n" +
+			"b [link]()\n" +
+			"c [link] (https://example.com)\n" +
+			"d link]\n" +
+			"e [link"
+
+		Expect(pivnet.LintMarkdown(text)).To(Equal([]pivnet.MarkdownWarning{
+			{Line: 1, Message: "link URL is missing ')'"},
+			{Line: 2, Message: "link has no URL"},
+			{Line: 3, Message: "space between link text and URL"},
+			{Line: 4, Message: "']' has no matching '['"},
+			{Line: 5, Message: "'[' is not closed"},
+		}))
+	})
+
+	It("reports code that is not closed", func() {
+		Expect(pivnet.LintMarkdown("run `foo\n\n```\ncode")).To(Equal([]pivnet.MarkdownWarning{
+			{Line: 1, Message: "inline code is not closed"},
+			{Line: 3, Message: "code block is not closed"},
+		}))
+	})
+
+	It("ignores the content of inline code", func() {
+		Expect(pivnet.LintMarkdown("use `a[0]]` and `**`")).To(BeEmpty())
+	})
+})
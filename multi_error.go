@@ -0,0 +1,68 @@
+package pivnet
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// ItemError is the error for one item of a bulk operation, identified by the
+// ID of the release, product file or other resource it concerns.
+type ItemError struct {
+	ID  int
+	Err error
+}
+
+func (e ItemError) Error() string {
+	return fmt.Sprintf("%d: %s", e.ID, e.Err)
+}
+
+func (e ItemError) Unwrap() error {
+	return e.Err
+}
+
+// MultiError is returned by bulk operations when some of their items fail.
+// errors.Is and errors.As match against the error of each item.
+type MultiError struct {
+	Errors []ItemError
+}
+
+func (e MultiError) Error() string {
+	messages := make([]string, len(e.Errors))
+	for i, err := range e.Errors {
+		messages[i] = err.Error()
+	}
+
+	return fmt.Sprintf(
+		"%d of the items failed - %s",
+		len(e.Errors),
+		strings.Join(messages, "; "),
+	)
+}
+
+func (e MultiError) Unwrap() []error {
+	errs := make([]error, len(e.Errors))
+	for i, err := range e.Errors {
+		errs[i] = err
+	}
+	return errs
+}
+
+// newMultiError returns a MultiError for errs, ordered by ID, or nil if errs
+// is empty.
+func newMultiError(errs map[int]error) error {
+	if len(errs) == 0 {
+		return nil
+	}
+
+	multiErr := MultiError{}
+	for id, err := range errs {
+		multiErr.Errors = append(multiErr.Errors, ItemError{ID: id, Err: err})
+	}
+
+	sort.Slice(multiErr.Errors, func(i, j int) bool {
+		return multiErr.Errors[i].ID < multiErr.Errors[j].ID
+	})
+
+	return multiErr
+}
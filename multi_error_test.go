@@ -0,0 +1,45 @@
+package pivnet_test
+
+import (
+	"errors"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"github.com/pivotal-cf/go-pivnet"
+)
+
+var _ = Describe("MultiError", func() {
+	var (
+		multiErr pivnet.MultiError
+	)
+
+	BeforeEach(func() {
+		multiErr = pivnet.MultiError{
+			Errors: []pivnet.ItemError{
+				{ID: 3, Err: errors.New("some error")},
+				{ID: 7, Err: pivnet.ErrNotFound{Message: "release not found"}},
+			},
+		}
+	})
+
+	It("combines the messages of each item", func() {
+		Expect(multiErr.Error()).To(Equal(
+			"2 of the items failed - 3: some error; 7: release not found",
+		))
+	})
+
+	It("matches the item errors with errors.Is", func() {
+		Expect(errors.Is(multiErr, pivnet.ErrNotFound{})).To(BeTrue())
+		Expect(errors.Is(multiErr, pivnet.ErrUnauthorized{})).To(BeFalse())
+	})
+
+	It("finds the item errors with errors.As", func() {
+		var itemErr pivnet.ItemError
+		Expect(errors.As(multiErr, &itemErr)).To(BeTrue())
+		Expect(itemErr.ID).To(Equal(3))
+
+		var notFound pivnet.ErrNotFound
+		Expect(errors.As(multiErr, &notFound)).To(BeTrue())
+		Expect(notFound.Message).To(Equal("release not found"))
+	})
+})
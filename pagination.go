@@ -0,0 +1,34 @@
+package pivnet
+
+import "encoding/json"
+
+// Pagination is the _pagination object of a list response. The list
+// endpoints used by this client return every item in one response, so it is
+// only present on responses from paginated endpoints requested directly,
+// for example with MakeRequestExpecting.
+type Pagination struct {
+	CurrentPage int `json:"current_page" yaml:"current_page"`
+	TotalPages  int `json:"total_pages" yaml:"total_pages"`
+	Total       int `json:"total" yaml:"total"`
+	PerPage     int `json:"per_page" yaml:"per_page"`
+}
+
+// HasNextPage reports whether there are pages after the current one.
+func (p Pagination) HasNextPage() bool {
+	return p.CurrentPage < p.TotalPages
+}
+
+// ParsePagination returns the _pagination object of the JSON list response
+// body, or nil if it has none.
+func ParsePagination(body []byte) (*Pagination, error) {
+	var response struct {
+		Pagination *Pagination `json:"_pagination"`
+	}
+
+	err := json.Unmarshal(body, &response)
+	if err != nil {
+		return nil, err
+	}
+
+	return response.Pagination, nil
+}
@@ -0,0 +1,51 @@
+package pivnet_test
+
+import (
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"github.com/pivotal-cf/go-pivnet"
+)
+
+var _ = Describe("Pagination", func() {
+	Describe("ParsePagination", func() {
+		It("parses the _pagination object of a list response", func() {
+			pagination, err := pivnet.ParsePagination([]byte(`{
+				"releases": [{"id": 1}, {"id": 2}],
+				"_pagination": {"current_page": 2, "total_pages": 5, "total": 98, "per_page": 20}
+			}`))
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(pagination).To(Equal(&pivnet.Pagination{
+				CurrentPage: 2,
+				TotalPages:  5,
+				Total:       98,
+				PerPage:     20,
+			}))
+		})
+
+		Context("when the response is not paginated", func() {
+			It("returns nil", func() {
+				pagination, err := pivnet.ParsePagination([]byte(`{"products": [{"id": 1}]}`))
+				Expect(err).NotTo(HaveOccurred())
+				Expect(pagination).To(BeNil())
+			})
+		})
+
+		Context("when the body is not JSON", func() {
+			It("returns an error", func() {
+				_, err := pivnet.ParsePagination([]byte(`%%%`))
+				Expect(err).To(HaveOccurred())
+			})
+		})
+	})
+
+	Describe("HasNextPage", func() {
+		It("is true before the last page", func() {
+			Expect(pivnet.Pagination{CurrentPage: 4, TotalPages: 5}.HasNextPage()).To(BeTrue())
+		})
+
+		It("is false on the last page", func() {
+			Expect(pivnet.Pagination{CurrentPage: 5, TotalPages: 5}.HasNextPage()).To(BeFalse())
+		})
+	})
+})
@@ -1,22 +1,34 @@
 package pivnet
 
 import (
+	"context"
 	"crypto/tls"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
 	"io/ioutil"
+	"net"
 	"net/http"
 	"net/http/httputil"
 	"net/url"
 	"strings"
+	"sync"
+	"time"
 
 	"github.com/pivotal-cf/go-pivnet/logger"
 )
 
 const (
-	DefaultHost = "https://network.pivotal.io"
-	apiVersion  = "/api/v2"
+	DefaultHost                = "https://network.pivotal.io"
+	DefaultMaxResponseBytes    = 32 * 1024 * 1024
+	DefaultProductSlugCacheTTL = time.Minute
+	DefaultEULACacheTTL        = time.Minute
+	DefaultContentType         = "application/json"
+	DefaultDialTimeout         = 30 * time.Second
+	DefaultTLSHandshakeTimeout = 10 * time.Second
+	RequestIDHeader            = "X-Request-Id"
+	apiVersion                 = "/api/v2"
 )
 
 type pivnetErr struct {
@@ -32,24 +44,52 @@ type ErrPivnetOther struct {
 	ResponseCode int      `json:"response_code" yaml:"response_code"`
 	Message      string   `json:"message" yaml:"message"`
 	Errors       []string `json:"errors" yaml:"errors"`
+	RequestID    string   `json:"request_id,omitempty" yaml:"request_id,omitempty"`
 }
 
 func (e ErrPivnetOther) Error() string {
-	return fmt.Sprintf(
+	return withRequestID(fmt.Sprintf(
 		"%d - %s. Errors: %v",
 		e.ResponseCode,
 		e.Message,
 		strings.Join(e.Errors, ","),
-	)
+	), e.RequestID)
+}
+
+// maxBodyInErrorMessage is the number of bytes of an undecodable response
+// body quoted in an error message.
+const maxBodyInErrorMessage = 200
+
+func undecodableBodyMessage(body []byte, err error) string {
+	trimmed := strings.TrimSpace(string(body))
+	if trimmed == "" {
+		return fmt.Sprintf("empty response body: %s", err.Error())
+	}
+
+	if len(trimmed) > maxBodyInErrorMessage {
+		trimmed = trimmed[:maxBodyInErrorMessage] + "..."
+	}
+
+	return fmt.Sprintf("response body %q could not be decoded: %s", trimmed, err.Error())
+}
+
+// withRequestID appends the ID Pivnet assigned to the request, if known, to
+// an error message so that it can be quoted to Pivnet support.
+func withRequestID(message string, requestID string) string {
+	if requestID == "" {
+		return message
+	}
+	return fmt.Sprintf("%s (request ID: %s)", message, requestID)
 }
 
 type ErrUnauthorized struct {
 	ResponseCode int    `json:"response_code" yaml:"response_code"`
 	Message      string `json:"message" yaml:"message"`
+	RequestID    string `json:"request_id,omitempty" yaml:"request_id,omitempty"`
 }
 
 func (e ErrUnauthorized) Error() string {
-	return e.Message
+	return withRequestID(e.Message, e.RequestID)
 }
 
 func newErrUnauthorized(message string) ErrUnauthorized {
@@ -62,10 +102,18 @@ func newErrUnauthorized(message string) ErrUnauthorized {
 type ErrNotFound struct {
 	ResponseCode int    `json:"response_code" yaml:"response_code"`
 	Message      string `json:"message" yaml:"message"`
+	RequestID    string `json:"request_id,omitempty" yaml:"request_id,omitempty"`
 }
 
 func (e ErrNotFound) Error() string {
-	return e.Message
+	return withRequestID(e.Message, e.RequestID)
+}
+
+// Is reports whether target is an ErrNotFound, whatever its message, so
+// that errors.Is(err, ErrNotFound{}) matches any not found error.
+func (e ErrNotFound) Is(target error) bool {
+	_, ok := target.(ErrNotFound)
+	return ok
 }
 
 func newErrNotFound(message string) ErrNotFound {
@@ -75,13 +123,49 @@ func newErrNotFound(message string) ErrNotFound {
 	}
 }
 
+// ignoreNotFound returns nil if err is or wraps an ErrNotFound, and err
+// otherwise.
+func ignoreNotFound(err error) error {
+	if errors.Is(err, ErrNotFound{}) {
+		return nil
+	}
+	return err
+}
+
+// ErrTooManyRequests is returned when Pivnet rate limits a request and it is
+// not retried, or still rate limited after the last retry.
+type ErrTooManyRequests struct {
+	ResponseCode int    `json:"response_code" yaml:"response_code"`
+	Message      string `json:"message" yaml:"message"`
+
+	// RetryAfter is how long Pivnet asked the client to wait, or zero if
+	// the response did not say.
+	RetryAfter time.Duration `json:"retry_after" yaml:"retry_after"`
+
+	RequestID string `json:"request_id,omitempty" yaml:"request_id,omitempty"`
+}
+
+func (e ErrTooManyRequests) Error() string {
+	message := e.Message
+	if message == "" {
+		message = "Too many requests"
+	}
+
+	if e.RetryAfter > 0 {
+		message = fmt.Sprintf("%s - retry after %s", message, e.RetryAfter)
+	}
+
+	return withRequestID(message, e.RequestID)
+}
+
 type ErrUnavailableForLegalReasons struct {
 	ResponseCode int    `json:"response_code" yaml:"response_code"`
 	Message      string `json:"message" yaml:"message"`
+	RequestID    string `json:"request_id,omitempty" yaml:"request_id,omitempty"`
 }
 
 func (e ErrUnavailableForLegalReasons) Error() string {
-	return e.Message
+	return withRequestID(e.Message, e.RequestID)
 }
 
 func newErrUnavailableForLegalReasons() ErrUnavailableForLegalReasons {
@@ -95,8 +179,38 @@ type Client struct {
 	baseURL           string
 	token             string
 	userAgent         string
+	contentType       string
 	logger            logger.Logger
 	skipSSLValidation bool
+	disableRedirects  bool
+	requestEditors    []RequestEditor
+	requestSlots      chan struct{}
+	productSlugCache  *ttlCache
+	eulaCache         *ttlCache
+	clock             func() time.Time
+	retryPolicy       RetryPolicy
+	transport         http.RoundTripper
+	recorder          *Recorder
+	downloadCacheDir  string
+	signedURLs        *signedURLCache
+	maxResponseBytes  int64
+	endpointOverrides map[string]string
+	deprecations      *deprecationTracker
+	responseHooks     []ResponseHook
+	auditLogger       AuditLogger
+
+	releaseDescriptionLint MarkdownLintMode
+	versionPrefix          string
+
+	dialTimeout           time.Duration
+	tlsHandshakeTimeout   time.Duration
+	responseHeaderTimeout time.Duration
+
+	ctx            context.Context
+	requestTimeout time.Duration
+	lifecycle      *clientLifecycle
+
+	name string
 
 	Auth                *AuthService
 	EULA                *EULAsService
@@ -110,38 +224,358 @@ type Client struct {
 	ReleaseUpgradePaths *ReleaseUpgradePathsService
 }
 
+// RequestEditor may modify a request before it is sent. Returning an error
+// aborts the request.
+type RequestEditor func(req *http.Request) error
+
+// ResponseHook is called with every response received by MakeRequest, after
+// any retries and before the body is read. It gives access to headers such
+// as request IDs and rate limits that the typed methods discard, and must
+// not read or close the body.
+type ResponseHook func(resp *http.Response)
+
 type ClientConfig struct {
 	Host              string
 	Token             string
 	UserAgent         string
 	SkipSSLValidation bool
+
+	// Name, if set, identifies the client in a process with several. It is
+	// added to every line the client logs under ClientNameLogKey, and is
+	// available to response hooks through RequestClientName.
+	Name string
+
+	// ContentType is sent as the Content-Type of every request. The body is
+	// JSON whatever the value, so this is only for proxies or API versions
+	// that expect a specific JSON media type, e.g.
+	// "application/vnd.pivnet.v2+json". Defaults to DefaultContentType. See
+	// also Client.WithContentType.
+	ContentType string
+
+	// DisableRedirects returns redirect responses to the caller instead of
+	// following them. Downloads will not work while this is set.
+	DisableRedirects bool
+
+	// ProductSlugCacheTTL is how long Products.SlugToID remembers a
+	// resolved product ID. Zero uses DefaultProductSlugCacheTTL and a
+	// negative value disables caching.
+	ProductSlugCacheTTL time.Duration
+
+	// EULACacheTTL is how long EULA.List and EULA.Get remember their
+	// results. Zero uses DefaultEULACacheTTL and a negative value disables
+	// caching.
+	EULACacheTTL time.Duration
+
+	// RequestEditors are run in order on every request made by
+	// MakeRequest, after the client has set its own headers.
+	RequestEditors []RequestEditor
+
+	// ResponseHooks are run in order on every response received by
+	// MakeRequest. See also Client.WithResponseHook.
+	ResponseHooks []ResponseHook
+
+	// AuditLogger, if set, is called with a record of every mutating call
+	// made by the client, including those that fail. Requesting a download
+	// is not reported. See AuditLogger.
+	AuditLogger AuditLogger
+
+	// MaxConcurrentRequests limits the number of requests in flight at
+	// once across all services of the client. A request holds its slot
+	// until its response body is closed. Zero means unlimited.
+	MaxConcurrentRequests int
+
+	// RetryPolicy controls retries of idempotent requests. The zero value
+	// disables retries.
+	RetryPolicy RetryPolicy
+
+	// Transport, if set, is used to make requests instead of the default
+	// transport. SkipSSLValidation and the timeouts below do not apply to a
+	// custom transport.
+	Transport http.RoundTripper
+
+	// DialTimeout limits how long connecting to the server may take. Zero
+	// uses DefaultDialTimeout and a negative value means no limit.
+	DialTimeout time.Duration
+
+	// TLSHandshakeTimeout limits how long the TLS handshake may take. Zero
+	// uses DefaultTLSHandshakeTimeout and a negative value means no limit.
+	TLSHandshakeTimeout time.Duration
+
+	// ResponseHeaderTimeout limits how long to wait for the response
+	// headers once the request has been sent. It does not limit reading the
+	// body, so long downloads are unaffected. Zero means no limit.
+	ResponseHeaderTimeout time.Duration
+
+	// Context, if set, is the base context of every call made by the
+	// client. Cancelling it aborts all in-flight calls and makes later calls
+	// fail with ErrClientClosed, like Client.Close.
+	Context context.Context
+
+	// RequestTimeout limits how long each call may take, from sending the
+	// request, including any retries, to closing the response body. As it
+	// includes reading the body, it also limits downloads, unless
+	// DownloadOptions.TransferTimeout is set. Zero means no limit.
+	// Client.WithTimeout overrides it for individual calls.
+	RequestTimeout time.Duration
+
+	// Recorder, if set, records every request and response. See Recorder.
+	Recorder *Recorder
+
+	// Clock is used wherever the client needs the current time, such as
+	// when defaulting a release date. Defaults to time.Now. It is not used
+	// to time elapsed durations, such as DownloadResult.Duration.
+	Clock func() time.Time
+
+	// DownloadCacheDir, if set, is a directory in which downloaded product
+	// files are kept, named by their SHA256 checksum, and reused by later
+	// downloads of the same content. Files without a SHA256 are kept by
+	// product file ID and revalidated with If-Modified-Since. See
+	// ProductFilesService.DownloadTo.
+	DownloadCacheDir string
+
+	// CacheSignedURLs makes downloads reuse the signed URL of a product
+	// file until shortly before it expires, instead of asking Pivnet for a
+	// new one each time. A cached URL that the storage provider rejects
+	// with a 403 is replaced. This saves a request to Pivnet per download
+	// when the same files are downloaded repeatedly, e.g. by retries.
+	CacheSignedURLs bool
+
+	// MaxResponseBytes limits the size of response bodies, other than those
+	// of downloads, which are streamed. Reading past the limit fails with
+	// ErrResponseTooLarge. Zero uses DefaultMaxResponseBytes and a negative
+	// value disables the limit.
+	MaxResponseBytes int64
+
+	// EndpointOverrides is an escape hatch for when Pivnet moves an
+	// endpoint before the library is updated. Each key is a path prefix as
+	// requested by the library, relative to /api/v2, and is replaced by its
+	// value. Keys match whole path segments and the longest key wins, e.g.
+	// {"/releases/release_types": "/release_types"}.
+	EndpointOverrides map[string]string
+
+	// ReleaseDescriptionLint, if set, checks release descriptions with
+	// LintMarkdown before they are sent. See MarkdownLintMode.
+	ReleaseDescriptionLint MarkdownLintMode
+
+	// VersionPrefix, if set, is ignored at the start of versions, along
+	// with surrounding whitespace, when Releases.GetByVersion and
+	// Releases.Exists look up a release, e.g. "version " to match
+	// "version 0.2.3" with "0.2.3". Case is ignored. An exact match is
+	// always preferred.
+	VersionPrefix string
+}
+
+// Validate returns an error if Host is not an absolute http or https URL.
+// NewClient does not validate its config, so callers taking the host from
+// user input should call Validate first.
+func (c ClientConfig) Validate() error {
+	u, err := url.Parse(c.Host)
+	if err != nil {
+		return fmt.Errorf("Invalid host '%s': %s", c.Host, err.Error())
+	}
+
+	if u.Scheme != "http" && u.Scheme != "https" {
+		return fmt.Errorf("Invalid host '%s' - must start with http:// or https://", c.Host)
+	}
+
+	if u.Host == "" {
+		return fmt.Errorf("Invalid host '%s' - no hostname", c.Host)
+	}
+
+	if strings.Trim(u.Path, "/") != "" || u.RawQuery != "" || u.Fragment != "" {
+		return fmt.Errorf("Invalid host '%s' - must not include a path, query or fragment", c.Host)
+	}
+
+	return nil
 }
 
 func NewClient(config ClientConfig, logger logger.Logger) Client {
-	baseURL := fmt.Sprintf("%s%s", config.Host, apiVersion)
+	baseURL := fmt.Sprintf("%s%s", strings.TrimRight(config.Host, "/"), apiVersion)
 
 	client := Client{
 		baseURL:           baseURL,
 		token:             config.Token,
 		userAgent:         config.UserAgent,
+		contentType:       config.ContentType,
 		logger:            logger,
 		skipSSLValidation: config.SkipSSLValidation,
+		disableRedirects:  config.DisableRedirects,
+		requestEditors:    config.RequestEditors,
+		responseHooks:     config.ResponseHooks,
+		auditLogger:       config.AuditLogger,
+		clock:             config.Clock,
+		retryPolicy:       config.RetryPolicy,
+		transport:         config.Transport,
+		recorder:          config.Recorder,
+		downloadCacheDir:  config.DownloadCacheDir,
+		maxResponseBytes:  config.MaxResponseBytes,
+		endpointOverrides: config.EndpointOverrides,
+		deprecations:      &deprecationTracker{},
+
+		dialTimeout:           timeoutOrDefault(config.DialTimeout, DefaultDialTimeout),
+		tlsHandshakeTimeout:   timeoutOrDefault(config.TLSHandshakeTimeout, DefaultTLSHandshakeTimeout),
+		responseHeaderTimeout: timeoutOrDefault(config.ResponseHeaderTimeout, 0),
+		requestTimeout:        timeoutOrDefault(config.RequestTimeout, 0),
+
+		releaseDescriptionLint: config.ReleaseDescriptionLint,
+		versionPrefix:          config.VersionPrefix,
+	}
+
+	if config.CacheSignedURLs {
+		client.signedURLs = newSignedURLCache()
 	}
 
-	client.Auth = &AuthService{client: client}
-	client.EULA = &EULAsService{client: client}
-	client.ProductFiles = &ProductFilesService{client: client}
-	client.FileGroups = &FileGroupsService{client: client}
-	client.Releases = &ReleasesService{client: client, l: logger}
-	client.Products = &ProductsService{client: client, l: logger}
-	client.UserGroups = &UserGroupsService{client: client}
-	client.ReleaseDependencies = &ReleaseDependenciesService{client: client}
-	client.ReleaseTypes = &ReleaseTypesService{client: client}
-	client.ReleaseUpgradePaths = &ReleaseUpgradePathsService{client: client}
+	baseContext := config.Context
+	if baseContext == nil {
+		baseContext = context.Background()
+	}
+	client.lifecycle = &clientLifecycle{}
+	client.lifecycle.ctx, client.lifecycle.cancel = context.WithCancel(baseContext)
+
+	if config.Name != "" {
+		client.name = config.Name
+		client.logger = namedLogger{logger: logger, name: config.Name}
+	}
+
+	if client.maxResponseBytes == 0 {
+		client.maxResponseBytes = DefaultMaxResponseBytes
+	}
+
+	if client.clock == nil {
+		client.clock = time.Now
+	}
+
+	if config.MaxConcurrentRequests > 0 {
+		client.requestSlots = make(chan struct{}, config.MaxConcurrentRequests)
+	}
+
+	productSlugCacheTTL := config.ProductSlugCacheTTL
+	if productSlugCacheTTL == 0 {
+		productSlugCacheTTL = DefaultProductSlugCacheTTL
+	}
+	client.productSlugCache = newTTLCache(productSlugCacheTTL, client.clock)
+
+	eulaCacheTTL := config.EULACacheTTL
+	if eulaCacheTTL == 0 {
+		eulaCacheTTL = DefaultEULACacheTTL
+	}
+	client.eulaCache = newTTLCache(eulaCacheTTL, client.clock)
+
+	client.initServices()
 
 	return client
 }
 
+// timeoutOrDefault returns defaultTimeout for zero and no limit, which the
+// transport represents as zero, for negative values.
+func timeoutOrDefault(timeout time.Duration, defaultTimeout time.Duration) time.Duration {
+	switch {
+	case timeout == 0:
+		return defaultTimeout
+	case timeout < 0:
+		return 0
+	default:
+		return timeout
+	}
+}
+
+// WithUserAgentSuffix returns a copy of the client whose requests append
+// suffix, e.g. "(op=download)", to the configured User-Agent. The copy
+// shares caches and request limits with the original client.
+func (c Client) WithUserAgentSuffix(suffix string) Client {
+	if suffix != "" {
+		c.userAgent = strings.TrimSpace(c.userAgent + " " + suffix)
+	}
+
+	c.initServices()
+
+	return c
+}
+
+// WithContentType returns a copy of the client whose requests are sent with
+// the Content-Type contentType, for calls to endpoints that require a
+// different media type from the rest. An empty contentType leaves the
+// configured Content-Type unchanged. The copy shares caches and request
+// limits with the original client.
+func (c Client) WithContentType(contentType string) Client {
+	if contentType != "" {
+		c.contentType = contentType
+	}
+
+	c.initServices()
+
+	return c
+}
+
+// WithTimeout returns a copy of the client whose calls time out after
+// timeout instead of ClientConfig.RequestTimeout, e.g.
+//
+//	releases, err := client.WithTimeout(5 * time.Minute).Releases.List(productSlug)
+//
+// Zero or a negative timeout removes the limit. If the client also has a
+// context with a deadline, see WithContext, the sooner of the two applies.
+// The copy shares caches and request limits with the original client.
+func (c Client) WithTimeout(timeout time.Duration) Client {
+	c.requestTimeout = 0
+	if timeout > 0 {
+		c.requestTimeout = timeout
+	}
+
+	c.initServices()
+
+	return c
+}
+
+// WithContext returns a copy of the client whose calls are made with ctx, so
+// that they are aborted when ctx is cancelled or its deadline passes. The
+// request timeout still applies, whichever is sooner, and closing the
+// client still aborts the calls. The copy shares caches and request limits
+// with the original client.
+func (c Client) WithContext(ctx context.Context) Client {
+	c.ctx = ctx
+
+	c.initServices()
+
+	return c
+}
+
+// WithResponseHook returns a copy of the client that also runs hook on every
+// response. Deriving a client for a single call, e.g.
+//
+//	var requestID string
+//	release, err := client.WithResponseHook(func(resp *http.Response) {
+//		requestID = resp.Header.Get("X-Request-Id")
+//	}).Releases.Get(productSlug, releaseID)
+//
+// gives access to the response headers of that call while the typed methods
+// keep their signatures.
+func (c Client) WithResponseHook(hook ResponseHook) Client {
+	c.responseHooks = append(append([]ResponseHook{}, c.responseHooks...), hook)
+
+	c.initServices()
+
+	return c
+}
+
+func (c *Client) initServices() {
+	client := *c
+
+	c.Auth = &AuthService{client: client}
+	c.EULA = &EULAsService{client: client, cache: c.eulaCache}
+	c.ProductFiles = &ProductFilesService{client: client}
+	c.FileGroups = &FileGroupsService{client: client}
+	c.Releases = &ReleasesService{client: client, l: c.logger}
+	c.Products = &ProductsService{
+		client:    client,
+		l:         c.logger,
+		slugCache: c.productSlugCache,
+	}
+	c.UserGroups = &UserGroupsService{client: client}
+	c.ReleaseDependencies = &ReleaseDependenciesService{client: client}
+	c.ReleaseTypes = &ReleaseTypesService{client: client}
+	c.ReleaseUpgradePaths = &ReleaseUpgradePathsService{client: client}
+}
+
 func (c Client) CreateRequest(
 	requestType string,
 	endpoint string,
@@ -152,7 +586,7 @@ func (c Client) CreateRequest(
 		return nil, err
 	}
 
-	endpoint = c.stripHostPrefix(endpoint)
+	endpoint = c.overrideEndpoint(c.stripHostPrefix(endpoint))
 
 	u.Path = u.Path + endpoint
 
@@ -161,7 +595,12 @@ func (c Client) CreateRequest(
 		return nil, err
 	}
 
-	req.Header.Add("Content-Type", "application/json")
+	contentType := c.contentType
+	if contentType == "" {
+		contentType = DefaultContentType
+	}
+
+	req.Header.Add("Content-Type", contentType)
 	req.Header.Add("Authorization", fmt.Sprintf("Token %s", c.token))
 	req.Header.Add("User-Agent", c.userAgent)
 
@@ -173,35 +612,187 @@ func (c Client) MakeRequest(
 	endpoint string,
 	expectedStatusCode int,
 	body io.Reader,
+) (*http.Response, error) {
+	return c.makeRequest(requestType, endpoint, expectedStatusCode, body, c.maxResponseBytes)
+}
+
+// MakeRequestExpecting behaves like MakeRequest, treating any of
+// expectedStatusCodes as success. If none are given, any 2XX status code is
+// a success. Any other status code is returned as the same typed errors as
+// MakeRequest, such as ErrNotFound or ErrPivnetOther.
+func (c Client) MakeRequestExpecting(
+	requestType string,
+	endpoint string,
+	body io.Reader,
+	expectedStatusCodes ...int,
+) (*http.Response, error) {
+	expected := func(statusCode int) bool {
+		return statusCode >= 200 && statusCode < 300
+	}
+
+	if len(expectedStatusCodes) > 0 {
+		expected = func(statusCode int) bool {
+			for _, code := range expectedStatusCodes {
+				if statusCode == code {
+					return true
+				}
+			}
+			return false
+		}
+	}
+
+	return c.makeRequestExpecting(requestType, endpoint, expected, body, c.maxResponseBytes)
+}
+
+// makeRequest behaves like MakeRequest, limiting the response body to
+// maxResponseBytes unless it is zero or negative.
+func (c Client) makeRequest(
+	requestType string,
+	endpoint string,
+	expectedStatusCode int,
+	body io.Reader,
+	maxResponseBytes int64,
+) (*http.Response, error) {
+	var expected func(int) bool
+	if expectedStatusCode > 0 {
+		expected = func(statusCode int) bool {
+			return statusCode == expectedStatusCode
+		}
+	}
+
+	return c.makeRequestExpecting(requestType, endpoint, expected, body, maxResponseBytes)
+}
+
+// makeRequestExpecting makes the request, returning an error for a response
+// whose status code expected rejects. A nil expected accepts any status code.
+func (c Client) makeRequestExpecting(
+	requestType string,
+	endpoint string,
+	expected func(statusCode int) bool,
+	body io.Reader,
+	maxResponseBytes int64,
+) (*http.Response, error) {
+	if c.auditLogger != nil && isMutatingMethod(requestType) {
+		return c.makeAuditedRequest(requestType, endpoint, expected, body, maxResponseBytes)
+	}
+
+	return c.makeUnauditedRequest(requestType, endpoint, expected, body, maxResponseBytes)
+}
+
+func (c Client) makeUnauditedRequest(
+	requestType string,
+	endpoint string,
+	expected func(statusCode int) bool,
+	body io.Reader,
+	maxResponseBytes int64,
 ) (*http.Response, error) {
 	req, err := c.CreateRequest(requestType, endpoint, body)
 	if err != nil {
 		return nil, err
 	}
 
+	ctx, cancel, err := c.requestContext()
+	if err != nil {
+		return nil, err
+	}
+
+	resp, err := c.sendRequest(req.WithContext(withClientName(ctx, c.name)), expected, maxResponseBytes)
+	if err != nil {
+		cancel()
+		return nil, err
+	}
+
+	resp.Body = &cancelingBody{ReadCloser: resp.Body, cancel: cancel}
+
+	return resp, nil
+}
+
+// sendRequest sends the request, retrying it according to the retry policy,
+// and checks the status code of the response.
+func (c Client) sendRequest(
+	req *http.Request,
+	expected func(statusCode int) bool,
+	maxResponseBytes int64,
+) (*http.Response, error) {
+	var err error
+	for _, editor := range c.requestEditors {
+		err = editor(req)
+		if err != nil {
+			return nil, err
+		}
+	}
+
 	reqBytes, err := httputil.DumpRequestOut(req, true)
 	if err != nil {
 		return nil, err
 	}
 
 	c.logger.Debug("Making request", logger.Data{"request": string(reqBytes)})
-	var httpClient *http.Client
+	httpClient := c.httpClient()
+
+	var resp *http.Response
+	for attempt := 1; ; attempt++ {
+		resp, err = c.do(httpClient, req)
+
+		reason, retry := c.retryPolicy.retryReason(req, expected, resp, err)
+		if !retry || attempt >= c.retryPolicy.MaxAttempts {
+			break
+		}
+
+		if retryAfter, tooLong := c.retryPolicy.retryAfterTooLong(resp); tooLong {
+			c.logger.Debug("Not retrying request - Retry-After exceeds MaxRetryAfter", logger.Data{
+				"attempt":     attempt,
+				"reason":      reason,
+				"retry_after": retryAfter.String(),
+				"method":      req.Method,
+				"url":         req.URL.String(),
+			})
+			break
+		}
+
+		delay := c.retryPolicy.delay(attempt, resp)
+		if resp != nil {
+			io.Copy(ioutil.Discard, resp.Body)
+			resp.Body.Close()
+		}
+
+		c.logger.Debug("Retrying request", logger.Data{
+			"attempt": attempt,
+			"reason":  reason,
+			"delay":   delay.String(),
+			"method":  req.Method,
+			"url":     req.URL.String(),
+		})
 
-	httpClient = &http.Client{
-		Transport: &http.Transport{
-			TLSClientConfig: &tls.Config{InsecureSkipVerify: c.skipSSLValidation},
-		},
+		err = sleepContext(req.Context(), delay)
+		if err != nil {
+			return nil, err
+		}
 	}
 
-	resp, err := httpClient.Do(req)
 	if err != nil {
 		return nil, err
 	}
 
-	c.logger.Debug("Response status code", logger.Data{"status code": resp.StatusCode})
+	if maxResponseBytes > 0 {
+		resp.Body = &limitedBody{ReadCloser: resp.Body, limit: maxResponseBytes}
+	}
+
+	c.observeDeprecation(req, resp)
+
+	for _, hook := range c.responseHooks {
+		hook(resp)
+	}
+
+	c.logger.Debug("Response status code", logger.Data{
+		"status code": resp.StatusCode,
+		"request id":  resp.Header.Get(RequestIDHeader),
+	})
 	c.logger.Debug("Response headers", logger.Data{"headers": resp.Header})
 
-	if expectedStatusCode > 0 && resp.StatusCode != expectedStatusCode {
+	if expected != nil && !expected(resp.StatusCode) {
+		defer resp.Body.Close()
+
 		var pErr pivnetErr
 
 		b, err := ioutil.ReadAll(resp.Body)
@@ -209,36 +800,57 @@ func (c Client) MakeRequest(
 			return nil, err
 		}
 
+		requestID := resp.Header.Get(RequestIDHeader)
+
 		// We have to handle 500 differently because it has a different structure
 		if resp.StatusCode == http.StatusInternalServerError {
 			var internalServerError pivnetInternalServerErr
 			err = json.Unmarshal(b, &internalServerError)
-			if err != nil {
-				return nil, err
-			}
 
 			pErr = pivnetErr{
 				Message: internalServerError.Error,
 			}
 		} else {
 			err = json.Unmarshal(b, &pErr)
-			if err != nil {
-				return nil, err
+		}
+
+		// A body that is not a Pivnet error, such as an empty body or an
+		// HTML page from a proxy, is reported with the status code.
+		if err != nil {
+			return nil, ErrPivnetOther{
+				ResponseCode: resp.StatusCode,
+				Message:      undecodableBodyMessage(b, err),
+				RequestID:    requestID,
 			}
 		}
 
 		switch resp.StatusCode {
 		case http.StatusUnauthorized:
-			return nil, newErrUnauthorized(pErr.Message)
+			err := newErrUnauthorized(pErr.Message)
+			err.RequestID = requestID
+			return nil, err
 		case http.StatusNotFound:
-			return nil, newErrNotFound(pErr.Message)
+			err := newErrNotFound(pErr.Message)
+			err.RequestID = requestID
+			return nil, err
 		case http.StatusUnavailableForLegalReasons:
-			return nil, newErrUnavailableForLegalReasons()
+			err := newErrUnavailableForLegalReasons()
+			err.RequestID = requestID
+			return nil, err
+		case http.StatusTooManyRequests:
+			retryAfter, _ := parseRetryAfter(resp)
+			return nil, ErrTooManyRequests{
+				ResponseCode: resp.StatusCode,
+				Message:      pErr.Message,
+				RetryAfter:   retryAfter,
+				RequestID:    requestID,
+			}
 		default:
 			return nil, ErrPivnetOther{
 				ResponseCode: resp.StatusCode,
 				Message:      pErr.Message,
 				Errors:       pErr.Errors,
+				RequestID:    requestID,
 			}
 		}
 	}
@@ -246,6 +858,215 @@ func (c Client) MakeRequest(
 	return resp, nil
 }
 
+func (c Client) httpClient() *http.Client {
+	transport := c.transport
+	if transport == nil {
+		dialer := &net.Dialer{
+			Timeout:   c.dialTimeout,
+			KeepAlive: 30 * time.Second,
+		}
+
+		transport = &http.Transport{
+			TLSClientConfig:       &tls.Config{InsecureSkipVerify: c.skipSSLValidation},
+			DialContext:           dialer.DialContext,
+			TLSHandshakeTimeout:   c.tlsHandshakeTimeout,
+			ResponseHeaderTimeout: c.responseHeaderTimeout,
+		}
+	}
+
+	httpClient := &http.Client{
+		Transport: transport,
+	}
+
+	if c.disableRedirects {
+		httpClient.CheckRedirect = func(*http.Request, []*http.Request) error {
+			return http.ErrUseLastResponse
+		}
+	}
+
+	return httpClient
+}
+
+func (c Client) do(httpClient *http.Client, req *http.Request) (*http.Response, error) {
+	release, err := c.acquireRequestSlot(req)
+	if err != nil {
+		return nil, err
+	}
+
+	resp, err := httpClient.Do(req)
+	if err != nil {
+		release()
+		return nil, err
+	}
+
+	if c.recorder != nil {
+		err = c.recorder.record(req, resp)
+		if err != nil {
+			release()
+			return nil, err
+		}
+	}
+
+	resp.Body = &releasingBody{ReadCloser: resp.Body, release: release}
+
+	return resp, nil
+}
+
+func (c Client) acquireRequestSlot(req *http.Request) (func(), error) {
+	if c.requestSlots == nil {
+		return func() {}, nil
+	}
+
+	select {
+	case c.requestSlots <- struct{}{}:
+	case <-req.Context().Done():
+		return nil, req.Context().Err()
+	}
+
+	var once sync.Once
+	return func() {
+		once.Do(func() { <-c.requestSlots })
+	}, nil
+}
+
+// requestContext returns the context of a call made by the client, limited
+// by the request timeout if there is one and cancelled when the client is
+// closed. The context must be cancelled once the response body has been
+// closed. It returns ErrClientClosed if the client has been closed.
+func (c Client) requestContext() (context.Context, context.CancelFunc, error) {
+	base := context.Background()
+	if c.lifecycle != nil {
+		base = c.lifecycle.ctx
+	}
+
+	if base.Err() != nil {
+		return nil, nil, ErrClientClosed{}
+	}
+
+	ctx := base
+	if c.ctx != nil {
+		ctx = c.ctx
+	}
+
+	var cancel context.CancelFunc
+	if c.requestTimeout > 0 {
+		ctx, cancel = context.WithTimeout(ctx, c.requestTimeout)
+	} else {
+		ctx, cancel = context.WithCancel(ctx)
+	}
+
+	if c.ctx == nil {
+		return ctx, cancel, nil
+	}
+
+	stop := context.AfterFunc(base, cancel)
+	return ctx, func() {
+		stop()
+		cancel()
+	}, nil
+}
+
+// clientLifecycle is shared by a client and its copies so that closing any
+// of them cancels the calls of all of them.
+type clientLifecycle struct {
+	ctx    context.Context
+	cancel context.CancelFunc
+}
+
+// ErrClientClosed is returned by calls made after the client was closed or
+// its ClientConfig.Context was cancelled.
+type ErrClientClosed struct{}
+
+func (e ErrClientClosed) Error() string {
+	return "client is closed"
+}
+
+// Close cancels all in-flight calls of the client and of every copy derived
+// from it, such as by WithTimeout, and makes later calls fail with
+// ErrClientClosed. It is intended for shutting down a service that embeds
+// the client, and is safe to call more than once.
+func (c Client) Close() error {
+	if c.lifecycle != nil {
+		c.lifecycle.cancel()
+	}
+	return nil
+}
+
+// cancelingBody cancels the context of its request once it is closed.
+type cancelingBody struct {
+	io.ReadCloser
+	cancel context.CancelFunc
+}
+
+func (b *cancelingBody) Close() error {
+	err := b.ReadCloser.Close()
+	b.cancel()
+	return err
+}
+
+type releasingBody struct {
+	io.ReadCloser
+	release func()
+}
+
+func (b *releasingBody) Close() error {
+	err := b.ReadCloser.Close()
+	b.release()
+	return err
+}
+
+type ErrResponseTooLarge struct {
+	Limit int64
+}
+
+func (e ErrResponseTooLarge) Error() string {
+	return fmt.Sprintf("response too large - exceeded the limit of %d bytes", e.Limit)
+}
+
+// limitedBody fails reads with ErrResponseTooLarge once more than limit
+// bytes have been read.
+type limitedBody struct {
+	io.ReadCloser
+	limit int64
+	read  int64
+}
+
+func (b *limitedBody) Read(p []byte) (int, error) {
+	n, err := b.ReadCloser.Read(p)
+	b.read += int64(n)
+
+	if b.read > b.limit {
+		n -= int(b.read - b.limit)
+		if n < 0 {
+			n = 0
+		}
+		b.read = b.limit
+		return n, ErrResponseTooLarge{Limit: b.limit}
+	}
+
+	return n, err
+}
+
+func (c Client) overrideEndpoint(endpoint string) string {
+	var match string
+	for prefix := range c.endpointOverrides {
+		if len(prefix) <= len(match) || !strings.HasPrefix(endpoint, prefix) {
+			continue
+		}
+
+		rest := endpoint[len(prefix):]
+		if rest == "" || rest[0] == '/' || strings.HasSuffix(prefix, "/") {
+			match = prefix
+		}
+	}
+
+	if match == "" {
+		return endpoint
+	}
+
+	return c.endpointOverrides[match] + endpoint[len(match):]
+}
+
 func (c Client) stripHostPrefix(downloadLink string) string {
 	if strings.HasPrefix(downloadLink, apiVersion) {
 		return downloadLink
@@ -1,8 +1,13 @@
 package pivnet_test
 
 import (
+	"context"
+	"errors"
 	"fmt"
+	"io/ioutil"
+	"net"
 	"net/http"
+	"time"
 
 	. "github.com/onsi/ginkgo"
 	. "github.com/onsi/gomega"
@@ -123,6 +128,28 @@ var _ = Describe("PivnetClient", func() {
 		Expect(err).NotTo(HaveOccurred())
 	})
 
+	Context("when the host has a trailing slash", func() {
+		It("does not double the slash before the API prefix", func() {
+			server.AppendHandlers(
+				ghttp.CombineHandlers(
+					ghttp.VerifyRequest("GET", fmt.Sprintf("%s/foo", apiPrefix)),
+					ghttp.RespondWithJSONEncoded(http.StatusOK, releases),
+				),
+			)
+
+			newClientConfig.Host = server.URL() + "/"
+			client = pivnet.NewClient(newClientConfig, fakeLogger)
+
+			_, err := client.MakeRequest(
+				"GET",
+				"/foo",
+				http.StatusOK,
+				nil,
+			)
+			Expect(err).NotTo(HaveOccurred())
+		})
+	})
+
 	Context("when parsing the url fails with error", func() {
 		It("forwards the error", func() {
 			newClientConfig.Host = "%%%"
@@ -262,6 +289,61 @@ var _ = Describe("PivnetClient", func() {
 		})
 	})
 
+	Context("when the error response has a request ID", func() {
+		It("includes it in the error", func() {
+			server.AppendHandlers(
+				ghttp.RespondWith(http.StatusNotFound, `{"message":"foo message"}`, http.Header{"X-Request-Id": []string{"some-request-id"}}),
+			)
+
+			_, err := client.MakeRequest("GET", "/foo", http.StatusOK, nil)
+			Expect(err).To(Equal(pivnet.ErrNotFound{
+				ResponseCode: http.StatusNotFound,
+				Message:      "foo message",
+				RequestID:    "some-request-id",
+			}))
+			Expect(err.Error()).To(Equal("foo message (request ID: some-request-id)"))
+		})
+
+		It("includes it in errors for other status codes", func() {
+			server.AppendHandlers(
+				ghttp.RespondWith(http.StatusTeapot, `{"message":"foo message"}`, http.Header{"X-Request-Id": []string{"some-request-id"}}),
+			)
+
+			_, err := client.MakeRequest("GET", "/foo", http.StatusOK, nil)
+			Expect(err.(pivnet.ErrPivnetOther).RequestID).To(Equal("some-request-id"))
+			Expect(err.Error()).To(HaveSuffix("(request ID: some-request-id)"))
+		})
+
+		It("keeps it when a method rewords the error", func() {
+			server.AppendHandlers(
+				ghttp.RespondWith(http.StatusNotFound, `{"message":"foo message"}`, http.Header{"X-Request-Id": []string{"some-request-id"}}),
+			)
+
+			_, err := client.Products.SlugToID("banana")
+			Expect(err).To(MatchError("Product 'banana' not found (request ID: some-request-id)"))
+		})
+
+		It("logs it with the response status code", func() {
+			server.AppendHandlers(
+				ghttp.RespondWith(http.StatusOK, `{}`, http.Header{"X-Request-Id": []string{"some-request-id"}}),
+			)
+
+			_, err := client.MakeRequest("GET", "/foo", http.StatusOK, nil)
+			Expect(err).NotTo(HaveOccurred())
+
+			fake := fakeLogger.(*loggerfakes.FakeLogger)
+			found := false
+			for i := 0; i < fake.DebugCallCount(); i++ {
+				action, data := fake.DebugArgsForCall(i)
+				if action == "Response status code" {
+					Expect(data[0]["request id"]).To(Equal("some-request-id"))
+					found = true
+				}
+			}
+			Expect(found).To(BeTrue())
+		})
+	})
+
 	Context("when Pivnet returns a 500", func() {
 		var (
 			body []byte
@@ -402,6 +484,646 @@ var _ = Describe("PivnetClient", func() {
 
 	})
 
+	Describe("MakeRequestExpecting", func() {
+		respondWith := func(statusCode int) {
+			server.AppendHandlers(
+				ghttp.CombineHandlers(
+					ghttp.VerifyRequest("GET", fmt.Sprintf("%s/foo", apiPrefix)),
+					ghttp.RespondWith(statusCode, `{"message":"foo message"}`),
+				),
+			)
+		}
+
+		It("accepts any of the expected status codes", func() {
+			respondWith(http.StatusAccepted)
+
+			resp, err := client.MakeRequestExpecting("GET", "/foo", nil, http.StatusOK, http.StatusAccepted)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(resp.StatusCode).To(Equal(http.StatusAccepted))
+		})
+
+		It("returns a typed error with the actual status code otherwise", func() {
+			respondWith(http.StatusConflict)
+
+			_, err := client.MakeRequestExpecting("GET", "/foo", nil, http.StatusOK, http.StatusAccepted)
+			Expect(err).To(BeAssignableToTypeOf(pivnet.ErrPivnetOther{}))
+			Expect(err.(pivnet.ErrPivnetOther).ResponseCode).To(Equal(http.StatusConflict))
+		})
+
+		Context("when the unexpected response has a body that is not a Pivnet error", func() {
+			It("returns a typed error with the actual status code", func() {
+				server.AppendHandlers(
+					ghttp.RespondWith(http.StatusNoContent, nil),
+					ghttp.RespondWith(http.StatusBadGateway, "<html>Bad Gateway</html>"),
+				)
+
+				_, err := client.MakeRequestExpecting("GET", "/foo", nil, http.StatusOK)
+				Expect(err).To(BeAssignableToTypeOf(pivnet.ErrPivnetOther{}))
+				Expect(err.(pivnet.ErrPivnetOther).ResponseCode).To(Equal(http.StatusNoContent))
+				Expect(err.(pivnet.ErrPivnetOther).Message).To(Equal("empty response body: unexpected end of JSON input"))
+
+				_, err = client.MakeRequestExpecting("GET", "/foo", nil, http.StatusOK)
+				Expect(err).To(BeAssignableToTypeOf(pivnet.ErrPivnetOther{}))
+				Expect(err.(pivnet.ErrPivnetOther).ResponseCode).To(Equal(http.StatusBadGateway))
+				Expect(err.Error()).To(HavePrefix(`502 - response body "<html>Bad Gateway</html>" could not be decoded`))
+			})
+		})
+
+		Context("when no status codes are given", func() {
+			It("accepts any 2XX status code", func() {
+				respondWith(http.StatusNoContent)
+
+				resp, err := client.MakeRequestExpecting("GET", "/foo", nil)
+				Expect(err).NotTo(HaveOccurred())
+				Expect(resp.StatusCode).To(Equal(http.StatusNoContent))
+			})
+
+			It("returns a typed error for other status codes", func() {
+				respondWith(http.StatusNotFound)
+
+				_, err := client.MakeRequestExpecting("GET", "/foo", nil)
+				Expect(err).To(BeAssignableToTypeOf(pivnet.ErrNotFound{}))
+			})
+		})
+	})
+
+	Context("when RequestEditors are provided", func() {
+		var (
+			calls []string
+		)
+
+		BeforeEach(func() {
+			calls = nil
+
+			newClientConfig.RequestEditors = []pivnet.RequestEditor{
+				func(req *http.Request) error {
+					calls = append(calls, "first")
+					req.Header.Set("User-Agent", "overridden")
+					return nil
+				},
+				func(req *http.Request) error {
+					calls = append(calls, "second")
+					req.Header.Set("X-Signature", "signed")
+					return nil
+				},
+			}
+			client = pivnet.NewClient(newClientConfig, fakeLogger)
+		})
+
+		It("runs them in order after the client sets its headers", func() {
+			server.AppendHandlers(
+				ghttp.CombineHandlers(
+					ghttp.VerifyRequest(
+						"GET",
+						fmt.Sprintf("%s/foo", apiPrefix),
+					),
+					ghttp.VerifyHeaderKV("User-Agent", "overridden"),
+					ghttp.VerifyHeaderKV("X-Signature", "signed"),
+					ghttp.VerifyHeaderKV("Authorization", fmt.Sprintf("Token %s", token)),
+					ghttp.RespondWithJSONEncoded(http.StatusOK, releases),
+				),
+			)
+
+			_, err := client.MakeRequest(
+				"GET",
+				"/foo",
+				http.StatusOK,
+				nil,
+			)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(calls).To(Equal([]string{"first", "second"}))
+		})
+
+		Context("when an editor returns an error", func() {
+			BeforeEach(func() {
+				newClientConfig.RequestEditors = append(
+					[]pivnet.RequestEditor{
+						func(req *http.Request) error {
+							return errors.New("editor failed")
+						},
+					},
+					newClientConfig.RequestEditors...,
+				)
+				client = pivnet.NewClient(newClientConfig, fakeLogger)
+			})
+
+			It("aborts the request", func() {
+				_, err := client.MakeRequest(
+					"GET",
+					"/foo",
+					http.StatusOK,
+					nil,
+				)
+				Expect(err).To(MatchError("editor failed"))
+
+				Expect(calls).To(BeEmpty())
+				Expect(server.ReceivedRequests()).To(BeEmpty())
+			})
+		})
+	})
+
+	Context("when ContentType is set", func() {
+		BeforeEach(func() {
+			newClientConfig.ContentType = "application/vnd.pivnet.v2+json"
+			client = pivnet.NewClient(newClientConfig, fakeLogger)
+		})
+
+		It("sends it as the Content-Type with a JSON body", func() {
+			server.AppendHandlers(
+				ghttp.CombineHandlers(
+					ghttp.VerifyRequest("PATCH", fmt.Sprintf("%s/products/banana/releases/3", apiPrefix)),
+					ghttp.VerifyHeaderKV("Content-Type", "application/vnd.pivnet.v2+json"),
+					func(w http.ResponseWriter, req *http.Request) {
+						body, err := ioutil.ReadAll(req.Body)
+						Expect(err).NotTo(HaveOccurred())
+						Expect(body).To(MatchJSON(`{"release":{"id":3,"version":"1.2.3","oss_compliant":"confirm"}}`))
+					},
+					ghttp.RespondWith(http.StatusOK, `{"release":{"id":3}}`),
+				),
+			)
+
+			_, err := client.Releases.Update("banana", pivnet.Release{ID: 3, Version: "1.2.3"})
+			Expect(err).NotTo(HaveOccurred())
+		})
+	})
+
+	Describe("WithContentType", func() {
+		It("overrides the Content-Type for requests of the derived client only", func() {
+			server.AppendHandlers(
+				ghttp.CombineHandlers(
+					ghttp.VerifyRequest("GET", fmt.Sprintf("%s/products/%s", apiPrefix, "some-product")),
+					ghttp.VerifyHeaderKV("Content-Type", "application/vnd.pivnet.v2+json"),
+					ghttp.RespondWith(http.StatusOK, `{"id":1}`),
+				),
+				ghttp.CombineHandlers(
+					ghttp.VerifyRequest("GET", fmt.Sprintf("%s/products/%s", apiPrefix, "some-product")),
+					ghttp.VerifyHeaderKV("Content-Type", "application/json"),
+					ghttp.RespondWith(http.StatusOK, `{"id":1}`),
+				),
+			)
+
+			_, err := client.WithContentType("application/vnd.pivnet.v2+json").Products.Get("some-product")
+			Expect(err).NotTo(HaveOccurred())
+
+			_, err = client.Products.Get("some-product")
+			Expect(err).NotTo(HaveOccurred())
+		})
+
+		It("leaves the Content-Type unchanged when given an empty string", func() {
+			server.AppendHandlers(
+				ghttp.CombineHandlers(
+					ghttp.VerifyHeaderKV("Content-Type", "application/json"),
+					ghttp.RespondWith(http.StatusOK, `{"id":1}`),
+				),
+			)
+
+			_, err := client.WithContentType("").Products.Get("some-product")
+			Expect(err).NotTo(HaveOccurred())
+		})
+	})
+
+	Describe("WithUserAgentSuffix", func() {
+		It("appends the suffix to the user agent for requests of all services", func() {
+			server.AppendHandlers(
+				ghttp.CombineHandlers(
+					ghttp.VerifyRequest("GET", fmt.Sprintf("%s/products/%s", apiPrefix, "some-product")),
+					ghttp.VerifyHeaderKV("User-Agent", userAgent+" (op=download)"),
+					ghttp.RespondWith(http.StatusOK, `{"id":1}`),
+				),
+				ghttp.CombineHandlers(
+					ghttp.VerifyRequest("GET", fmt.Sprintf("%s/products/%s", apiPrefix, "some-product")),
+					ghttp.VerifyHeaderKV("User-Agent", userAgent),
+					ghttp.RespondWith(http.StatusOK, `{"id":1}`),
+				),
+			)
+
+			_, err := client.WithUserAgentSuffix("(op=download)").Products.Get("some-product")
+			Expect(err).NotTo(HaveOccurred())
+
+			_, err = client.Products.Get("some-product")
+			Expect(err).NotTo(HaveOccurred())
+		})
+	})
+
+	Describe("WithResponseHook", func() {
+		It("runs the hook on responses of the derived client only", func() {
+			server.AppendHandlers(
+				ghttp.RespondWith(http.StatusOK, `{"id": 3}`, http.Header{"X-Request-Id": []string{"some-request-id"}}),
+				ghttp.RespondWith(http.StatusOK, `{"id": 3}`, http.Header{"X-Request-Id": []string{"other-request-id"}}),
+			)
+
+			var requestIDs []string
+			derived := client.WithResponseHook(func(resp *http.Response) {
+				requestIDs = append(requestIDs, resp.Header.Get("X-Request-Id"))
+			})
+
+			release, err := derived.Releases.Get("banana", 3)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(release.ID).To(Equal(3))
+
+			_, err = client.Releases.Get("banana", 3)
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(requestIDs).To(Equal([]string{"some-request-id"}))
+		})
+
+		Context("when the response has an unexpected status code", func() {
+			It("runs the hook before returning the error", func() {
+				server.AppendHandlers(
+					ghttp.RespondWith(http.StatusTeapot, `{"message":"foo message"}`, http.Header{"X-Request-Id": []string{"some-request-id"}}),
+				)
+
+				var requestID string
+				_, err := client.WithResponseHook(func(resp *http.Response) {
+					requestID = resp.Header.Get("X-Request-Id")
+				}).Releases.Get("banana", 3)
+				Expect(err).To(HaveOccurred())
+
+				Expect(requestID).To(Equal("some-request-id"))
+			})
+		})
+	})
+
+	Context("when RequestTimeout is set", func() {
+		BeforeEach(func() {
+			newClientConfig.RequestTimeout = 20 * time.Millisecond
+			client = pivnet.NewClient(newClientConfig, fakeLogger)
+		})
+
+		It("fails calls that take longer", func() {
+			server.AppendHandlers(func(w http.ResponseWriter, req *http.Request) {
+				time.Sleep(200 * time.Millisecond)
+			})
+
+			_, err := client.MakeRequest("GET", "/foo", http.StatusOK, nil)
+			Expect(errors.Is(err, context.DeadlineExceeded)).To(BeTrue())
+		})
+
+		It("also limits reading the response body", func() {
+			server.AppendHandlers(func(w http.ResponseWriter, req *http.Request) {
+				w.WriteHeader(http.StatusOK)
+				w.(http.Flusher).Flush()
+				time.Sleep(200 * time.Millisecond)
+			})
+
+			resp, err := client.MakeRequest("GET", "/foo", http.StatusOK, nil)
+			Expect(err).NotTo(HaveOccurred())
+			defer resp.Body.Close()
+
+			_, err = ioutil.ReadAll(resp.Body)
+			Expect(errors.Is(err, context.DeadlineExceeded)).To(BeTrue())
+		})
+
+		Describe("WithTimeout", func() {
+			It("overrides the timeout for calls made through the copy", func() {
+				server.AppendHandlers(
+					func(w http.ResponseWriter, req *http.Request) {
+						time.Sleep(100 * time.Millisecond)
+						w.Write([]byte(`{"id": 3}`))
+					},
+					func(w http.ResponseWriter, req *http.Request) {
+						time.Sleep(100 * time.Millisecond)
+					},
+				)
+
+				release, err := client.WithTimeout(time.Second).Releases.Get("banana", 3)
+				Expect(err).NotTo(HaveOccurred())
+				Expect(release.ID).To(Equal(3))
+
+				_, err = client.Releases.Get("banana", 3)
+				Expect(errors.Is(err, context.DeadlineExceeded)).To(BeTrue())
+			})
+
+			Context("when the timeout is zero", func() {
+				It("removes the limit", func() {
+					server.AppendHandlers(func(w http.ResponseWriter, req *http.Request) {
+						time.Sleep(100 * time.Millisecond)
+					})
+
+					_, err := client.WithTimeout(0).MakeRequest("GET", "/foo", http.StatusOK, nil)
+					Expect(err).NotTo(HaveOccurred())
+				})
+			})
+		})
+	})
+
+	Describe("WithContext", func() {
+		It("aborts calls when the context is cancelled", func() {
+			ctx, cancel := context.WithCancel(context.Background())
+			cancel()
+
+			_, err := client.WithContext(ctx).MakeRequest("GET", "/foo", http.StatusOK, nil)
+			Expect(errors.Is(err, context.Canceled)).To(BeTrue())
+
+			Expect(server.ReceivedRequests()).To(BeEmpty())
+		})
+
+		It("uses the sooner of the context deadline and the timeout", func() {
+			server.AppendHandlers(func(w http.ResponseWriter, req *http.Request) {
+				time.Sleep(200 * time.Millisecond)
+			})
+
+			ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+			defer cancel()
+
+			start := time.Now()
+			_, err := client.WithContext(ctx).WithTimeout(10*time.Second).MakeRequest("GET", "/foo", http.StatusOK, nil)
+			Expect(errors.Is(err, context.DeadlineExceeded)).To(BeTrue())
+			Expect(time.Since(start)).To(BeNumerically("<", 150*time.Millisecond))
+		})
+	})
+
+	Describe("Close", func() {
+		It("aborts in-flight calls of the client and its copies", func() {
+			server.AppendHandlers(func(w http.ResponseWriter, req *http.Request) {
+				select {
+				case <-req.Context().Done():
+				case <-time.After(time.Second):
+				}
+			})
+
+			errs := make(chan error, 1)
+			go func() {
+				_, err := client.WithTimeout(time.Minute).MakeRequest("GET", "/foo", http.StatusOK, nil)
+				errs <- err
+			}()
+
+			Eventually(server.ReceivedRequests).Should(HaveLen(1))
+			Expect(client.Close()).To(Succeed())
+
+			var err error
+			Eventually(errs).Should(Receive(&err))
+			Expect(errors.Is(err, context.Canceled)).To(BeTrue())
+		})
+
+		It("rejects later calls with ErrClientClosed", func() {
+			derived := client.WithUserAgentSuffix("(op=test)")
+
+			Expect(client.Close()).To(Succeed())
+			Expect(client.Close()).To(Succeed())
+
+			_, err := client.MakeRequest("GET", "/foo", http.StatusOK, nil)
+			Expect(err).To(Equal(pivnet.ErrClientClosed{}))
+
+			_, err = derived.Releases.Get("banana", 3)
+			Expect(err).To(MatchError("client is closed"))
+
+			Expect(server.ReceivedRequests()).To(BeEmpty())
+		})
+
+		It("aborts calls made with their own context", func() {
+			Expect(client.Close()).To(Succeed())
+
+			_, err := client.WithContext(context.Background()).MakeRequest("GET", "/foo", http.StatusOK, nil)
+			Expect(err).To(Equal(pivnet.ErrClientClosed{}))
+		})
+	})
+
+	Context("when Context is set", func() {
+		var cancel context.CancelFunc
+
+		BeforeEach(func() {
+			newClientConfig.Context, cancel = context.WithCancel(context.Background())
+			client = pivnet.NewClient(newClientConfig, fakeLogger)
+		})
+
+		It("rejects calls once the context is cancelled", func() {
+			server.AppendHandlers(ghttp.RespondWith(http.StatusOK, nil))
+
+			_, err := client.MakeRequest("GET", "/foo", http.StatusOK, nil)
+			Expect(err).NotTo(HaveOccurred())
+
+			cancel()
+
+			_, err = client.MakeRequest("GET", "/foo", http.StatusOK, nil)
+			Expect(err).To(Equal(pivnet.ErrClientClosed{}))
+		})
+	})
+
+	Context("when ResponseHeaderTimeout is set", func() {
+		BeforeEach(func() {
+			newClientConfig.ResponseHeaderTimeout = 20 * time.Millisecond
+			client = pivnet.NewClient(newClientConfig, fakeLogger)
+		})
+
+		It("fails if the server is slower to respond", func() {
+			server.AppendHandlers(func(w http.ResponseWriter, req *http.Request) {
+				time.Sleep(200 * time.Millisecond)
+			})
+
+			_, err := client.MakeRequest("GET", "/foo", http.StatusOK, nil)
+			Expect(err).To(MatchError(ContainSubstring("timeout awaiting response headers")))
+		})
+	})
+
+	Context("when TLSHandshakeTimeout is set", func() {
+		var (
+			listener net.Listener
+		)
+
+		BeforeEach(func() {
+			var err error
+			listener, err = net.Listen("tcp", "127.0.0.1:0")
+			Expect(err).NotTo(HaveOccurred())
+
+			go func() {
+				for {
+					conn, err := listener.Accept()
+					if err != nil {
+						return
+					}
+					defer conn.Close()
+				}
+			}()
+
+			newClientConfig.Host = "https://" + listener.Addr().String()
+			newClientConfig.TLSHandshakeTimeout = 20 * time.Millisecond
+			client = pivnet.NewClient(newClientConfig, fakeLogger)
+		})
+
+		AfterEach(func() {
+			listener.Close()
+		})
+
+		It("fails if the server does not complete the handshake in time", func() {
+			_, err := client.MakeRequest("GET", "/foo", http.StatusOK, nil)
+			Expect(err).To(MatchError(ContainSubstring("TLS handshake timeout")))
+		})
+	})
+
+	Context("when DisableRedirects is set", func() {
+		BeforeEach(func() {
+			newClientConfig.DisableRedirects = true
+			client = pivnet.NewClient(newClientConfig, fakeLogger)
+		})
+
+		It("returns the redirect response", func() {
+			server.AppendHandlers(
+				ghttp.CombineHandlers(
+					ghttp.VerifyRequest(
+						"GET",
+						fmt.Sprintf("%s/foo", apiPrefix),
+					),
+					ghttp.RespondWith(http.StatusFound, nil, http.Header{"Location": []string{"/bar"}}),
+				),
+			)
+
+			resp, err := client.MakeRequest(
+				"GET",
+				"/foo",
+				http.StatusFound,
+				nil,
+			)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(resp.Header.Get("Location")).To(Equal("/bar"))
+			Expect(server.ReceivedRequests()).To(HaveLen(1))
+		})
+	})
+
+	Context("when MaxConcurrentRequests is set", func() {
+		BeforeEach(func() {
+			newClientConfig.MaxConcurrentRequests = 1
+			client = pivnet.NewClient(newClientConfig, fakeLogger)
+
+			for i := 0; i < 2; i++ {
+				server.AppendHandlers(
+					ghttp.CombineHandlers(
+						ghttp.VerifyRequest(
+							"GET",
+							fmt.Sprintf("%s/foo", apiPrefix),
+						),
+						ghttp.RespondWithJSONEncoded(http.StatusOK, releases),
+					),
+				)
+			}
+		})
+
+		It("does not send further requests until the response body is closed", func() {
+			resp, err := client.MakeRequest(
+				"GET",
+				"/foo",
+				http.StatusOK,
+				nil,
+			)
+			Expect(err).NotTo(HaveOccurred())
+
+			done := make(chan error, 1)
+			go func() {
+				defer GinkgoRecover()
+
+				resp, err := client.MakeRequest(
+					"GET",
+					"/foo",
+					http.StatusOK,
+					nil,
+				)
+				if err == nil {
+					resp.Body.Close()
+				}
+				done <- err
+			}()
+
+			Consistently(server.ReceivedRequests, "200ms").Should(HaveLen(1))
+
+			resp.Body.Close()
+
+			Eventually(done).Should(Receive(BeNil()))
+			Expect(server.ReceivedRequests()).To(HaveLen(2))
+		})
+	})
+
+	Context("when MaxResponseBytes is set", func() {
+		BeforeEach(func() {
+			newClientConfig.MaxResponseBytes = 10
+			client = pivnet.NewClient(newClientConfig, fakeLogger)
+		})
+
+		It("returns ErrResponseTooLarge when reading past the limit", func() {
+			server.AppendHandlers(
+				ghttp.RespondWith(http.StatusOK, `{"releases":[{"id":1}]}`),
+			)
+
+			resp, err := client.MakeRequest(
+				"GET",
+				"/foo",
+				http.StatusOK,
+				nil,
+			)
+			Expect(err).NotTo(HaveOccurred())
+			defer resp.Body.Close()
+
+			b, err := ioutil.ReadAll(resp.Body)
+			Expect(err).To(Equal(pivnet.ErrResponseTooLarge{Limit: 10}))
+			Expect(b).To(HaveLen(10))
+		})
+
+		It("applies the limit to error responses", func() {
+			server.AppendHandlers(
+				ghttp.RespondWith(http.StatusTeapot, `{"message":"foo message"}`),
+			)
+
+			_, err := client.MakeRequest(
+				"GET",
+				"/foo",
+				http.StatusOK,
+				nil,
+			)
+			Expect(err).To(Equal(pivnet.ErrResponseTooLarge{Limit: 10}))
+		})
+
+		Context("when the limit is negative", func() {
+			BeforeEach(func() {
+				newClientConfig.MaxResponseBytes = -1
+				client = pivnet.NewClient(newClientConfig, fakeLogger)
+			})
+
+			It("does not limit the response", func() {
+				server.AppendHandlers(
+					ghttp.RespondWith(http.StatusOK, `{"releases":[{"id":1}]}`),
+				)
+
+				resp, err := client.MakeRequest(
+					"GET",
+					"/foo",
+					http.StatusOK,
+					nil,
+				)
+				Expect(err).NotTo(HaveOccurred())
+				defer resp.Body.Close()
+
+				_, err = ioutil.ReadAll(resp.Body)
+				Expect(err).NotTo(HaveOccurred())
+			})
+		})
+	})
+
+	Describe("ClientConfig.Validate", func() {
+		It("accepts http and https hosts", func() {
+			Expect(pivnet.ClientConfig{Host: "https://network.pivotal.io"}.Validate()).To(Succeed())
+			Expect(pivnet.ClientConfig{Host: "http://localhost:8080/"}.Validate()).To(Succeed())
+		})
+
+		It("rejects a host without a scheme", func() {
+			err := pivnet.ClientConfig{Host: "network.pivotal.io"}.Validate()
+			Expect(err).To(MatchError(ContainSubstring("must start with http:// or https://")))
+		})
+
+		It("rejects a host without a hostname", func() {
+			err := pivnet.ClientConfig{Host: "https://"}.Validate()
+			Expect(err).To(MatchError(ContainSubstring("no hostname")))
+		})
+
+		It("rejects a host with a path", func() {
+			err := pivnet.ClientConfig{Host: "https://network.pivotal.io/api/v2"}.Validate()
+			Expect(err).To(MatchError(ContainSubstring("must not include a path")))
+		})
+
+		It("rejects a host that cannot be parsed", func() {
+			err := pivnet.ClientConfig{Host: "%%%"}.Validate()
+			Expect(err).To(MatchError(ContainSubstring("Invalid host '%%%'")))
+		})
+	})
+
 	Describe("CreateRequest", func() {
 		It("strips the host prefix if present", func() {
 			req, err := client.CreateRequest(
@@ -413,5 +1135,41 @@ var _ = Describe("PivnetClient", func() {
 			Expect(err).NotTo(HaveOccurred())
 			Expect(req.URL.Path).To(Equal("/api/v2/foo/bar"))
 		})
+
+		Context("when EndpointOverrides are provided", func() {
+			BeforeEach(func() {
+				newClientConfig.EndpointOverrides = map[string]string{
+					"/foo":     "/moved-foo",
+					"/foo/bar": "/bar",
+				}
+				client = pivnet.NewClient(newClientConfig, fakeLogger)
+			})
+
+			It("replaces the longest matching prefix", func() {
+				req, err := client.CreateRequest("GET", "/foo/bar/1", nil)
+				Expect(err).NotTo(HaveOccurred())
+				Expect(req.URL.Path).To(Equal("/api/v2/bar/1"))
+
+				req, err = client.CreateRequest("GET", "/foo/baz", nil)
+				Expect(err).NotTo(HaveOccurred())
+				Expect(req.URL.Path).To(Equal("/api/v2/moved-foo/baz"))
+			})
+
+			It("only matches whole path segments", func() {
+				req, err := client.CreateRequest("GET", "/foobar", nil)
+				Expect(err).NotTo(HaveOccurred())
+				Expect(req.URL.Path).To(Equal("/api/v2/foobar"))
+			})
+
+			It("applies to download links", func() {
+				req, err := client.CreateRequest(
+					"POST",
+					fmt.Sprintf("https://example.com/%s/foo/bar/download", "api/v2"),
+					nil,
+				)
+				Expect(err).NotTo(HaveOccurred())
+				Expect(req.URL.Path).To(Equal("/api/v2/bar/download"))
+			})
+		})
 	})
 })
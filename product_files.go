@@ -2,10 +2,15 @@ package pivnet
 
 import (
 	"bytes"
+	"context"
 	"encoding/json"
 	"fmt"
 	"io"
 	"net/http"
+	"sort"
+	"strings"
+	"sync"
+	"time"
 
 	"github.com/pivotal-cf/go-pivnet/logger"
 )
@@ -40,6 +45,7 @@ type ProductFileResponse struct {
 type ProductFile struct {
 	ID                 int      `json:"id,omitempty" yaml:"id,omitempty"`
 	AWSObjectKey       string   `json:"aws_object_key,omitempty" yaml:"aws_object_key,omitempty"`
+	CreatedAt          string   `json:"created_at,omitempty" yaml:"created_at,omitempty"`
 	Description        string   `json:"description,omitempty" yaml:"description,omitempty"`
 	DocsURL            string   `json:"docs_url,omitempty" yaml:"docs_url,omitempty"`
 	FileTransferStatus string   `json:"file_transfer_status,omitempty" yaml:"file_transfer_status,omitempty"`
@@ -52,9 +58,30 @@ type ProductFile struct {
 	Platforms          []string `json:"platforms,omitempty" yaml:"platforms,omitempty"`
 	ReadyToServe       bool     `json:"ready_to_serve,omitempty" yaml:"ready_to_serve,omitempty"`
 	ReleasedAt         string   `json:"released_at,omitempty" yaml:"released_at,omitempty"`
+	SHA1               string   `json:"sha1,omitempty" yaml:"sha1,omitempty"`
+	SHA256             string   `json:"sha256,omitempty" yaml:"sha256,omitempty"`
 	Size               int      `json:"size,omitempty" yaml:"size,omitempty"`
 	SystemRequirements []string `json:"system_requirements,omitempty" yaml:"system_requirements,omitempty"`
 	Links              *Links   `json:"_links,omitempty" yaml:"_links,omitempty"`
+
+	// AdditionalFields holds fields returned by Pivnet that are not
+	// modelled above. They are never sent to Pivnet.
+	AdditionalFields map[string]json.RawMessage `json:"-" yaml:"-"`
+}
+
+func (p *ProductFile) UnmarshalJSON(b []byte) error {
+	type productFile ProductFile
+
+	var decoded productFile
+	additionalFields, err := unmarshalWithAdditionalFields(b, &decoded)
+	if err != nil {
+		return err
+	}
+
+	*p = ProductFile(decoded)
+	p.AdditionalFields = additionalFields
+
+	return nil
 }
 
 func (p ProductFile) DownloadLink() (string, error) {
@@ -65,6 +92,18 @@ func (p ProductFile) DownloadLink() (string, error) {
 	return p.Links.Download["href"], nil
 }
 
+func (p ProductFile) ReleasedAtTime() (time.Time, error) {
+	return parseTimestamp(p.ReleasedAt)
+}
+
+func (p ProductFile) CreatedAtTime() (time.Time, error) {
+	return parseTimestamp(p.CreatedAt)
+}
+
+const (
+	FileTransferStatusComplete = "complete"
+)
+
 const (
 	FileTypeSoftware          = "Software"
 	FileTypeDocumentation     = "Documentation"
@@ -121,6 +160,255 @@ func (p ProductFilesService) ListForRelease(productSlug string, releaseID int) (
 	return response.ProductFiles, nil
 }
 
+// ListForReleaseByFileType returns the product files of the release with
+// the given file type, such as FileTypeOpenSourceLicense, ignoring case.
+func (p ProductFilesService) ListForReleaseByFileType(
+	productSlug string,
+	releaseID int,
+	fileType string,
+) ([]ProductFile, error) {
+	productFiles, err := p.ListForRelease(productSlug, releaseID)
+	if err != nil {
+		return []ProductFile{}, err
+	}
+
+	matching := []ProductFile{}
+	for _, pf := range productFiles {
+		if strings.EqualFold(pf.FileType, fileType) {
+			matching = append(matching, pf)
+		}
+	}
+
+	return matching, nil
+}
+
+// ReleasesUsing returns the releases of the product the product file is
+// attached to, which is empty if the file is unused. Pivnet does not provide
+// this directly, so it is derived by listing the product files of every
+// release of the product, using at most concurrency simultaneous requests.
+//
+// Releases that could not be checked are reported in a MultiError, as the
+// result would be incomplete. If ctx is done, requests in flight are
+// aborted, no further releases are checked and ctx.Err() is returned.
+func (p ProductFilesService) ReleasesUsing(
+	ctx context.Context,
+	productSlug string,
+	productFileID int,
+	concurrency int,
+) ([]Release, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	p.client = p.client.WithContext(ctx)
+
+	releases, err := ReleasesService{client: p.client}.List(productSlug)
+	if err != nil {
+		return nil, err
+	}
+
+	using := make([]bool, len(releases))
+	errs := map[int]error{}
+
+	var mutex sync.Mutex
+	forEachConcurrently(len(releases), concurrency, func(i int) {
+		if ctx.Err() != nil {
+			return
+		}
+
+		productFiles, err := p.ListForRelease(productSlug, releases[i].ID)
+		if err != nil {
+			mutex.Lock()
+			errs[releases[i].ID] = err
+			mutex.Unlock()
+			return
+		}
+
+		for _, pf := range productFiles {
+			if pf.ID == productFileID {
+				using[i] = true
+				break
+			}
+		}
+	})
+
+	if ctx.Err() != nil {
+		return nil, ctx.Err()
+	}
+
+	if err := newMultiError(errs); err != nil {
+		return nil, err
+	}
+
+	associated := []Release{}
+	for i, release := range releases {
+		if using[i] {
+			associated = append(associated, release)
+		}
+	}
+
+	return associated, nil
+}
+
+// UniqueProductFile is a file content, identified by its SHA256 checksum,
+// and where it is used across the releases of a product.
+type UniqueProductFile struct {
+	// ProductFile is the first product file found with the checksum.
+	ProductFile ProductFile `json:"product_file" yaml:"product_file"`
+
+	// ProductFileIDs are the product files with the checksum. More than one
+	// indicates the same content is stored more than once.
+	ProductFileIDs []int `json:"product_file_ids" yaml:"product_file_ids"`
+
+	// ReleaseIDs are the releases that have one of the product files.
+	ReleaseIDs []int `json:"release_ids" yaml:"release_ids"`
+}
+
+// UniqueAcrossProduct returns the product files of every release of the
+// product, keyed by lowercase SHA256 checksum, using at most concurrency
+// simultaneous requests. Product files without a SHA256 checksum are not
+// included. IDs are sorted in ascending order.
+//
+// Releases whose product files could not be listed are reported in a
+// MultiError alongside the results from the other releases. If ctx is done,
+// requests in flight are aborted, no further releases are checked and the
+// results so far are returned with ctx.Err().
+func (p ProductFilesService) UniqueAcrossProduct(
+	ctx context.Context,
+	productSlug string,
+	concurrency int,
+) (map[string]UniqueProductFile, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	p = ProductFilesService{client: p.client.WithContext(ctx)}
+
+	releases, err := ReleasesService{client: p.client}.List(productSlug)
+	if err != nil {
+		return nil, err
+	}
+
+	unique := map[string]UniqueProductFile{}
+	errs := map[int]error{}
+
+	var mutex sync.Mutex
+	forEachConcurrently(len(releases), concurrency, func(i int) {
+		if ctx.Err() != nil {
+			return
+		}
+
+		productFiles, err := p.ListForRelease(productSlug, releases[i].ID)
+
+		mutex.Lock()
+		defer mutex.Unlock()
+
+		if err != nil {
+			errs[releases[i].ID] = err
+			return
+		}
+
+		for _, pf := range productFiles {
+			checksum := strings.ToLower(pf.SHA256)
+			if checksum == "" {
+				continue
+			}
+
+			file, ok := unique[checksum]
+			if !ok {
+				file.ProductFile = pf
+			}
+			file.ProductFileIDs = appendUniqueID(file.ProductFileIDs, pf.ID)
+			file.ReleaseIDs = appendUniqueID(file.ReleaseIDs, releases[i].ID)
+			unique[checksum] = file
+		}
+	})
+
+	for checksum, file := range unique {
+		sort.Ints(file.ProductFileIDs)
+		sort.Ints(file.ReleaseIDs)
+		unique[checksum] = file
+	}
+
+	if ctx.Err() != nil {
+		return unique, ctx.Err()
+	}
+
+	return unique, newMultiError(errs)
+}
+
+func appendUniqueID(ids []int, id int) []int {
+	for _, existing := range ids {
+		if existing == id {
+			return ids
+		}
+	}
+	return append(ids, id)
+}
+
+// WaitForReady polls the product file every interval until Pivnet has
+// finished processing it, i.e. it is ready to serve or its transfer status is
+// complete, and returns the final product file. It returns an error if the
+// transfer fails, if the file is not ready within timeout, or if ctx is done,
+// which also aborts a poll in flight. A failing poll is returned immediately.
+// interval must be positive.
+func (p ProductFilesService) WaitForReady(
+	ctx context.Context,
+	productSlug string,
+	productFileID int,
+	interval time.Duration,
+	timeout time.Duration,
+) (ProductFile, error) {
+	if interval <= 0 {
+		return ProductFile{}, fmt.Errorf("Poll interval must be positive - got %s", interval)
+	}
+
+	if err := ctx.Err(); err != nil {
+		return ProductFile{}, err
+	}
+
+	p.client = p.client.WithContext(ctx)
+
+	timer := time.NewTimer(timeout)
+	defer timer.Stop()
+
+	poll := time.NewTimer(interval)
+	defer poll.Stop()
+
+	for {
+		pf, err := p.Get(productSlug, productFileID)
+		if err != nil {
+			return ProductFile{}, err
+		}
+
+		if pf.ReadyToServe || pf.FileTransferStatus == FileTransferStatusComplete {
+			return pf, nil
+		}
+
+		if strings.HasPrefix(pf.FileTransferStatus, "failed") {
+			return pf, fmt.Errorf(
+				"Product file %d failed processing with status '%s'",
+				productFileID,
+				pf.FileTransferStatus,
+			)
+		}
+
+		select {
+		case <-ctx.Done():
+			return pf, ctx.Err()
+		case <-timer.C:
+			return pf, fmt.Errorf(
+				"Timed out after %s waiting for product file %d to be ready - last status '%s'",
+				timeout,
+				productFileID,
+				pf.FileTransferStatus,
+			)
+		case <-poll.C:
+			poll.Reset(interval)
+		}
+	}
+}
+
 func (p ProductFilesService) Get(productSlug string, productFileID int) (ProductFile, error) {
 	url := fmt.Sprintf(
 		"/products/%s/product_files/%d",
@@ -297,6 +585,13 @@ func (p ProductFilesService) Delete(productSlug string, id int) (ProductFile, er
 	return response.ProductFile, nil
 }
 
+// DeleteIfExists deletes the product file, treating a product file that does
+// not exist as already deleted. Use Delete to find out whether it existed.
+func (p ProductFilesService) DeleteIfExists(productSlug string, id int) error {
+	_, err := p.Delete(productSlug, id)
+	return ignoreNotFound(err)
+}
+
 func (p ProductFilesService) AddToRelease(
 	productSlug string,
 	releaseID int,
@@ -373,6 +668,50 @@ func (p ProductFilesService) RemoveFromRelease(
 	return nil
 }
 
+// AddManyToRelease adds each of the product files to the release, making up
+// to concurrency requests at once. Files that could not be added are
+// reported in a MultiError keyed by product file ID.
+func (p ProductFilesService) AddManyToRelease(
+	productSlug string,
+	releaseID int,
+	productFileIDs []int,
+	concurrency int,
+) error {
+	return forEachProductFile(productFileIDs, concurrency, func(productFileID int) error {
+		return p.AddToRelease(productSlug, releaseID, productFileID)
+	})
+}
+
+// RemoveManyFromRelease removes each of the product files from the release,
+// making up to concurrency requests at once. Files that could not be removed
+// are reported in a MultiError keyed by product file ID.
+func (p ProductFilesService) RemoveManyFromRelease(
+	productSlug string,
+	releaseID int,
+	productFileIDs []int,
+	concurrency int,
+) error {
+	return forEachProductFile(productFileIDs, concurrency, func(productFileID int) error {
+		return p.RemoveFromRelease(productSlug, releaseID, productFileID)
+	})
+}
+
+func forEachProductFile(productFileIDs []int, concurrency int, fn func(productFileID int) error) error {
+	errs := map[int]error{}
+
+	var mutex sync.Mutex
+	forEachConcurrently(len(productFileIDs), concurrency, func(i int) {
+		err := fn(productFileIDs[i])
+		if err != nil {
+			mutex.Lock()
+			errs[productFileIDs[i]] = err
+			mutex.Unlock()
+		}
+	})
+
+	return newMultiError(errs)
+}
+
 func (p ProductFilesService) AddToFileGroup(
 	productSlug string,
 	fileGroupID int,
@@ -449,6 +788,9 @@ func (p ProductFilesService) RemoveFromFileGroup(
 	return nil
 }
 
+// DownloadForRelease copies the product file to writer as it is received.
+// Unlike DownloadTo, the content is not verified against the product file's
+// checksums, nor checked for looking like an error page.
 func (p ProductFilesService) DownloadForRelease(
 	writer io.Writer,
 	productSlug string,
@@ -471,7 +813,10 @@ func (p ProductFilesService) DownloadForRelease(
 
 	p.client.logger.Debug("Downloading file", logger.Data{"downloadLink": downloadLink})
 
-	resp, err := p.client.MakeRequest(
+	client := p.client
+	client.auditLogger = nil
+
+	resp, err := client.MakeRequest(
 		"POST",
 		downloadLink,
 		http.StatusOK,
@@ -2,9 +2,13 @@ package pivnet_test
 
 import (
 	"bytes"
+	"context"
 	"errors"
 	"fmt"
+	"io/ioutil"
 	"net/http"
+	"strings"
+	"time"
 
 	. "github.com/onsi/ginkgo"
 	. "github.com/onsi/gomega"
@@ -225,6 +229,394 @@ var _ = Describe("PivnetClient - product files", func() {
 		})
 	})
 
+	Describe("List product files for release by file type", func() {
+		var (
+			productSlug string
+			releaseID   int
+		)
+
+		BeforeEach(func() {
+			productSlug = "banana"
+			releaseID = 12
+		})
+
+		It("returns only the product files of the file type", func() {
+			server.AppendHandlers(
+				ghttp.CombineHandlers(
+					ghttp.VerifyRequest(
+						"GET",
+						fmt.Sprintf("%s/products/%s/releases/%d/product_files", apiPrefix, productSlug, releaseID),
+					),
+					ghttp.RespondWithJSONEncoded(http.StatusOK, pivnet.ProductFilesResponse{[]pivnet.ProductFile{
+						{ID: 1, FileType: pivnet.FileTypeSoftware},
+						{ID: 2, FileType: pivnet.FileTypeOpenSourceLicense},
+						{ID: 3, FileType: pivnet.FileTypeOpenSourceLicense},
+					}}),
+				),
+			)
+
+			productFiles, err := client.ProductFiles.ListForReleaseByFileType(
+				productSlug,
+				releaseID,
+				pivnet.FileTypeOpenSourceLicense,
+			)
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(productFiles).To(HaveLen(2))
+			Expect(productFiles[0].ID).To(Equal(2))
+			Expect(productFiles[1].ID).To(Equal(3))
+		})
+
+		It("matches the file type ignoring case", func() {
+			server.AppendHandlers(
+				ghttp.RespondWithJSONEncoded(http.StatusOK, pivnet.ProductFilesResponse{[]pivnet.ProductFile{
+					{ID: 1, FileType: pivnet.FileTypeSoftware},
+					{ID: 2, FileType: pivnet.FileTypeOpenSourceLicense},
+				}}),
+			)
+
+			productFiles, err := client.ProductFiles.ListForReleaseByFileType(productSlug, releaseID, "software")
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(productFiles).To(HaveLen(1))
+			Expect(productFiles[0].ID).To(Equal(1))
+		})
+
+		Context("when the server responds with a non-2XX status code", func() {
+			It("returns an error", func() {
+				server.AppendHandlers(
+					ghttp.RespondWithJSONEncoded(http.StatusTeapot, pivnetErr{Message: "foo message"}),
+				)
+
+				_, err := client.ProductFiles.ListForReleaseByFileType(
+					productSlug,
+					releaseID,
+					pivnet.FileTypeOpenSourceLicense,
+				)
+				Expect(err).To(HaveOccurred())
+
+				Expect(err.Error()).To(ContainSubstring("foo message"))
+			})
+		})
+	})
+
+	Describe("ReleasesUsing", func() {
+		var (
+			productSlug  string
+			releasesURL  string
+			productFiles map[int]string
+		)
+
+		BeforeEach(func() {
+			productSlug = "banana"
+			releasesURL = fmt.Sprintf("%s/products/%s/releases", apiPrefix, productSlug)
+
+			productFiles = map[int]string{
+				1: `{"product_files":[{"id":10},{"id":20}]}`,
+				2: `{"product_files":[{"id":30}]}`,
+				3: `{"product_files":[{"id":20}]}`,
+			}
+
+			server.RouteToHandler("GET", releasesURL,
+				ghttp.RespondWith(http.StatusOK, `{"releases":[{"id":1},{"id":2},{"id":3}]}`),
+			)
+		})
+
+		JustBeforeEach(func() {
+			for releaseID, response := range productFiles {
+				server.RouteToHandler("GET", fmt.Sprintf("%s/%d/product_files", releasesURL, releaseID),
+					ghttp.RespondWith(http.StatusOK, response),
+				)
+			}
+		})
+
+		It("returns the releases the product file is attached to", func() {
+			releases, err := client.ProductFiles.ReleasesUsing(context.Background(), productSlug, 20, 2)
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(releases).To(HaveLen(2))
+			Expect(releases[0].ID).To(Equal(1))
+			Expect(releases[1].ID).To(Equal(3))
+		})
+
+		Context("when the product file is unused", func() {
+			It("returns an empty slice", func() {
+				releases, err := client.ProductFiles.ReleasesUsing(context.Background(), productSlug, 40, 2)
+				Expect(err).NotTo(HaveOccurred())
+				Expect(releases).NotTo(BeNil())
+				Expect(releases).To(BeEmpty())
+			})
+		})
+
+		Context("when listing the product files of a release fails", func() {
+			BeforeEach(func() {
+				delete(productFiles, 2)
+				server.RouteToHandler("GET", releasesURL+"/2/product_files",
+					ghttp.RespondWith(http.StatusTeapot, `{"message":"foo message"}`),
+				)
+			})
+
+			It("returns a MultiError", func() {
+				_, err := client.ProductFiles.ReleasesUsing(context.Background(), productSlug, 20, 2)
+
+				var multiErr pivnet.MultiError
+				Expect(errors.As(err, &multiErr)).To(BeTrue())
+				Expect(multiErr.Errors).To(HaveLen(1))
+				Expect(multiErr.Errors[0].ID).To(Equal(2))
+			})
+		})
+
+		Context("when the context is cancelled", func() {
+			It("returns the context error without checking the releases", func() {
+				ctx, cancel := context.WithCancel(context.Background())
+				cancel()
+
+				_, err := client.ProductFiles.ReleasesUsing(ctx, productSlug, 20, 2)
+				Expect(err).To(Equal(context.Canceled))
+
+				Expect(server.ReceivedRequests()).To(BeEmpty())
+			})
+
+			Context("while a request is in flight", func() {
+				It("aborts the request and returns the context error", func() {
+					ctx, cancel := context.WithCancel(context.Background())
+					defer cancel()
+
+					server.RouteToHandler("GET", releasesURL+"/2/product_files",
+						func(w http.ResponseWriter, r *http.Request) {
+							cancel()
+							<-r.Context().Done()
+						},
+					)
+
+					_, err := client.ProductFiles.ReleasesUsing(ctx, productSlug, 20, 1)
+					Expect(err).To(Equal(context.Canceled))
+				})
+			})
+		})
+	})
+
+	Describe("UniqueAcrossProduct", func() {
+		var (
+			productSlug string
+			releasesURL string
+		)
+
+		BeforeEach(func() {
+			productSlug = "banana"
+			releasesURL = fmt.Sprintf("%s/products/%s/releases", apiPrefix, productSlug)
+
+			server.RouteToHandler("GET", releasesURL,
+				ghttp.RespondWith(http.StatusOK, `{"releases":[{"id":1},{"id":2},{"id":3}]}`),
+			)
+			server.RouteToHandler("GET", releasesURL+"/1/product_files",
+				ghttp.RespondWith(http.StatusOK, `{"product_files":[{"id":10,"sha256":"AAAA"},{"id":20,"sha256":"bbbb"}]}`),
+			)
+			server.RouteToHandler("GET", releasesURL+"/2/product_files",
+				ghttp.RespondWith(http.StatusOK, `{"product_files":[{"id":30,"sha256":"aaaa"},{"id":40}]}`),
+			)
+			server.RouteToHandler("GET", releasesURL+"/3/product_files",
+				ghttp.RespondWith(http.StatusOK, `{"product_files":[{"id":20,"sha256":"bbbb"}]}`),
+			)
+		})
+
+		It("returns the product files keyed by checksum with the releases using them", func() {
+			unique, err := client.ProductFiles.UniqueAcrossProduct(context.Background(), productSlug, 2)
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(unique).To(HaveLen(2))
+
+			Expect(unique["aaaa"].ProductFileIDs).To(Equal([]int{10, 30}))
+			Expect(unique["aaaa"].ReleaseIDs).To(Equal([]int{1, 2}))
+
+			Expect(unique["bbbb"].ProductFile.ID).To(Equal(20))
+			Expect(unique["bbbb"].ProductFileIDs).To(Equal([]int{20}))
+			Expect(unique["bbbb"].ReleaseIDs).To(Equal([]int{1, 3}))
+		})
+
+		Context("when listing the product files of a release fails", func() {
+			BeforeEach(func() {
+				server.RouteToHandler("GET", releasesURL+"/3/product_files",
+					ghttp.RespondWith(http.StatusTeapot, `{"message":"foo message"}`),
+				)
+			})
+
+			It("returns the results of the other releases with a MultiError", func() {
+				unique, err := client.ProductFiles.UniqueAcrossProduct(context.Background(), productSlug, 2)
+
+				var multiErr pivnet.MultiError
+				Expect(errors.As(err, &multiErr)).To(BeTrue())
+				Expect(multiErr.Errors).To(HaveLen(1))
+				Expect(multiErr.Errors[0].ID).To(Equal(3))
+
+				Expect(unique).To(HaveLen(2))
+				Expect(unique["bbbb"].ReleaseIDs).To(Equal([]int{1}))
+			})
+		})
+
+		Context("when the context is cancelled", func() {
+			It("returns the context error without checking the releases", func() {
+				ctx, cancel := context.WithCancel(context.Background())
+				cancel()
+
+				unique, err := client.ProductFiles.UniqueAcrossProduct(ctx, productSlug, 2)
+				Expect(err).To(Equal(context.Canceled))
+				Expect(unique).To(BeEmpty())
+
+				Expect(server.ReceivedRequests()).To(BeEmpty())
+			})
+
+			Context("while a request is in flight", func() {
+				It("aborts the request and returns the context error", func() {
+					ctx, cancel := context.WithCancel(context.Background())
+					defer cancel()
+
+					server.RouteToHandler("GET", releasesURL,
+						func(w http.ResponseWriter, r *http.Request) {
+							cancel()
+							<-r.Context().Done()
+						},
+					)
+
+					_, err := client.ProductFiles.UniqueAcrossProduct(ctx, productSlug, 2)
+					Expect(errors.Is(err, context.Canceled)).To(BeTrue())
+				})
+			})
+		})
+	})
+
+	Describe("WaitForReady", func() {
+		var (
+			productSlug    string
+			productFileURL string
+		)
+
+		BeforeEach(func() {
+			productSlug = "banana"
+			productFileURL = fmt.Sprintf("%s/products/%s/product_files/%d", apiPrefix, productSlug, 1234)
+		})
+
+		respondWithStatus := func(status string) http.HandlerFunc {
+			return ghttp.CombineHandlers(
+				ghttp.VerifyRequest("GET", productFileURL),
+				ghttp.RespondWith(http.StatusOK, fmt.Sprintf(
+					`{"product_file":{"id":1234,"file_transfer_status":"%s"}}`,
+					status,
+				)),
+			)
+		}
+
+		It("polls until the product file is ready", func() {
+			server.AppendHandlers(
+				respondWithStatus("in_progress"),
+				respondWithStatus("in_progress"),
+				respondWithStatus("complete"),
+			)
+
+			pf, err := client.ProductFiles.WaitForReady(
+				context.Background(), productSlug, 1234, 10*time.Millisecond, time.Minute,
+			)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(pf.FileTransferStatus).To(Equal(pivnet.FileTransferStatusComplete))
+
+			Expect(server.ReceivedRequests()).To(HaveLen(3))
+		})
+
+		Context("when the product file is ready to serve", func() {
+			It("returns immediately", func() {
+				server.AppendHandlers(
+					ghttp.RespondWith(http.StatusOK, `{"product_file":{"id":1234,"ready_to_serve":true}}`),
+				)
+
+				pf, err := client.ProductFiles.WaitForReady(
+					context.Background(), productSlug, 1234, time.Minute, time.Minute,
+				)
+				Expect(err).NotTo(HaveOccurred())
+				Expect(pf.ReadyToServe).To(BeTrue())
+			})
+		})
+
+		Context("when processing fails", func() {
+			It("returns an error with the product file", func() {
+				server.AppendHandlers(respondWithStatus("failed_sha256_check"))
+
+				pf, err := client.ProductFiles.WaitForReady(
+					context.Background(), productSlug, 1234, 10*time.Millisecond, time.Minute,
+				)
+				Expect(err).To(MatchError(ContainSubstring("failed_sha256_check")))
+				Expect(pf.ID).To(Equal(1234))
+			})
+		})
+
+		Context("when the product file is not ready in time", func() {
+			It("returns a timeout error", func() {
+				server.RouteToHandler("GET", productFileURL, respondWithStatus("in_progress"))
+
+				_, err := client.ProductFiles.WaitForReady(
+					context.Background(), productSlug, 1234, 10*time.Millisecond, 50*time.Millisecond,
+				)
+				Expect(err).To(MatchError(ContainSubstring("Timed out")))
+			})
+		})
+
+		Context("when the context is cancelled", func() {
+			It("returns the context error", func() {
+				server.RouteToHandler("GET", productFileURL, respondWithStatus("in_progress"))
+
+				ctx, cancel := context.WithCancel(context.Background())
+				cancel()
+
+				_, err := client.ProductFiles.WaitForReady(
+					ctx, productSlug, 1234, time.Minute, time.Minute,
+				)
+				Expect(err).To(Equal(context.Canceled))
+			})
+
+			Context("while a poll is in flight", func() {
+				It("aborts the poll and returns the context error", func() {
+					ctx, cancel := context.WithCancel(context.Background())
+					defer cancel()
+
+					server.RouteToHandler("GET", productFileURL,
+						func(w http.ResponseWriter, r *http.Request) {
+							cancel()
+							<-r.Context().Done()
+						},
+					)
+
+					_, err := client.ProductFiles.WaitForReady(
+						ctx, productSlug, 1234, time.Minute, time.Minute,
+					)
+					Expect(errors.Is(err, context.Canceled)).To(BeTrue())
+				})
+			})
+		})
+
+		Context("when the interval is not positive", func() {
+			It("returns an error without polling", func() {
+				_, err := client.ProductFiles.WaitForReady(
+					context.Background(), productSlug, 1234, 0, time.Minute,
+				)
+				Expect(err).To(MatchError("Poll interval must be positive - got 0s"))
+
+				Expect(server.ReceivedRequests()).To(BeEmpty())
+			})
+		})
+
+		Context("when polling fails", func() {
+			It("returns the error", func() {
+				server.AppendHandlers(
+					ghttp.RespondWith(http.StatusTeapot, `{"message":"foo message"}`),
+				)
+
+				_, err := client.ProductFiles.WaitForReady(
+					context.Background(), productSlug, 1234, 10*time.Millisecond, time.Minute,
+				)
+				Expect(err).To(MatchError(ContainSubstring("foo message")))
+			})
+		})
+	})
+
 	Describe("Get Product File", func() {
 		var (
 			productSlug   string
@@ -309,6 +701,20 @@ var _ = Describe("PivnetClient - product files", func() {
 		})
 	})
 
+	Context("when getting a product file that does not exist", func() {
+		It("returns an error matching ErrNotFound", func() {
+			server.AppendHandlers(
+				ghttp.CombineHandlers(
+					ghttp.VerifyRequest("GET", fmt.Sprintf("%s/products/%s/releases/%d/product_files/%d", apiPrefix, productSlug, 12, 34)),
+					ghttp.RespondWith(http.StatusNotFound, `{"message":"not found"}`),
+				),
+			)
+
+			_, err := client.ProductFiles.GetForRelease(productSlug, 12, 34)
+			Expect(errors.Is(err, pivnet.ErrNotFound{})).To(BeTrue())
+		})
+	})
+
 	Describe("Get product file for release", func() {
 		var (
 			productSlug   string
@@ -379,6 +785,30 @@ var _ = Describe("PivnetClient - product files", func() {
 				)))
 		})
 
+		Context("when the response contains fields that are not modelled", func() {
+			BeforeEach(func() {
+				response = map[string]interface{}{
+					"product_file": map[string]interface{}{
+						"id":        productFileID,
+						"new_field": "new value",
+					},
+				}
+			})
+
+			It("keeps them in AdditionalFields", func() {
+				productFile, err := client.ProductFiles.GetForRelease(
+					productSlug,
+					releaseID,
+					productFileID,
+				)
+				Expect(err).NotTo(HaveOccurred())
+
+				Expect(productFile.ID).To(Equal(productFileID))
+				Expect(productFile.AdditionalFields).To(HaveLen(1))
+				Expect(productFile.AdditionalFields["new_field"]).To(MatchJSON(`"new value"`))
+			})
+		})
+
 		Context("when the server responds with a non-2XX status code", func() {
 			BeforeEach(func() {
 				responseStatusCode = http.StatusTeapot
@@ -729,6 +1159,58 @@ var _ = Describe("PivnetClient - product files", func() {
 		})
 	})
 
+	Describe("Delete Product File If Exists", func() {
+		var (
+			id = 1234
+		)
+
+		It("deletes the product file", func() {
+			server.AppendHandlers(
+				ghttp.CombineHandlers(
+					ghttp.VerifyRequest(
+						"DELETE",
+						fmt.Sprintf("%s/products/%s/product_files/%d", apiPrefix, productSlug, id)),
+					ghttp.RespondWith(http.StatusOK, `{"product_file":{"id":1234}}`),
+				),
+			)
+
+			err := client.ProductFiles.DeleteIfExists(productSlug, id)
+			Expect(err).NotTo(HaveOccurred())
+		})
+
+		Context("when the product file does not exist", func() {
+			It("does not return an error", func() {
+				server.AppendHandlers(
+					ghttp.CombineHandlers(
+						ghttp.VerifyRequest(
+							"DELETE",
+							fmt.Sprintf("%s/products/%s/product_files/%d", apiPrefix, productSlug, id)),
+						ghttp.RespondWithJSONEncoded(http.StatusNotFound, pivnetErr{Message: "not found"}),
+					),
+				)
+
+				err := client.ProductFiles.DeleteIfExists(productSlug, id)
+				Expect(err).NotTo(HaveOccurred())
+			})
+		})
+
+		Context("when the server responds with another non-2XX status code", func() {
+			It("returns an error", func() {
+				server.AppendHandlers(
+					ghttp.CombineHandlers(
+						ghttp.VerifyRequest(
+							"DELETE",
+							fmt.Sprintf("%s/products/%s/product_files/%d", apiPrefix, productSlug, id)),
+						ghttp.RespondWithJSONEncoded(http.StatusTeapot, pivnetErr{Message: "foo message"}),
+					),
+				)
+
+				err := client.ProductFiles.DeleteIfExists(productSlug, id)
+				Expect(err).To(HaveOccurred())
+				Expect(err.Error()).To(ContainSubstring("foo message"))
+			})
+		})
+	})
 	Describe("Add Product File to release", func() {
 		var (
 			productSlug   = "some-product"
@@ -885,6 +1367,72 @@ var _ = Describe("PivnetClient - product files", func() {
 		})
 	})
 
+	Describe("Add many Product Files to release", func() {
+		var (
+			releaseID = 2345
+		)
+
+		It("adds each product file and reports the ones that failed", func() {
+			server.RouteToHandler("PATCH", fmt.Sprintf("%s/products/%s/releases/%d/add_product_file", apiPrefix, productSlug, releaseID),
+				func(w http.ResponseWriter, req *http.Request) {
+					body, err := ioutil.ReadAll(req.Body)
+					Expect(err).NotTo(HaveOccurred())
+
+					if strings.Contains(string(body), `"id":2`) {
+						w.WriteHeader(http.StatusTeapot)
+						w.Write([]byte(`{"message":"foo message"}`))
+						return
+					}
+					w.WriteHeader(http.StatusNoContent)
+				},
+			)
+
+			err := client.ProductFiles.AddManyToRelease(productSlug, releaseID, []int{1, 2, 3}, 2)
+			Expect(server.ReceivedRequests()).To(HaveLen(3))
+
+			multiErr, ok := err.(pivnet.MultiError)
+			Expect(ok).To(BeTrue())
+			Expect(multiErr.Errors).To(HaveLen(1))
+			Expect(multiErr.Errors[0].ID).To(Equal(2))
+			Expect(multiErr.Errors[0].Err).To(MatchError(ContainSubstring("foo message")))
+		})
+
+		It("returns nil when every product file is added", func() {
+			server.RouteToHandler("PATCH", fmt.Sprintf("%s/products/%s/releases/%d/add_product_file", apiPrefix, productSlug, releaseID),
+				ghttp.RespondWith(http.StatusNoContent, nil),
+			)
+
+			err := client.ProductFiles.AddManyToRelease(productSlug, releaseID, []int{1, 2}, 0)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(server.ReceivedRequests()).To(HaveLen(2))
+		})
+	})
+
+	Describe("Remove many Product Files from release", func() {
+		var (
+			releaseID = 2345
+		)
+
+		It("removes each product file", func() {
+			server.RouteToHandler("PATCH", fmt.Sprintf("%s/products/%s/releases/%d/remove_product_file", apiPrefix, productSlug, releaseID),
+				ghttp.RespondWith(http.StatusNoContent, nil),
+			)
+
+			err := client.ProductFiles.RemoveManyFromRelease(productSlug, releaseID, []int{1, 2, 3}, 2)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(server.ReceivedRequests()).To(HaveLen(3))
+		})
+
+		It("reports the product files that could not be removed", func() {
+			server.RouteToHandler("PATCH", fmt.Sprintf("%s/products/%s/releases/%d/remove_product_file", apiPrefix, productSlug, releaseID),
+				ghttp.RespondWith(http.StatusTeapot, `{"message":"foo message"}`),
+			)
+
+			err := client.ProductFiles.RemoveManyFromRelease(productSlug, releaseID, []int{3, 1}, 1)
+			Expect(err).To(MatchError("2 of the items failed - 1: 418 - foo message. Errors: ; 3: 418 - foo message. Errors: "))
+		})
+	})
+
 	Describe("Add Product File to file group", func() {
 		var (
 			productSlug   = "some-product"
@@ -1085,6 +1633,44 @@ var _ = Describe("PivnetClient - product files", func() {
 				})
 			})
 		})
+
+		Describe("ReleasedAtTime", func() {
+			It("parses a release date", func() {
+				productFile.ReleasedAt = "2016-05-04"
+
+				t, err := productFile.ReleasedAtTime()
+				Expect(err).NotTo(HaveOccurred())
+				Expect(t).To(Equal(time.Date(2016, 5, 4, 0, 0, 0, 0, time.UTC)))
+			})
+
+			Context("when released at is empty", func() {
+				It("returns the zero time", func() {
+					t, err := productFile.ReleasedAtTime()
+					Expect(err).NotTo(HaveOccurred())
+					Expect(t.IsZero()).To(BeTrue())
+				})
+			})
+
+			Context("when released at cannot be parsed", func() {
+				It("returns an error", func() {
+					productFile.ReleasedAt = "not-a-date"
+
+					_, err := productFile.ReleasedAtTime()
+					Expect(err).To(HaveOccurred())
+					Expect(err.Error()).To(ContainSubstring("not-a-date"))
+				})
+			})
+		})
+
+		Describe("CreatedAtTime", func() {
+			It("parses an RFC3339 timestamp", func() {
+				productFile.CreatedAt = "2016-05-04T10:11:12.000Z"
+
+				t, err := productFile.CreatedAtTime()
+				Expect(err).NotTo(HaveOccurred())
+				Expect(t).To(Equal(time.Date(2016, 5, 4, 10, 11, 12, 0, time.UTC)))
+			})
+		})
 	})
 
 	Describe("DownloadForRelease", func() {
@@ -1169,6 +1755,36 @@ var _ = Describe("PivnetClient - product files", func() {
 			Expect(writer.Bytes()).To(Equal(downloadLinkResponseBody))
 		})
 
+		Context("when the content does not match the product file's checksum", func() {
+			BeforeEach(func() {
+				getResponse = pivnet.ProductFileResponse{
+					pivnet.ProductFile{
+						ID:     1234,
+						SHA256: "not-the-sha256",
+						Links: &pivnet.Links{
+							Download: map[string]string{
+								"href": downloadLink,
+							},
+						},
+					},
+				}
+			})
+
+			It("writes the content without verifying it", func() {
+				writer := bytes.NewBuffer(nil)
+
+				err := client.ProductFiles.DownloadForRelease(
+					writer,
+					productSlug,
+					releaseID,
+					productFileID,
+				)
+				Expect(err).NotTo(HaveOccurred())
+
+				Expect(writer.Bytes()).To(Equal(downloadLinkResponseBody))
+			})
+		})
+
 		Context("when productFile.DownloadLink() returns an error", func() {
 			BeforeEach(func() {
 				getResponse = pivnet.ProductFileResponse{
@@ -1,6 +1,7 @@
 package pivnet
 
 import (
+	"errors"
 	"fmt"
 	"net/http"
 
@@ -9,14 +10,18 @@ import (
 )
 
 type ProductsService struct {
-	client Client
-	l      logger.Logger
+	client    Client
+	l         logger.Logger
+	slugCache *ttlCache
 }
 
 type Product struct {
 	ID   int    `json:"id,omitempty" yaml:"id,omitempty"`
 	Slug string `json:"slug,omitempty" yaml:"slug,omitempty"`
 	Name string `json:"name,omitempty" yaml:"name,omitempty"`
+
+	// LogoURL is empty if the product does not have a logo.
+	LogoURL string `json:"logo_url,omitempty" yaml:"logo_url,omitempty"`
 }
 
 type ProductsResponse struct {
@@ -68,3 +73,53 @@ func (p ProductsService) Get(slug string) (Product, error) {
 
 	return response, nil
 }
+
+// IconURL returns the URL of the product's logo, or an empty string if the
+// product does not have one.
+func (p ProductsService) IconURL(slug string) (string, error) {
+	product, err := p.Get(slug)
+	if err != nil {
+		return "", err
+	}
+
+	return product.LogoURL, nil
+}
+
+// SlugToID returns the ID of the product with the slug. Resolved IDs are
+// cached for ClientConfig.ProductSlugCacheTTL, shared by the client and its
+// copies, and failures are not cached. If there is no such product it
+// returns an ErrNotFound naming the slug.
+func (p ProductsService) SlugToID(slug string) (int, error) {
+	if id, ok := p.slugCache.get(slug); ok {
+		return id.(int), nil
+	}
+
+	product, err := p.Get(slug)
+	if err != nil {
+		var notFound ErrNotFound
+		if errors.As(err, &notFound) {
+			notFound.Message = fmt.Sprintf("Product '%s' not found", slug)
+			return 0, notFound
+		}
+		return 0, err
+	}
+
+	p.slugCache.set(slug, product.ID)
+
+	return product.ID, nil
+}
+
+// Exists reports whether a product with the slug exists, mapping a 404 to
+// false. A product that exists is remembered in the same cache as SlugToID,
+// so validating a slug before using it costs at most one request.
+func (p ProductsService) Exists(slug string) (bool, error) {
+	_, err := p.SlugToID(slug)
+	if err != nil {
+		if errors.Is(err, ErrNotFound{}) {
+			return false, nil
+		}
+		return false, err
+	}
+
+	return true, nil
+}
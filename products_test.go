@@ -115,6 +115,54 @@ var _ = Describe("PivnetClient - product", func() {
 		})
 	})
 
+	Describe("IconURL", func() {
+		var (
+			slug = "my-product"
+		)
+
+		It("returns the logo URL of the product", func() {
+			server.AppendHandlers(
+				ghttp.CombineHandlers(
+					ghttp.VerifyRequest("GET", fmt.Sprintf("%s/products/%s", apiPrefix, slug)),
+					ghttp.RespondWith(http.StatusOK, `{"id": 3, "logo_url": "https://example.com/logo.png"}`),
+				),
+			)
+
+			iconURL, err := client.Products.IconURL(slug)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(iconURL).To(Equal("https://example.com/logo.png"))
+		})
+
+		Context("when the product does not have a logo", func() {
+			It("returns an empty URL", func() {
+				server.AppendHandlers(
+					ghttp.CombineHandlers(
+						ghttp.VerifyRequest("GET", fmt.Sprintf("%s/products/%s", apiPrefix, slug)),
+						ghttp.RespondWith(http.StatusOK, `{"id": 3}`),
+					),
+				)
+
+				iconURL, err := client.Products.IconURL(slug)
+				Expect(err).NotTo(HaveOccurred())
+				Expect(iconURL).To(BeEmpty())
+			})
+		})
+
+		Context("when the server responds with a non-2XX status code", func() {
+			It("returns an error", func() {
+				server.AppendHandlers(
+					ghttp.CombineHandlers(
+						ghttp.VerifyRequest("GET", fmt.Sprintf("%s/products/%s", apiPrefix, slug)),
+						ghttp.RespondWith(http.StatusTeapot, `{"message":"foo message"}`),
+					),
+				)
+
+				_, err := client.Products.IconURL(slug)
+				Expect(err.Error()).To(ContainSubstring("foo message"))
+			})
+		})
+	})
+
 	Describe("List", func() {
 		var (
 			slug = "my-product"
@@ -185,4 +233,128 @@ var _ = Describe("PivnetClient - product", func() {
 			})
 		})
 	})
+
+	Describe("Exists", func() {
+		It("returns true when the product exists", func() {
+			server.AppendHandlers(
+				ghttp.CombineHandlers(
+					ghttp.VerifyRequest("GET", fmt.Sprintf("%s/products/my-product", apiPrefix)),
+					ghttp.RespondWith(http.StatusOK, `{"id": 3, "slug": "my-product"}`),
+				),
+			)
+
+			exists, err := client.Products.Exists("my-product")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(exists).To(BeTrue())
+
+			id, err := client.Products.SlugToID("my-product")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(id).To(Equal(3))
+
+			Expect(server.ReceivedRequests()).To(HaveLen(1))
+		})
+
+		It("returns false when the product is not found", func() {
+			server.AppendHandlers(
+				ghttp.RespondWith(http.StatusNotFound, `{"message":"not found"}`),
+			)
+
+			exists, err := client.Products.Exists("my-product")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(exists).To(BeFalse())
+		})
+
+		Context("when the request fails for another reason", func() {
+			It("returns the error", func() {
+				server.AppendHandlers(
+					ghttp.RespondWith(http.StatusTeapot, `{"message":"foo message"}`),
+				)
+
+				_, err := client.Products.Exists("my-product")
+				Expect(err).To(MatchError(ContainSubstring("foo message")))
+			})
+		})
+	})
+
+	Describe("SlugToID", func() {
+		var (
+			slug = "my-product"
+		)
+
+		It("returns the product ID and caches it", func() {
+			server.AppendHandlers(
+				ghttp.CombineHandlers(
+					ghttp.VerifyRequest("GET", fmt.Sprintf("%s/products/%s", apiPrefix, slug)),
+					ghttp.RespondWith(http.StatusOK, `{"id": 3, "slug": "my-product"}`),
+				),
+			)
+
+			id, err := client.Products.SlugToID(slug)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(id).To(Equal(3))
+
+			id, err = client.Products.SlugToID(slug)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(id).To(Equal(3))
+
+			Expect(server.ReceivedRequests()).To(HaveLen(1))
+		})
+
+		Context("when caching is disabled", func() {
+			BeforeEach(func() {
+				newClientConfig.ProductSlugCacheTTL = -1
+				client = pivnet.NewClient(newClientConfig, fakeLogger)
+			})
+
+			It("requests the product every time", func() {
+				for i := 0; i < 2; i++ {
+					server.AppendHandlers(
+						ghttp.CombineHandlers(
+							ghttp.VerifyRequest("GET", fmt.Sprintf("%s/products/%s", apiPrefix, slug)),
+							ghttp.RespondWith(http.StatusOK, `{"id": 3, "slug": "my-product"}`),
+						),
+					)
+				}
+
+				_, err := client.Products.SlugToID(slug)
+				Expect(err).NotTo(HaveOccurred())
+
+				_, err = client.Products.SlugToID(slug)
+				Expect(err).NotTo(HaveOccurred())
+
+				Expect(server.ReceivedRequests()).To(HaveLen(2))
+			})
+		})
+
+		Context("when the product cannot be found", func() {
+			It("returns an ErrNotFound naming the slug", func() {
+				server.AppendHandlers(
+					ghttp.CombineHandlers(
+						ghttp.VerifyRequest("GET", fmt.Sprintf("%s/products/%s", apiPrefix, slug)),
+						ghttp.RespondWith(http.StatusNotFound, `{"message":"not found"}`),
+					),
+				)
+
+				_, err := client.Products.SlugToID(slug)
+				Expect(err).To(MatchError(pivnet.ErrNotFound{
+					ResponseCode: http.StatusNotFound,
+					Message:      "Product 'my-product' not found",
+				}))
+			})
+		})
+
+		Context("when the request fails", func() {
+			It("forwards the error", func() {
+				server.AppendHandlers(
+					ghttp.CombineHandlers(
+						ghttp.VerifyRequest("GET", fmt.Sprintf("%s/products/%s", apiPrefix, slug)),
+						ghttp.RespondWith(http.StatusTeapot, `{"message":"foo message"}`),
+					),
+				)
+
+				_, err := client.Products.SlugToID(slug)
+				Expect(err.Error()).To(ContainSubstring("foo message"))
+			})
+		})
+	})
 })
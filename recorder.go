@@ -0,0 +1,225 @@
+package pivnet
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"sync"
+)
+
+const (
+	redactedToken     = "Token [REDACTED]"
+	redactedQueryItem = "REDACTED"
+)
+
+// signedURLSecrets are the query parameters of pre-signed storage URLs that
+// a Recorder redacts.
+var signedURLSecrets = []string{
+	"AWSAccessKeyId",
+	"Signature",
+	"X-Amz-Credential",
+	"X-Amz-Security-Token",
+	"X-Amz-Signature",
+	"X-Goog-Credential",
+	"X-Goog-Signature",
+}
+
+// Interaction is a request made by the client and the response it
+// received, as written by a Recorder.
+type Interaction struct {
+	Method          string      `json:"method"`
+	URL             string      `json:"url"`
+	RequestHeaders  http.Header `json:"request_headers,omitempty"`
+	RequestBody     string      `json:"request_body,omitempty"`
+	StatusCode      int         `json:"status_code"`
+	ResponseHeaders http.Header `json:"response_headers,omitempty"`
+
+	// ResponseBody is base64 encoded in JSON so that binary content, such
+	// as a downloaded product file, is recorded exactly.
+	ResponseBody []byte `json:"response_body,omitempty"`
+}
+
+// Recorder writes every request made by a client and its response to a
+// writer as newline-delimited JSON Interactions, with the API token and the
+// signatures and credentials in pre-signed download URLs redacted. The
+// output can be served back with a ReplayTransport.
+//
+// Responses are read into memory in full, so recording is meant for
+// building test fixtures rather than for large downloads.
+type Recorder struct {
+	mutex   sync.Mutex
+	encoder *json.Encoder
+}
+
+func NewRecorder(writer io.Writer) *Recorder {
+	return &Recorder{
+		encoder: json.NewEncoder(writer),
+	}
+}
+
+// record writes the interaction, replacing resp.Body with a reader over
+// the recorded body.
+func (r *Recorder) record(req *http.Request, resp *http.Response) error {
+	interaction := Interaction{
+		Method:          req.Method,
+		URL:             redactSignedURL(req.URL.String()),
+		RequestHeaders:  cloneHeader(req.Header),
+		StatusCode:      resp.StatusCode,
+		ResponseHeaders: cloneHeader(resp.Header),
+	}
+
+	if interaction.RequestHeaders.Get("Authorization") != "" {
+		interaction.RequestHeaders.Set("Authorization", redactedToken)
+	}
+
+	if location := interaction.ResponseHeaders.Get("Location"); location != "" {
+		interaction.ResponseHeaders.Set("Location", redactSignedURL(location))
+	}
+
+	if req.GetBody != nil {
+		body, err := req.GetBody()
+		if err != nil {
+			return err
+		}
+
+		b, err := ioutil.ReadAll(body)
+		body.Close()
+		if err != nil {
+			return err
+		}
+		interaction.RequestBody = string(b)
+	}
+
+	b, err := ioutil.ReadAll(resp.Body)
+	resp.Body.Close()
+	if err != nil {
+		return err
+	}
+	interaction.ResponseBody = b
+	resp.Body = ioutil.NopCloser(bytes.NewReader(b))
+
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	return r.encoder.Encode(interaction)
+}
+
+// redactSignedURL replaces the values of the signedURLSecrets in the query
+// of the URL. Other parameters, such as the expiry, are kept. Redaction is
+// deterministic, so a redacted Location header replays to the request
+// recorded for it.
+func redactSignedURL(rawURL string) string {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return rawURL
+	}
+
+	query := u.Query()
+	redacted := false
+	for _, name := range signedURLSecrets {
+		if _, ok := query[name]; ok {
+			query.Set(name, redactedQueryItem)
+			redacted = true
+		}
+	}
+
+	if !redacted {
+		return rawURL
+	}
+
+	u.RawQuery = query.Encode()
+	return u.String()
+}
+
+func cloneHeader(h http.Header) http.Header {
+	clone := make(http.Header, len(h))
+	for k, v := range h {
+		clone[k] = append([]string(nil), v...)
+	}
+	return clone
+}
+
+// ReplayTransport is an http.RoundTripper that serves responses recorded
+// by a Recorder instead of contacting a server. A request is matched to an
+// unused interaction with the same method, path and query; the host is
+// ignored so fixtures can be replayed against any ClientConfig.Host.
+// Identical requests are served in the order they were recorded.
+type ReplayTransport struct {
+	mutex        sync.Mutex
+	interactions []Interaction
+	used         []bool
+}
+
+// NewReplayTransport reads the interactions written by a Recorder.
+func NewReplayTransport(reader io.Reader) (*ReplayTransport, error) {
+	t := &ReplayTransport{}
+
+	scanner := bufio.NewScanner(reader)
+	scanner.Buffer(nil, 64*1024*1024)
+	for scanner.Scan() {
+		if len(bytes.TrimSpace(scanner.Bytes())) == 0 {
+			continue
+		}
+
+		var interaction Interaction
+		err := json.Unmarshal(scanner.Bytes(), &interaction)
+		if err != nil {
+			return nil, err
+		}
+
+		t.interactions = append(t.interactions, interaction)
+	}
+
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+
+	t.used = make([]bool, len(t.interactions))
+
+	return t, nil
+}
+
+func (t *ReplayTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	if req.Body != nil {
+		req.Body.Close()
+	}
+
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+
+	for i, interaction := range t.interactions {
+		if t.used[i] || interaction.Method != req.Method {
+			continue
+		}
+
+		u, err := url.Parse(interaction.URL)
+		if err != nil {
+			return nil, err
+		}
+
+		if u.RequestURI() != req.URL.RequestURI() {
+			continue
+		}
+
+		t.used[i] = true
+
+		return &http.Response{
+			Status:        fmt.Sprintf("%d %s", interaction.StatusCode, http.StatusText(interaction.StatusCode)),
+			StatusCode:    interaction.StatusCode,
+			Proto:         "HTTP/1.1",
+			ProtoMajor:    1,
+			ProtoMinor:    1,
+			Header:        cloneHeader(interaction.ResponseHeaders),
+			Body:          ioutil.NopCloser(bytes.NewReader(interaction.ResponseBody)),
+			ContentLength: int64(len(interaction.ResponseBody)),
+			Request:       req,
+		}, nil
+	}
+
+	return nil, fmt.Errorf("No recorded interaction for %s %s", req.Method, req.URL.RequestURI())
+}
@@ -0,0 +1,189 @@
+package pivnet_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"strings"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"github.com/onsi/gomega/ghttp"
+	"github.com/pivotal-cf/go-pivnet"
+	"github.com/pivotal-cf/go-pivnet/logger"
+	"github.com/pivotal-cf/go-pivnet/logger/loggerfakes"
+)
+
+var _ = Describe("PivnetClient - recording", func() {
+	var (
+		server     *ghttp.Server
+		fakeLogger logger.Logger
+
+		recording *bytes.Buffer
+	)
+
+	BeforeEach(func() {
+		server = ghttp.NewServer()
+		fakeLogger = &loggerfakes.FakeLogger{}
+
+		recording = bytes.NewBuffer(nil)
+	})
+
+	AfterEach(func() {
+		server.Close()
+	})
+
+	Describe("Recorder", func() {
+		It("records each interaction with the token redacted", func() {
+			server.AppendHandlers(
+				ghttp.CombineHandlers(
+					ghttp.VerifyRequest("PATCH", fmt.Sprintf("%s/products/%s/releases/%d", apiPrefix, productSlug, 12)),
+					ghttp.RespondWith(http.StatusOK, `{"release":{"id":12,"version":"1.2.3"}}`),
+				),
+			)
+
+			client := pivnet.NewClient(pivnet.ClientConfig{
+				Host:     server.URL(),
+				Token:    "my-auth-token",
+				Recorder: pivnet.NewRecorder(recording),
+			}, fakeLogger)
+
+			release, err := client.Releases.Update(productSlug, pivnet.Release{ID: 12})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(release.Version).To(Equal("1.2.3"))
+
+			Expect(recording.String()).NotTo(ContainSubstring("my-auth-token"))
+
+			var interaction pivnet.Interaction
+			Expect(json.Unmarshal(recording.Bytes(), &interaction)).To(Succeed())
+
+			Expect(interaction.Method).To(Equal("PATCH"))
+			Expect(interaction.URL).To(HaveSuffix("/api/v2/products/some-product-name/releases/12"))
+			Expect(interaction.RequestHeaders.Get("Authorization")).To(Equal("Token [REDACTED]"))
+			Expect(interaction.RequestBody).To(MatchJSON(`{"release":{"id":12,"oss_compliant":"confirm"}}`))
+			Expect(interaction.StatusCode).To(Equal(http.StatusOK))
+			Expect(interaction.ResponseBody).To(MatchJSON(`{"release":{"id":12,"version":"1.2.3"}}`))
+		})
+
+		Context("when a response redirects to a signed URL with a binary body", func() {
+			var (
+				signedURL string
+				body      []byte
+			)
+
+			BeforeEach(func() {
+				signedURL = server.URL() + "/storage/file?X-Amz-Date=20261017T000000Z&X-Amz-Expires=300&X-Amz-Signature=some-signature"
+				body = []byte{0xff, 0xfe, 0x00, 0x80}
+
+				server.AppendHandlers(
+					ghttp.CombineHandlers(
+						ghttp.VerifyRequest("GET", apiPrefix+"/download"),
+						ghttp.RespondWith(http.StatusFound, body, http.Header{"Location": []string{signedURL}}),
+					),
+				)
+			})
+
+			It("records the body exactly and redacts the signature", func() {
+				client := pivnet.NewClient(pivnet.ClientConfig{
+					Host:             server.URL(),
+					Token:            "my-auth-token",
+					DisableRedirects: true,
+					Recorder:         pivnet.NewRecorder(recording),
+				}, fakeLogger)
+
+				resp, err := client.MakeRequestExpecting("GET", "/download", nil, http.StatusFound)
+				Expect(err).NotTo(HaveOccurred())
+				resp.Body.Close()
+
+				Expect(recording.String()).NotTo(ContainSubstring("some-signature"))
+
+				var interaction pivnet.Interaction
+				Expect(json.Unmarshal(recording.Bytes(), &interaction)).To(Succeed())
+
+				Expect(interaction.ResponseBody).To(Equal(body))
+				Expect(interaction.ResponseHeaders.Get("Location")).To(ContainSubstring("X-Amz-Signature=REDACTED"))
+				Expect(interaction.ResponseHeaders.Get("Location")).To(ContainSubstring("X-Amz-Expires=300"))
+
+				transport, err := pivnet.NewReplayTransport(recording)
+				Expect(err).NotTo(HaveOccurred())
+
+				replayClient := pivnet.NewClient(pivnet.ClientConfig{
+					Host:             "https://pivnet.example.com",
+					Token:            "my-auth-token",
+					DisableRedirects: true,
+					Transport:        transport,
+				}, fakeLogger)
+
+				resp, err = replayClient.MakeRequestExpecting("GET", "/download", nil, http.StatusFound)
+				Expect(err).NotTo(HaveOccurred())
+				defer resp.Body.Close()
+
+				replayed, err := ioutil.ReadAll(resp.Body)
+				Expect(err).NotTo(HaveOccurred())
+				Expect(replayed).To(Equal(body))
+			})
+		})
+	})
+
+	Describe("ReplayTransport", func() {
+		var (
+			transport *pivnet.ReplayTransport
+			client    pivnet.Client
+		)
+
+		BeforeEach(func() {
+			server.AppendHandlers(
+				ghttp.RespondWith(http.StatusOK, `{"id":12,"version":"1.0.0"}`),
+				ghttp.RespondWith(http.StatusOK, `{"id":12,"version":"2.0.0"}`),
+			)
+
+			recordingClient := pivnet.NewClient(pivnet.ClientConfig{
+				Host:     server.URL(),
+				Token:    "my-auth-token",
+				Recorder: pivnet.NewRecorder(recording),
+			}, fakeLogger)
+
+			_, err := recordingClient.Releases.Get(productSlug, 12)
+			Expect(err).NotTo(HaveOccurred())
+			_, err = recordingClient.Releases.Get(productSlug, 12)
+			Expect(err).NotTo(HaveOccurred())
+
+			transport, err = pivnet.NewReplayTransport(recording)
+			Expect(err).NotTo(HaveOccurred())
+
+			client = pivnet.NewClient(pivnet.ClientConfig{
+				Host:      "https://pivnet.example.com",
+				Token:     "my-auth-token",
+				Transport: transport,
+			}, fakeLogger)
+		})
+
+		It("serves the recorded responses in order", func() {
+			release, err := client.Releases.Get(productSlug, 12)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(release.Version).To(Equal("1.0.0"))
+
+			release, err = client.Releases.Get(productSlug, 12)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(release.Version).To(Equal("2.0.0"))
+
+			Expect(server.ReceivedRequests()).To(HaveLen(2))
+		})
+
+		Context("when no recorded interaction matches", func() {
+			It("returns an error", func() {
+				_, err := client.Releases.Get(productSlug, 13)
+				Expect(err).To(MatchError(ContainSubstring("No recorded interaction")))
+			})
+		})
+
+		Context("when the recording is malformed", func() {
+			It("returns an error", func() {
+				_, err := pivnet.NewReplayTransport(strings.NewReader("not json"))
+				Expect(err).To(HaveOccurred())
+			})
+		})
+	})
+})
@@ -0,0 +1,153 @@
+package pivnet
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// ReleaseFilesDiff describes how the product files of a release B differ
+// from those of a release A. Files are matched by file name.
+type ReleaseFilesDiff struct {
+	// Added are the files of B whose name does not appear in A.
+	Added []ProductFile
+
+	// Removed are the files of A whose name does not appear in B.
+	Removed []ProductFile
+
+	// Changed are the files of B whose name appears in A with a different
+	// checksum.
+	Changed []ProductFile
+
+	// Common are the files of B whose name appears in A with the same
+	// checksum, or where either checksum is unknown.
+	Common []ProductFile
+}
+
+// DiffFiles compares the product files of two releases of the product,
+// fetching both file lists concurrently. Each list in the result is sorted
+// by file name.
+func (r ReleasesService) DiffFiles(
+	productSlug string,
+	releaseIDA int,
+	releaseIDB int,
+) (ReleaseFilesDiff, error) {
+	releaseIDs := []int{releaseIDA, releaseIDB}
+	productFiles := make([][]ProductFile, 2)
+	errs := make([]error, 2)
+
+	productFilesService := ProductFilesService{client: r.client}
+	forEachConcurrently(2, 2, func(i int) {
+		productFiles[i], errs[i] = productFilesService.ListForRelease(productSlug, releaseIDs[i])
+	})
+
+	for _, err := range errs {
+		if err != nil {
+			return ReleaseFilesDiff{}, err
+		}
+	}
+
+	return diffProductFiles(productFiles[0], productFiles[1]), nil
+}
+
+// DiffFilesByVersion is DiffFiles for the releases of the product with the
+// given versions, found as by GetByVersion. If either version does not
+// exist, it returns ErrNotFound naming the missing versions and listing the
+// available ones.
+func (r ReleasesService) DiffFilesByVersion(
+	productSlug string,
+	versionA string,
+	versionB string,
+) (ReleaseFilesDiff, error) {
+	releases, err := r.List(productSlug)
+	if err != nil {
+		return ReleaseFilesDiff{}, err
+	}
+
+	var releaseIDs []int
+	var missing []string
+	for _, version := range []string{versionA, versionB} {
+		release, ok := r.findReleaseByVersion(releases, version)
+		if !ok {
+			missing = append(missing, fmt.Sprintf("'%s'", version))
+			continue
+		}
+		releaseIDs = append(releaseIDs, release.ID)
+	}
+
+	if len(missing) > 0 {
+		noun := "Release"
+		if len(missing) > 1 {
+			noun = "Releases"
+		}
+
+		return ReleaseFilesDiff{}, newErrNotFound(fmt.Sprintf(
+			"%s %s not found for product '%s' - %s",
+			noun,
+			strings.Join(missing, " and "),
+			productSlug,
+			describeAvailableVersions(releases),
+		))
+	}
+
+	return r.DiffFiles(productSlug, releaseIDs[0], releaseIDs[1])
+}
+
+func diffProductFiles(a []ProductFile, b []ProductFile) ReleaseFilesDiff {
+	byNameA := map[string]ProductFile{}
+	for _, pf := range a {
+		byNameA[productFileName(pf)] = pf
+	}
+
+	byNameB := map[string]ProductFile{}
+	for _, pf := range b {
+		byNameB[productFileName(pf)] = pf
+	}
+
+	var diff ReleaseFilesDiff
+	for name, pf := range byNameB {
+		previous, ok := byNameA[name]
+		switch {
+		case !ok:
+			diff.Added = append(diff.Added, pf)
+		case checksumsDiffer(previous, pf):
+			diff.Changed = append(diff.Changed, pf)
+		default:
+			diff.Common = append(diff.Common, pf)
+		}
+	}
+
+	for name, pf := range byNameA {
+		if _, ok := byNameB[name]; !ok {
+			diff.Removed = append(diff.Removed, pf)
+		}
+	}
+
+	for _, files := range [][]ProductFile{diff.Added, diff.Removed, diff.Changed, diff.Common} {
+		sortProductFilesByName(files)
+	}
+
+	return diff
+}
+
+func checksumsDiffer(a ProductFile, b ProductFile) bool {
+	if a.SHA256 != "" && b.SHA256 != "" {
+		return !strings.EqualFold(a.SHA256, b.SHA256)
+	}
+
+	if a.SHA1 != "" && b.SHA1 != "" {
+		return !strings.EqualFold(a.SHA1, b.SHA1)
+	}
+
+	if a.MD5 != "" && b.MD5 != "" {
+		return !strings.EqualFold(a.MD5, b.MD5)
+	}
+
+	return false
+}
+
+func sortProductFilesByName(productFiles []ProductFile) {
+	sort.Slice(productFiles, func(i, j int) bool {
+		return productFileName(productFiles[i]) < productFileName(productFiles[j])
+	})
+}
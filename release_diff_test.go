@@ -0,0 +1,117 @@
+package pivnet_test
+
+import (
+	"fmt"
+	"net/http"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"github.com/onsi/gomega/ghttp"
+	"github.com/pivotal-cf/go-pivnet"
+	"github.com/pivotal-cf/go-pivnet/logger"
+	"github.com/pivotal-cf/go-pivnet/logger/loggerfakes"
+)
+
+var _ = Describe("PivnetClient - release diff", func() {
+	var (
+		server     *ghttp.Server
+		client     pivnet.Client
+		fakeLogger logger.Logger
+	)
+
+	BeforeEach(func() {
+		server = ghttp.NewServer()
+		fakeLogger = &loggerfakes.FakeLogger{}
+
+		client = pivnet.NewClient(pivnet.ClientConfig{
+			Host:      server.URL(),
+			Token:     "my-auth-token",
+			UserAgent: "pivnet-resource/0.1.0 (some-url)",
+		}, fakeLogger)
+	})
+
+	AfterEach(func() {
+		server.Close()
+	})
+
+	Describe("DiffFiles", func() {
+		productFilesURL := func(releaseID int) string {
+			return fmt.Sprintf("%s/products/%s/releases/%d/product_files", apiPrefix, productSlug, releaseID)
+		}
+
+		It("returns the added, removed, changed and common files", func() {
+			server.RouteToHandler("GET", productFilesURL(1), ghttp.RespondWith(http.StatusOK, `{"product_files":[
+				{"id":1,"aws_object_key":"product/same.tgz","sha256":"aaa"},
+				{"id":2,"aws_object_key":"product/changed.tgz","sha256":"bbb"},
+				{"id":3,"aws_object_key":"product/removed.tgz"}
+			]}`))
+			server.RouteToHandler("GET", productFilesURL(2), ghttp.RespondWith(http.StatusOK, `{"product_files":[
+				{"id":11,"aws_object_key":"product/same.tgz","sha256":"AAA"},
+				{"id":12,"aws_object_key":"product/changed.tgz","sha256":"ccc"},
+				{"id":13,"aws_object_key":"product/added.tgz"}
+			]}`))
+
+			diff, err := client.Releases.DiffFiles(productSlug, 1, 2)
+			Expect(err).NotTo(HaveOccurred())
+
+			ids := func(productFiles []pivnet.ProductFile) []int {
+				var ids []int
+				for _, pf := range productFiles {
+					ids = append(ids, pf.ID)
+				}
+				return ids
+			}
+
+			Expect(ids(diff.Added)).To(Equal([]int{13}))
+			Expect(ids(diff.Removed)).To(Equal([]int{3}))
+			Expect(ids(diff.Changed)).To(Equal([]int{12}))
+			Expect(ids(diff.Common)).To(Equal([]int{11}))
+		})
+
+		Context("when listing the files of a release returns an error", func() {
+			It("returns the error", func() {
+				server.RouteToHandler("GET", productFilesURL(1), ghttp.RespondWith(http.StatusOK, `{"product_files":[]}`))
+				server.RouteToHandler("GET", productFilesURL(2), ghttp.RespondWith(http.StatusTeapot, `{"message":"foo message"}`))
+
+				_, err := client.Releases.DiffFiles(productSlug, 1, 2)
+				Expect(err).To(MatchError(ContainSubstring("foo message")))
+			})
+		})
+	})
+
+	Describe("DiffFilesByVersion", func() {
+		BeforeEach(func() {
+			server.RouteToHandler("GET", fmt.Sprintf("%s/products/%s/releases", apiPrefix, productSlug),
+				ghttp.RespondWith(http.StatusOK, `{"releases":[{"id":2,"version":"1.1.0"},{"id":1,"version":"1.0.0"}]}`),
+			)
+			server.RouteToHandler("GET", fmt.Sprintf("%s/products/%s/releases/1/product_files", apiPrefix, productSlug),
+				ghttp.RespondWith(http.StatusOK, `{"product_files":[{"id":1,"aws_object_key":"product/removed.tgz"}]}`),
+			)
+			server.RouteToHandler("GET", fmt.Sprintf("%s/products/%s/releases/2/product_files", apiPrefix, productSlug),
+				ghttp.RespondWith(http.StatusOK, `{"product_files":[{"id":11,"aws_object_key":"product/added.tgz"}]}`),
+			)
+		})
+
+		It("diffs the files of the releases with the versions", func() {
+			diff, err := client.Releases.DiffFilesByVersion(productSlug, "1.0.0", "1.1.0")
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(diff.Added).To(HaveLen(1))
+			Expect(diff.Added[0].ID).To(Equal(11))
+			Expect(diff.Removed).To(HaveLen(1))
+			Expect(diff.Removed[0].ID).To(Equal(1))
+		})
+
+		Context("when a version does not exist", func() {
+			It("returns ErrNotFound naming the missing versions", func() {
+				_, err := client.Releases.DiffFilesByVersion(productSlug, "0.9.0", "1.1.0")
+				Expect(err).To(BeAssignableToTypeOf(pivnet.ErrNotFound{}))
+				Expect(err).To(MatchError(ContainSubstring("Release '0.9.0' not found")))
+				Expect(err).To(MatchError(ContainSubstring("available versions: '1.1.0', '1.0.0'")))
+
+				_, err = client.Releases.DiffFilesByVersion(productSlug, "0.9.0", "3.0.0")
+				Expect(err).To(MatchError(ContainSubstring("Releases '0.9.0' and '3.0.0' not found")))
+			})
+		})
+	})
+})
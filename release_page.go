@@ -0,0 +1,111 @@
+package pivnet
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+)
+
+// ReleasePage is everything needed to display a release.
+type ReleasePage struct {
+	Product      Product
+	Release      Release
+	ProductFiles []ProductFile
+}
+
+// ReleasePageError reports the sections of a ReleasePage that could not be
+// fetched. A nil field means that section was fetched.
+type ReleasePageError struct {
+	Product      error
+	Release      error
+	ProductFiles error
+}
+
+func (e ReleasePageError) Error() string {
+	var problems []string
+	for _, section := range []struct {
+		name string
+		err  error
+	}{
+		{"product", e.Product},
+		{"release", e.Release},
+		{"product files", e.ProductFiles},
+	} {
+		if section.err != nil {
+			problems = append(problems, fmt.Sprintf("%s: %s", section.name, section.err.Error()))
+		}
+	}
+
+	return fmt.Sprintf("Failed to fetch release page - %s", strings.Join(problems, "; "))
+}
+
+// Unwrap returns the error of each section that failed, so that errors.Is
+// and errors.As match against them.
+func (e ReleasePageError) Unwrap() []error {
+	var errs []error
+	for _, err := range []error{e.Product, e.Release, e.ProductFiles} {
+		if err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errs
+}
+
+// ReleasePage fetches the product, the release with the version and the
+// release's product files. The product is fetched concurrently with the
+// release, and the product files once the release has been found.
+//
+// If any section fails, ReleasePage returns the sections that succeeded
+// together with a ReleasePageError. Once ctx is done requests in flight are
+// aborted, no further requests are started, and the sections not yet fetched
+// fail with ctx.Err().
+func (c Client) ReleasePage(ctx context.Context, productSlug string, version string) (ReleasePage, error) {
+	c = c.WithContext(ctx)
+
+	var page ReleasePage
+	var pageErr ReleasePageError
+
+	var wg sync.WaitGroup
+	wg.Add(2)
+
+	go func() {
+		defer wg.Done()
+
+		if pageErr.Product = ctx.Err(); pageErr.Product != nil {
+			return
+		}
+
+		page.Product, pageErr.Product = c.Products.Get(productSlug)
+	}()
+
+	go func() {
+		defer wg.Done()
+
+		if err := ctx.Err(); err != nil {
+			pageErr.Release = err
+			pageErr.ProductFiles = err
+			return
+		}
+
+		page.Release, pageErr.Release = c.Releases.GetByVersion(productSlug, version)
+		if pageErr.Release != nil {
+			pageErr.ProductFiles = fmt.Errorf("Release '%s' could not be fetched", version)
+			return
+		}
+
+		if pageErr.ProductFiles = ctx.Err(); pageErr.ProductFiles != nil {
+			return
+		}
+
+		page.ProductFiles, pageErr.ProductFiles = c.ProductFiles.ListForRelease(productSlug, page.Release.ID)
+	}()
+
+	wg.Wait()
+
+	if pageErr.Product != nil || pageErr.Release != nil || pageErr.ProductFiles != nil {
+		return page, pageErr
+	}
+
+	return page, nil
+}
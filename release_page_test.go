@@ -0,0 +1,128 @@
+package pivnet_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"github.com/onsi/gomega/ghttp"
+	"github.com/pivotal-cf/go-pivnet"
+	"github.com/pivotal-cf/go-pivnet/logger/loggerfakes"
+)
+
+var _ = Describe("PivnetClient - release page", func() {
+	var (
+		server *ghttp.Server
+		client pivnet.Client
+	)
+
+	BeforeEach(func() {
+		server = ghttp.NewServer()
+		client = pivnet.NewClient(pivnet.ClientConfig{
+			Host:      server.URL(),
+			Token:     "my-auth-token",
+			UserAgent: "pivnet-resource/0.1.0 (some-url)",
+		}, &loggerfakes.FakeLogger{})
+
+		server.RouteToHandler("GET", fmt.Sprintf("%s/products/banana", apiPrefix),
+			ghttp.RespondWith(http.StatusOK, `{"id": 1, "slug": "banana"}`),
+		)
+		server.RouteToHandler("GET", fmt.Sprintf("%s/products/banana/releases", apiPrefix),
+			ghttp.RespondWith(http.StatusOK, `{"releases": [{"id": 2, "version": "1.2.3"}, {"id": 3, "version": "2.0.0"}]}`),
+		)
+		server.RouteToHandler("GET", fmt.Sprintf("%s/products/banana/releases/2/product_files", apiPrefix),
+			ghttp.RespondWith(http.StatusOK, `{"product_files": [{"id": 4}, {"id": 5}]}`),
+		)
+	})
+
+	AfterEach(func() {
+		server.Close()
+	})
+
+	It("returns the product, the release and its product files", func() {
+		page, err := client.ReleasePage(context.Background(), "banana", "1.2.3")
+		Expect(err).NotTo(HaveOccurred())
+
+		Expect(page.Product.ID).To(Equal(1))
+		Expect(page.Release.ID).To(Equal(2))
+		Expect(page.ProductFiles).To(Equal([]pivnet.ProductFile{{ID: 4}, {ID: 5}}))
+	})
+
+	Context("when the product cannot be fetched", func() {
+		BeforeEach(func() {
+			server.RouteToHandler("GET", fmt.Sprintf("%s/products/banana", apiPrefix),
+				ghttp.RespondWith(http.StatusTeapot, `{"message": "foo message"}`),
+			)
+		})
+
+		It("returns the other sections with the error", func() {
+			page, err := client.ReleasePage(context.Background(), "banana", "1.2.3")
+			Expect(err).To(MatchError(ContainSubstring("product: 418 - foo message")))
+
+			var pageErr pivnet.ReleasePageError
+			Expect(errors.As(err, &pageErr)).To(BeTrue())
+			Expect(pageErr.Product).To(HaveOccurred())
+			Expect(pageErr.Release).NotTo(HaveOccurred())
+			Expect(pageErr.ProductFiles).NotTo(HaveOccurred())
+
+			Expect(page.Release.ID).To(Equal(2))
+			Expect(page.ProductFiles).To(HaveLen(2))
+		})
+	})
+
+	Context("when the release cannot be found", func() {
+		It("returns the product and does not fetch the product files", func() {
+			page, err := client.ReleasePage(context.Background(), "banana", "9.9.9")
+			Expect(errors.Is(err, pivnet.ErrNotFound{})).To(BeTrue())
+
+			var pageErr pivnet.ReleasePageError
+			Expect(errors.As(err, &pageErr)).To(BeTrue())
+			Expect(pageErr.Product).NotTo(HaveOccurred())
+			Expect(pageErr.Release).To(HaveOccurred())
+			Expect(pageErr.ProductFiles).To(MatchError("Release '9.9.9' could not be fetched"))
+
+			Expect(page.Product.ID).To(Equal(1))
+			Expect(server.ReceivedRequests()).To(HaveLen(2))
+		})
+	})
+
+	Context("when the context is cancelled", func() {
+		It("does not make any requests", func() {
+			ctx, cancel := context.WithCancel(context.Background())
+			cancel()
+
+			_, err := client.ReleasePage(ctx, "banana", "1.2.3")
+			Expect(errors.Is(err, context.Canceled)).To(BeTrue())
+
+			pageErr := err.(pivnet.ReleasePageError)
+			Expect(pageErr.Product).To(Equal(context.Canceled))
+			Expect(pageErr.Release).To(Equal(context.Canceled))
+			Expect(pageErr.ProductFiles).To(Equal(context.Canceled))
+
+			Expect(server.ReceivedRequests()).To(BeEmpty())
+		})
+
+		Context("while a request is in flight", func() {
+			It("aborts the request", func() {
+				ctx, cancel := context.WithCancel(context.Background())
+				defer cancel()
+
+				server.RouteToHandler("GET", fmt.Sprintf("%s/products/banana/releases/2/product_files", apiPrefix),
+					func(w http.ResponseWriter, r *http.Request) {
+						cancel()
+						<-r.Context().Done()
+					},
+				)
+
+				page, err := client.ReleasePage(ctx, "banana", "1.2.3")
+				Expect(page.Release.ID).To(Equal(2))
+
+				pageErr := err.(pivnet.ReleasePageError)
+				Expect(errors.Is(pageErr.ProductFiles, context.Canceled)).To(BeTrue())
+			})
+		})
+	})
+})
@@ -0,0 +1,302 @@
+package pivnet
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/pivotal-cf/go-pivnet/logger"
+	yaml "gopkg.in/yaml.v2"
+)
+
+const ReleaseSpecVersion = 1
+
+type ReleaseSpec struct {
+	SpecVersion           int    `json:"spec_version" yaml:"spec_version"`
+	Version               string `json:"version" yaml:"version"`
+	ReleaseType           string `json:"release_type,omitempty" yaml:"release_type,omitempty"`
+	Availability          string `json:"availability,omitempty" yaml:"availability,omitempty"`
+	EULASlug              string `json:"eula_slug,omitempty" yaml:"eula_slug,omitempty"`
+	ReleaseDate           string `json:"release_date,omitempty" yaml:"release_date,omitempty"`
+	Description           string `json:"description,omitempty" yaml:"description,omitempty"`
+	ReleaseNotesURL       string `json:"release_notes_url,omitempty" yaml:"release_notes_url,omitempty"`
+	Controlled            bool   `json:"controlled,omitempty" yaml:"controlled,omitempty"`
+	ECCN                  string `json:"eccn,omitempty" yaml:"eccn,omitempty"`
+	LicenseException      string `json:"license_exception,omitempty" yaml:"license_exception,omitempty"`
+	EndOfSupportDate      string `json:"end_of_support_date,omitempty" yaml:"end_of_support_date,omitempty"`
+	EndOfGuidanceDate     string `json:"end_of_guidance_date,omitempty" yaml:"end_of_guidance_date,omitempty"`
+	EndOfAvailabilityDate string `json:"end_of_availability_date,omitempty" yaml:"end_of_availability_date,omitempty"`
+
+	// ProductFileIDs are the files the release should contain. A spec
+	// without product_file_ids leaves the files of the release alone when
+	// applied; an explicitly empty list removes them all.
+	ProductFileIDs []int `json:"product_file_ids" yaml:"product_file_ids"`
+}
+
+// Marshal renders the spec as YAML. As YAML is a superset of JSON,
+// UnmarshalReleaseSpec accepts either format. An empty ProductFileIDs is
+// written as an empty list, while a nil one is left out.
+func (s ReleaseSpec) Marshal() ([]byte, error) {
+	if s.SpecVersion == 0 {
+		s.SpecVersion = ReleaseSpecVersion
+	}
+
+	b, err := yaml.Marshal(s)
+	if err != nil || s.ProductFileIDs != nil {
+		return b, err
+	}
+
+	// YAML writes a nil list as [], which would remove every product file
+	// when applied, so product_file_ids is dropped instead.
+	var fields yaml.MapSlice
+	err = yaml.Unmarshal(b, &fields)
+	if err != nil {
+		return nil, err
+	}
+
+	kept := yaml.MapSlice{}
+	for _, field := range fields {
+		if field.Key != "product_file_ids" {
+			kept = append(kept, field)
+		}
+	}
+
+	return yaml.Marshal(kept)
+}
+
+func UnmarshalReleaseSpec(b []byte) (ReleaseSpec, error) {
+	var spec ReleaseSpec
+	err := yaml.Unmarshal(b, &spec)
+	if err != nil {
+		return ReleaseSpec{}, err
+	}
+
+	if spec.SpecVersion != ReleaseSpecVersion {
+		return ReleaseSpec{}, fmt.Errorf(
+			"Unsupported release spec version: %d (expected %d)",
+			spec.SpecVersion,
+			ReleaseSpecVersion,
+		)
+	}
+
+	return spec, nil
+}
+
+func (r ReleasesService) Export(productSlug string, releaseID int) (ReleaseSpec, error) {
+	release, err := r.Get(productSlug, releaseID)
+	if err != nil {
+		return ReleaseSpec{}, err
+	}
+
+	productFiles, err := ProductFilesService{client: r.client}.ListForRelease(productSlug, releaseID)
+	if err != nil {
+		return ReleaseSpec{}, err
+	}
+
+	spec := releaseToSpec(release)
+	spec.ProductFileIDs = []int{}
+	for _, pf := range productFiles {
+		spec.ProductFileIDs = append(spec.ProductFileIDs, pf.ID)
+	}
+	sort.Ints(spec.ProductFileIDs)
+
+	return spec, nil
+}
+
+// Apply creates the release described by the spec if no release with the
+// same version exists, otherwise it updates only the fields that differ.
+// Product files are then added or removed so that the release contains
+// exactly the files referenced by the spec, unless spec.ProductFileIDs is
+// nil.
+//
+// As Pivnet ignores omitted fields, Apply cannot clear a field that is set
+// on the existing release.
+func (r ReleasesService) Apply(productSlug string, spec ReleaseSpec) (Release, error) {
+	if spec.Version == "" {
+		return Release{}, fmt.Errorf("Release spec version must not be empty")
+	}
+
+	if spec.Availability != "" {
+		err := validateAvailability(spec.Availability)
+		if err != nil {
+			return Release{}, err
+		}
+	}
+
+	releases, err := r.List(productSlug)
+	if err != nil {
+		return Release{}, err
+	}
+
+	var current *Release
+	for i := range releases {
+		if releases[i].Version == spec.Version {
+			current = &releases[i]
+			break
+		}
+	}
+
+	var release Release
+	if current == nil {
+		release, err = r.Create(CreateReleaseConfig{
+			ProductSlug:           productSlug,
+			Version:               spec.Version,
+			ReleaseType:           spec.ReleaseType,
+			ReleaseDate:           spec.ReleaseDate,
+			EULASlug:              spec.EULASlug,
+			Description:           spec.Description,
+			ReleaseNotesURL:       spec.ReleaseNotesURL,
+			Controlled:            spec.Controlled,
+			ECCN:                  spec.ECCN,
+			LicenseException:      spec.LicenseException,
+			EndOfSupportDate:      spec.EndOfSupportDate,
+			EndOfGuidanceDate:     spec.EndOfGuidanceDate,
+			EndOfAvailabilityDate: spec.EndOfAvailabilityDate,
+		})
+		if err != nil {
+			return Release{}, err
+		}
+
+		if spec.Availability != "" && spec.Availability != release.Availability {
+			release, err = r.Update(productSlug, Release{
+				ID:           release.ID,
+				Availability: spec.Availability,
+			})
+			if err != nil {
+				return Release{}, err
+			}
+		}
+	} else {
+		release, err = r.Get(productSlug, current.ID)
+		if err != nil {
+			return Release{}, err
+		}
+
+		changes, changed := diffReleaseSpec(releaseToSpec(release), spec)
+		if changed {
+			changes.ID = release.ID
+			r.l.Debug(
+				"Updating release to match spec",
+				logger.Data{"version": spec.Version},
+			)
+
+			release, err = r.Update(productSlug, changes)
+			if err != nil {
+				return Release{}, err
+			}
+		}
+	}
+
+	if spec.ProductFileIDs != nil {
+		err = r.applyProductFiles(productSlug, release.ID, spec.ProductFileIDs)
+		if err != nil {
+			return Release{}, err
+		}
+	}
+
+	return release, nil
+}
+
+func (r ReleasesService) applyProductFiles(productSlug string, releaseID int, productFileIDs []int) error {
+	productFilesService := ProductFilesService{client: r.client}
+
+	productFiles, err := productFilesService.ListForRelease(productSlug, releaseID)
+	if err != nil {
+		return err
+	}
+
+	existing := map[int]bool{}
+	for _, pf := range productFiles {
+		existing[pf.ID] = true
+	}
+
+	desired := map[int]bool{}
+	for _, id := range productFileIDs {
+		desired[id] = true
+
+		if !existing[id] {
+			err := productFilesService.AddToRelease(productSlug, releaseID, id)
+			if err != nil {
+				return err
+			}
+		}
+	}
+
+	for _, pf := range productFiles {
+		if !desired[pf.ID] {
+			err := productFilesService.RemoveFromRelease(productSlug, releaseID, pf.ID)
+			if err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
+
+func releaseToSpec(release Release) ReleaseSpec {
+	spec := ReleaseSpec{
+		SpecVersion:           ReleaseSpecVersion,
+		Version:               release.Version,
+		ReleaseType:           string(release.ReleaseType),
+		Availability:          release.Availability,
+		ReleaseDate:           release.ReleaseDate,
+		Description:           release.Description,
+		ReleaseNotesURL:       release.ReleaseNotesURL,
+		Controlled:            release.Controlled,
+		ECCN:                  release.ECCN,
+		LicenseException:      release.LicenseException,
+		EndOfSupportDate:      release.EndOfSupportDate,
+		EndOfGuidanceDate:     release.EndOfGuidanceDate,
+		EndOfAvailabilityDate: release.EndOfAvailabilityDate,
+	}
+
+	if release.EULA != nil {
+		spec.EULASlug = release.EULA.Slug
+	}
+
+	return spec
+}
+
+// diffReleaseSpec returns a release containing only the fields of desired
+// that are set and differ from current.
+func diffReleaseSpec(current ReleaseSpec, desired ReleaseSpec) (Release, bool) {
+	var changes Release
+	changed := false
+
+	setString := func(dst *string, have string, want string) {
+		if want != "" && want != have {
+			*dst = want
+			changed = true
+		}
+	}
+
+	var releaseType string
+	setString(&releaseType, current.ReleaseType, desired.ReleaseType)
+	changes.ReleaseType = ReleaseType(releaseType)
+
+	setString(&changes.Availability, current.Availability, desired.Availability)
+	setString(&changes.ReleaseDate, current.ReleaseDate, desired.ReleaseDate)
+	setString(&changes.Description, current.Description, desired.Description)
+	setString(&changes.ReleaseNotesURL, current.ReleaseNotesURL, desired.ReleaseNotesURL)
+	setString(&changes.ECCN, current.ECCN, desired.ECCN)
+	setString(&changes.LicenseException, current.LicenseException, desired.LicenseException)
+	setString(&changes.EndOfSupportDate, current.EndOfSupportDate, desired.EndOfSupportDate)
+	setString(&changes.EndOfGuidanceDate, current.EndOfGuidanceDate, desired.EndOfGuidanceDate)
+	setString(&changes.EndOfAvailabilityDate, current.EndOfAvailabilityDate, desired.EndOfAvailabilityDate)
+
+	// The ECCN and license exception must be sent with the flag, even if
+	// they already match.
+	if desired.Controlled && !current.Controlled {
+		changes.Controlled = true
+		changes.ECCN = desired.ECCN
+		changes.LicenseException = desired.LicenseException
+		changed = true
+	}
+
+	if desired.EULASlug != "" && desired.EULASlug != current.EULASlug {
+		changes.EULA = &EULA{Slug: desired.EULASlug}
+		changed = true
+	}
+
+	return changes, changed
+}
@@ -0,0 +1,389 @@
+package pivnet_test
+
+import (
+	"fmt"
+	"net/http"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"github.com/onsi/gomega/ghttp"
+	"github.com/pivotal-cf/go-pivnet"
+	"github.com/pivotal-cf/go-pivnet/logger"
+	"github.com/pivotal-cf/go-pivnet/logger/loggerfakes"
+)
+
+var _ = Describe("PivnetClient - release specs", func() {
+	var (
+		server     *ghttp.Server
+		client     pivnet.Client
+		token      string
+		apiAddress string
+		userAgent  string
+
+		newClientConfig pivnet.ClientConfig
+		fakeLogger      logger.Logger
+
+		releasesURL     string
+		releaseURL      string
+		productFilesURL string
+	)
+
+	BeforeEach(func() {
+		server = ghttp.NewServer()
+		apiAddress = server.URL()
+		token = "my-auth-token"
+		userAgent = "pivnet-resource/0.1.0 (some-url)"
+
+		fakeLogger = &loggerfakes.FakeLogger{}
+		newClientConfig = pivnet.ClientConfig{
+			Host:      apiAddress,
+			Token:     token,
+			UserAgent: userAgent,
+		}
+		client = pivnet.NewClient(newClientConfig, fakeLogger)
+
+		releasesURL = fmt.Sprintf("%s/products/%s/releases", apiPrefix, productSlug)
+		releaseURL = fmt.Sprintf("%s/products/%s/releases/%d", apiPrefix, productSlug, 3)
+		productFilesURL = fmt.Sprintf("%s/products/%s/releases/%d/product_files", apiPrefix, productSlug, 3)
+	})
+
+	AfterEach(func() {
+		server.Close()
+	})
+
+	Describe("Marshal and UnmarshalReleaseSpec", func() {
+		It("round-trips the spec", func() {
+			spec := pivnet.ReleaseSpec{
+				Version:        "1.2.3",
+				ReleaseType:    "Minor Release",
+				EULASlug:       "some-eula",
+				ProductFileIDs: []int{1, 2},
+			}
+
+			b, err := spec.Marshal()
+			Expect(err).NotTo(HaveOccurred())
+
+			unmarshalled, err := pivnet.UnmarshalReleaseSpec(b)
+			Expect(err).NotTo(HaveOccurred())
+
+			spec.SpecVersion = pivnet.ReleaseSpecVersion
+			Expect(unmarshalled).To(Equal(spec))
+		})
+
+		It("round-trips an empty list of product files", func() {
+			b, err := pivnet.ReleaseSpec{Version: "1.2.3", ProductFileIDs: []int{}}.Marshal()
+			Expect(err).NotTo(HaveOccurred())
+
+			unmarshalled, err := pivnet.UnmarshalReleaseSpec(b)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(unmarshalled.ProductFileIDs).To(Equal([]int{}))
+		})
+
+		It("leaves out a nil list of product files", func() {
+			b, err := pivnet.ReleaseSpec{Version: "1.2.3"}.Marshal()
+			Expect(err).NotTo(HaveOccurred())
+			Expect(string(b)).NotTo(ContainSubstring("product_file_ids"))
+
+			unmarshalled, err := pivnet.UnmarshalReleaseSpec(b)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(unmarshalled.ProductFileIDs).To(BeNil())
+		})
+
+		It("accepts JSON", func() {
+			spec, err := pivnet.UnmarshalReleaseSpec([]byte(`{"spec_version":1,"version":"1.2.3"}`))
+			Expect(err).NotTo(HaveOccurred())
+			Expect(spec.Version).To(Equal("1.2.3"))
+		})
+
+		Context("when the spec version is not supported", func() {
+			It("returns an error", func() {
+				_, err := pivnet.UnmarshalReleaseSpec([]byte(`{"spec_version":99,"version":"1.2.3"}`))
+				Expect(err).To(HaveOccurred())
+				Expect(err.Error()).To(ContainSubstring("Unsupported release spec version"))
+			})
+		})
+	})
+
+	Describe("Export", func() {
+		It("returns the spec for the release", func() {
+			server.AppendHandlers(
+				ghttp.CombineHandlers(
+					ghttp.VerifyRequest("GET", releaseURL),
+					ghttp.RespondWith(http.StatusOK, `{"id":3,"version":"1.2.3","release_type":"Minor Release","eula":{"slug":"some-eula"}}`),
+				),
+				ghttp.CombineHandlers(
+					ghttp.VerifyRequest("GET", productFilesURL),
+					ghttp.RespondWith(http.StatusOK, `{"product_files":[{"id":9},{"id":4}]}`),
+				),
+			)
+
+			spec, err := client.Releases.Export(productSlug, 3)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(spec).To(Equal(pivnet.ReleaseSpec{
+				SpecVersion:    pivnet.ReleaseSpecVersion,
+				Version:        "1.2.3",
+				ReleaseType:    "Minor Release",
+				EULASlug:       "some-eula",
+				ProductFileIDs: []int{4, 9},
+			}))
+		})
+
+		Context("when getting the release returns an error", func() {
+			It("forwards the error", func() {
+				server.AppendHandlers(
+					ghttp.CombineHandlers(
+						ghttp.VerifyRequest("GET", releaseURL),
+						ghttp.RespondWith(http.StatusTeapot, `{"message":"foo message"}`),
+					),
+				)
+
+				_, err := client.Releases.Export(productSlug, 3)
+				Expect(err.Error()).To(ContainSubstring("foo message"))
+			})
+		})
+	})
+
+	Describe("Apply", func() {
+		var (
+			spec pivnet.ReleaseSpec
+		)
+
+		BeforeEach(func() {
+			spec = pivnet.ReleaseSpec{
+				SpecVersion:    pivnet.ReleaseSpecVersion,
+				Version:        "1.2.3",
+				ReleaseType:    "Minor Release",
+				ReleaseDate:    "2016-01-01",
+				EULASlug:       "some-eula",
+				Description:    "new description",
+				ProductFileIDs: []int{4, 5},
+			}
+		})
+
+		Context("when the release does not exist", func() {
+			It("creates the release and adds the product files", func() {
+				server.AppendHandlers(
+					ghttp.CombineHandlers(
+						ghttp.VerifyRequest("GET", releasesURL),
+						ghttp.RespondWith(http.StatusOK, `{"releases":[{"id":1,"version":"1.0.0"}]}`),
+					),
+					ghttp.CombineHandlers(
+						ghttp.VerifyRequest("POST", releasesURL),
+						ghttp.RespondWith(http.StatusCreated, `{"release":{"id":3,"version":"1.2.3","availability":"Admins Only"}}`),
+					),
+					ghttp.CombineHandlers(
+						ghttp.VerifyRequest("GET", productFilesURL),
+						ghttp.RespondWith(http.StatusOK, `{"product_files":[]}`),
+					),
+					ghttp.CombineHandlers(
+						ghttp.VerifyRequest("PATCH", releaseURL+"/add_product_file"),
+						ghttp.VerifyJSON(`{"product_file":{"id":4}}`),
+						ghttp.RespondWith(http.StatusNoContent, nil),
+					),
+					ghttp.CombineHandlers(
+						ghttp.VerifyRequest("PATCH", releaseURL+"/add_product_file"),
+						ghttp.VerifyJSON(`{"product_file":{"id":5}}`),
+						ghttp.RespondWith(http.StatusNoContent, nil),
+					),
+				)
+
+				release, err := client.Releases.Apply(productSlug, spec)
+				Expect(err).NotTo(HaveOccurred())
+				Expect(release.ID).To(Equal(3))
+			})
+		})
+
+		Context("when the release exists", func() {
+			It("updates only the differing fields and reconciles product files", func() {
+				server.AppendHandlers(
+					ghttp.CombineHandlers(
+						ghttp.VerifyRequest("GET", releasesURL),
+						ghttp.RespondWith(http.StatusOK, `{"releases":[{"id":3,"version":"1.2.3"}]}`),
+					),
+					ghttp.CombineHandlers(
+						ghttp.VerifyRequest("GET", releaseURL),
+						ghttp.RespondWith(http.StatusOK, `{"id":3,"version":"1.2.3","release_type":"Minor Release","release_date":"2016-01-01","eula":{"slug":"some-eula"},"description":"old description"}`),
+					),
+					ghttp.CombineHandlers(
+						ghttp.VerifyRequest("PATCH", releaseURL),
+						ghttp.VerifyJSON(`{"release":{"id":3,"description":"new description","oss_compliant":"confirm"}}`),
+						ghttp.RespondWith(http.StatusOK, `{"release":{"id":3,"version":"1.2.3","description":"new description"}}`),
+					),
+					ghttp.CombineHandlers(
+						ghttp.VerifyRequest("GET", productFilesURL),
+						ghttp.RespondWith(http.StatusOK, `{"product_files":[{"id":4},{"id":6}]}`),
+					),
+					ghttp.CombineHandlers(
+						ghttp.VerifyRequest("PATCH", releaseURL+"/add_product_file"),
+						ghttp.VerifyJSON(`{"product_file":{"id":5}}`),
+						ghttp.RespondWith(http.StatusNoContent, nil),
+					),
+					ghttp.CombineHandlers(
+						ghttp.VerifyRequest("PATCH", releaseURL+"/remove_product_file"),
+						ghttp.VerifyJSON(`{"product_file":{"id":6}}`),
+						ghttp.RespondWith(http.StatusNoContent, nil),
+					),
+				)
+
+				release, err := client.Releases.Apply(productSlug, spec)
+				Expect(err).NotTo(HaveOccurred())
+				Expect(release.Description).To(Equal("new description"))
+			})
+
+			Context("when the spec turns on export control", func() {
+				BeforeEach(func() {
+					spec.Controlled = true
+					spec.ECCN = "5D002"
+					spec.LicenseException = "ENC Unrestricted"
+					spec.ProductFileIDs = nil
+				})
+
+				It("sends the ECCN and license exception with the flag", func() {
+					server.AppendHandlers(
+						ghttp.CombineHandlers(
+							ghttp.VerifyRequest("GET", releasesURL),
+							ghttp.RespondWith(http.StatusOK, `{"releases":[{"id":3,"version":"1.2.3"}]}`),
+						),
+						ghttp.CombineHandlers(
+							ghttp.VerifyRequest("GET", releaseURL),
+							ghttp.RespondWith(http.StatusOK, `{"id":3,"version":"1.2.3","release_type":"Minor Release","release_date":"2016-01-01","eula":{"slug":"some-eula"},"description":"new description","eccn":"5D002","license_exception":"ENC Unrestricted"}`),
+						),
+						ghttp.CombineHandlers(
+							ghttp.VerifyRequest("PATCH", releaseURL),
+							ghttp.VerifyJSON(`{"release":{"id":3,"controlled":true,"eccn":"5D002","license_exception":"ENC Unrestricted","oss_compliant":"confirm"}}`),
+							ghttp.RespondWith(http.StatusOK, `{"release":{"id":3,"version":"1.2.3","controlled":true}}`),
+						),
+					)
+
+					release, err := client.Releases.Apply(productSlug, spec)
+					Expect(err).NotTo(HaveOccurred())
+					Expect(release.Controlled).To(BeTrue())
+				})
+			})
+
+			Context("when nothing differs", func() {
+				It("does not update the release", func() {
+					server.AppendHandlers(
+						ghttp.CombineHandlers(
+							ghttp.VerifyRequest("GET", releasesURL),
+							ghttp.RespondWith(http.StatusOK, `{"releases":[{"id":3,"version":"1.2.3"}]}`),
+						),
+						ghttp.CombineHandlers(
+							ghttp.VerifyRequest("GET", releaseURL),
+							ghttp.RespondWith(http.StatusOK, `{"id":3,"version":"1.2.3","release_type":"Minor Release","release_date":"2016-01-01","eula":{"slug":"some-eula"},"description":"new description"}`),
+						),
+						ghttp.CombineHandlers(
+							ghttp.VerifyRequest("GET", productFilesURL),
+							ghttp.RespondWith(http.StatusOK, `{"product_files":[{"id":4},{"id":5}]}`),
+						),
+					)
+
+					_, err := client.Releases.Apply(productSlug, spec)
+					Expect(err).NotTo(HaveOccurred())
+					Expect(server.ReceivedRequests()).To(HaveLen(3))
+				})
+			})
+		})
+
+		Context("when the spec was exported from a release without product files", func() {
+			BeforeEach(func() {
+				server.AppendHandlers(
+					ghttp.CombineHandlers(
+						ghttp.VerifyRequest("GET", releaseURL),
+						ghttp.RespondWith(http.StatusOK, `{"id":3,"version":"1.2.3","description":"new description"}`),
+					),
+					ghttp.CombineHandlers(
+						ghttp.VerifyRequest("GET", productFilesURL),
+						ghttp.RespondWith(http.StatusOK, `{"product_files":[]}`),
+					),
+				)
+
+				exported, err := client.Releases.Export(productSlug, 3)
+				Expect(err).NotTo(HaveOccurred())
+
+				b, err := exported.Marshal()
+				Expect(err).NotTo(HaveOccurred())
+
+				spec, err = pivnet.UnmarshalReleaseSpec(b)
+				Expect(err).NotTo(HaveOccurred())
+			})
+
+			It("removes every product file of the release", func() {
+				server.AppendHandlers(
+					ghttp.CombineHandlers(
+						ghttp.VerifyRequest("GET", releasesURL),
+						ghttp.RespondWith(http.StatusOK, `{"releases":[{"id":3,"version":"1.2.3"}]}`),
+					),
+					ghttp.CombineHandlers(
+						ghttp.VerifyRequest("GET", releaseURL),
+						ghttp.RespondWith(http.StatusOK, `{"id":3,"version":"1.2.3","description":"new description"}`),
+					),
+					ghttp.CombineHandlers(
+						ghttp.VerifyRequest("GET", productFilesURL),
+						ghttp.RespondWith(http.StatusOK, `{"product_files":[{"id":4}]}`),
+					),
+					ghttp.CombineHandlers(
+						ghttp.VerifyRequest("PATCH", releaseURL+"/remove_product_file"),
+						ghttp.VerifyJSON(`{"product_file":{"id":4}}`),
+						ghttp.RespondWith(http.StatusNoContent, nil),
+					),
+				)
+
+				_, err := client.Releases.Apply(productSlug, spec)
+				Expect(err).NotTo(HaveOccurred())
+				Expect(server.ReceivedRequests()).To(HaveLen(6))
+			})
+		})
+
+		Context("when the spec omits product_file_ids", func() {
+			BeforeEach(func() {
+				var err error
+				spec, err = pivnet.UnmarshalReleaseSpec([]byte(`{"spec_version": 1, "version": "1.2.3", "description": "new description"}`))
+				Expect(err).NotTo(HaveOccurred())
+			})
+
+			It("leaves the product files of the release alone", func() {
+				server.AppendHandlers(
+					ghttp.CombineHandlers(
+						ghttp.VerifyRequest("GET", releasesURL),
+						ghttp.RespondWith(http.StatusOK, `{"releases":[{"id":3,"version":"1.2.3"}]}`),
+					),
+					ghttp.CombineHandlers(
+						ghttp.VerifyRequest("GET", releaseURL),
+						ghttp.RespondWith(http.StatusOK, `{"id":3,"version":"1.2.3","description":"new description"}`),
+					),
+				)
+
+				_, err := client.Releases.Apply(productSlug, spec)
+				Expect(err).NotTo(HaveOccurred())
+				Expect(server.ReceivedRequests()).To(HaveLen(2))
+			})
+		})
+
+		Context("when the spec has no version", func() {
+			BeforeEach(func() {
+				spec.Version = ""
+			})
+
+			It("returns an error", func() {
+				_, err := client.Releases.Apply(productSlug, spec)
+				Expect(err).To(HaveOccurred())
+				Expect(server.ReceivedRequests()).To(BeEmpty())
+			})
+		})
+
+		Context("when listing releases returns an error", func() {
+			It("forwards the error", func() {
+				server.AppendHandlers(
+					ghttp.CombineHandlers(
+						ghttp.VerifyRequest("GET", releasesURL),
+						ghttp.RespondWith(http.StatusTeapot, `{"message":"foo message"}`),
+					),
+				)
+
+				_, err := client.Releases.Apply(productSlug, spec)
+				Expect(err.Error()).To(ContainSubstring("foo message"))
+			})
+		})
+	})
+})
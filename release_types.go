@@ -12,6 +12,19 @@ type ReleaseTypesService struct {
 
 type ReleaseType string
 
+const (
+	ReleaseTypeAllInOne    ReleaseType = "All-In-One"
+	ReleaseTypeMajor       ReleaseType = "Major Release"
+	ReleaseTypeMinor       ReleaseType = "Minor Release"
+	ReleaseTypeService     ReleaseType = "Service Release"
+	ReleaseTypeMaintenance ReleaseType = "Maintenance Release"
+	ReleaseTypeSecurity    ReleaseType = "Security Release"
+	ReleaseTypeAlpha       ReleaseType = "Alpha Release"
+	ReleaseTypeBeta        ReleaseType = "Beta Release"
+	ReleaseTypeEdge        ReleaseType = "Edge Release"
+	ReleaseTypeDeveloper   ReleaseType = "Developer Release"
+)
+
 type ReleaseTypesResponse struct {
 	ReleaseTypes []ReleaseType `json:"release_types" yaml:"release_types"`
 }
@@ -0,0 +1,309 @@
+package pivnet
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// UpgradeGraph is the directed graph of the upgrade paths of a product.
+// Each node is a release and each edge an upgrade Pivnet allows from one
+// release to another.
+type UpgradeGraph struct {
+	Nodes []UpgradeGraphNode `json:"nodes" yaml:"nodes"`
+	Edges []UpgradeGraphEdge `json:"edges" yaml:"edges"`
+}
+
+type UpgradeGraphNode struct {
+	ReleaseID int    `json:"release_id" yaml:"release_id"`
+	Version   string `json:"version" yaml:"version"`
+
+	// Missing is set for a release that an upgrade path refers to but that
+	// is not among the releases of the product.
+	Missing bool `json:"missing,omitempty" yaml:"missing,omitempty"`
+}
+
+// UpgradeGraphEdge allows upgrading from the release with ID From to the
+// release with ID To.
+type UpgradeGraphEdge struct {
+	From int `json:"from" yaml:"from"`
+	To   int `json:"to" yaml:"to"`
+}
+
+// UpgradeGraph returns the upgrade paths of every release of the product as
+// a graph, fetching them with at most concurrency simultaneous requests.
+// Nodes are in the order of Releases.List and edges are sorted by release
+// ID.
+//
+// Releases whose upgrade paths could not be fetched are reported in a
+// MultiError, with the graph of the other releases. If ctx is done,
+// requests in flight are aborted, no further releases are fetched and the
+// partial graph is returned with ctx.Err().
+func (r ReleasesService) UpgradeGraph(
+	ctx context.Context,
+	productSlug string,
+	concurrency int,
+) (UpgradeGraph, error) {
+	if err := ctx.Err(); err != nil {
+		return UpgradeGraph{}, err
+	}
+
+	r.client = r.client.WithContext(ctx)
+
+	releases, err := r.List(productSlug)
+	if err != nil {
+		return UpgradeGraph{}, err
+	}
+
+	upgradePaths := ReleaseUpgradePathsService{client: r.client}
+
+	paths := make([][]ReleaseUpgradePath, len(releases))
+	errs := map[int]error{}
+
+	var mutex sync.Mutex
+	forEachConcurrently(len(releases), concurrency, func(i int) {
+		if ctx.Err() != nil {
+			return
+		}
+
+		releasePaths, err := upgradePaths.Get(productSlug, releases[i].ID)
+		if err != nil {
+			mutex.Lock()
+			errs[releases[i].ID] = err
+			mutex.Unlock()
+			return
+		}
+
+		paths[i] = releasePaths
+	})
+
+	graph := UpgradeGraph{
+		Nodes: []UpgradeGraphNode{},
+		Edges: []UpgradeGraphEdge{},
+	}
+
+	known := map[int]bool{}
+	for _, release := range releases {
+		graph.Nodes = append(graph.Nodes, UpgradeGraphNode{
+			ReleaseID: release.ID,
+			Version:   release.Version,
+		})
+		known[release.ID] = true
+	}
+
+	for i, release := range releases {
+		for _, path := range paths[i] {
+			if !known[path.Release.ID] {
+				graph.Nodes = append(graph.Nodes, UpgradeGraphNode{
+					ReleaseID: path.Release.ID,
+					Version:   path.Release.Version,
+					Missing:   true,
+				})
+				known[path.Release.ID] = true
+			}
+
+			graph.Edges = append(graph.Edges, UpgradeGraphEdge{
+				From: path.Release.ID,
+				To:   release.ID,
+			})
+		}
+	}
+
+	sort.Slice(graph.Edges, func(i, j int) bool {
+		if graph.Edges[i].From != graph.Edges[j].From {
+			return graph.Edges[i].From < graph.Edges[j].From
+		}
+		return graph.Edges[i].To < graph.Edges[j].To
+	})
+
+	if ctx.Err() != nil {
+		return graph, ctx.Err()
+	}
+
+	return graph, newMultiError(errs)
+}
+
+// DOT returns the graph in the Graphviz DOT language, with each release
+// labelled by its version, e.g. for "dot -Tsvg".
+func (g UpgradeGraph) DOT() string {
+	var b strings.Builder
+
+	b.WriteString("digraph upgrades {\n")
+	for _, node := range g.Nodes {
+		fmt.Fprintf(&b, "  %d [label=%s];\n", node.ReleaseID, strconv.Quote(node.Version))
+	}
+	for _, edge := range g.Edges {
+		fmt.Fprintf(&b, "  %d -> %d;\n", edge.From, edge.To)
+	}
+	b.WriteString("}\n")
+
+	return b.String()
+}
+
+const (
+	UpgradePathSelfReference  = "self_reference"
+	UpgradePathCycle          = "cycle"
+	UpgradePathMissingRelease = "missing_release"
+)
+
+// UpgradePathFinding is a problem with the upgrade paths of a product.
+type UpgradePathFinding struct {
+	// Kind is one of the UpgradePath constants.
+	Kind string `json:"kind" yaml:"kind"`
+
+	// ReleaseIDs are the releases involved: the release for a self
+	// reference, the release and the missing release it upgrades from, or
+	// the releases forming a cycle.
+	ReleaseIDs []int `json:"release_ids" yaml:"release_ids"`
+
+	Message string `json:"message" yaml:"message"`
+}
+
+// upgradePathValidationConcurrency is the number of releases whose upgrade
+// paths ReleaseUpgradePaths.Validate fetches at once.
+const upgradePathValidationConcurrency = 4
+
+// Validate fetches the upgrade paths of every release of the product and
+// returns their problems, see UpgradeGraph.Validate. Nothing is changed.
+//
+// Releases whose upgrade paths could not be fetched are reported in a
+// MultiError, with the findings for the other releases.
+func (r ReleaseUpgradePathsService) Validate(productSlug string) ([]UpgradePathFinding, error) {
+	graph, err := ReleasesService{client: r.client}.UpgradeGraph(
+		context.Background(),
+		productSlug,
+		upgradePathValidationConcurrency,
+	)
+	if err != nil {
+		if _, ok := err.(MultiError); !ok {
+			return nil, err
+		}
+	}
+
+	return graph.Validate(), err
+}
+
+// Validate returns the releases with an upgrade path to themselves, the
+// upgrade paths from releases that are not releases of the product, and
+// the groups of releases that can be upgraded from one to another in a
+// cycle. Self references are not reported as cycles as well.
+func (g UpgradeGraph) Validate() []UpgradePathFinding {
+	nodes := map[int]UpgradeGraphNode{}
+	for _, node := range g.Nodes {
+		nodes[node.ReleaseID] = node
+	}
+
+	describe := func(id int) string {
+		if node, ok := nodes[id]; ok && node.Version != "" {
+			return fmt.Sprintf("%s (%d)", node.Version, id)
+		}
+		return strconv.Itoa(id)
+	}
+
+	findings := []UpgradePathFinding{}
+	successors := map[int][]int{}
+
+	for _, edge := range g.Edges {
+		if edge.From == edge.To {
+			findings = append(findings, UpgradePathFinding{
+				Kind:       UpgradePathSelfReference,
+				ReleaseIDs: []int{edge.To},
+				Message:    fmt.Sprintf("Release %s has an upgrade path from itself", describe(edge.To)),
+			})
+			continue
+		}
+
+		if node, ok := nodes[edge.From]; !ok || node.Missing {
+			findings = append(findings, UpgradePathFinding{
+				Kind:       UpgradePathMissingRelease,
+				ReleaseIDs: []int{edge.To, edge.From},
+				Message: fmt.Sprintf(
+					"Release %s has an upgrade path from release %s, which is not a release of the product",
+					describe(edge.To),
+					describe(edge.From),
+				),
+			})
+		}
+
+		successors[edge.From] = append(successors[edge.From], edge.To)
+	}
+
+	for _, cycle := range upgradeCycles(g.Nodes, successors) {
+		described := make([]string, len(cycle))
+		for i, id := range cycle {
+			described[i] = describe(id)
+		}
+
+		findings = append(findings, UpgradePathFinding{
+			Kind:       UpgradePathCycle,
+			ReleaseIDs: cycle,
+			Message:    fmt.Sprintf("Releases %s form an upgrade cycle", strings.Join(described, ", ")),
+		})
+	}
+
+	return findings
+}
+
+// upgradeCycles returns the strongly connected components of more than one
+// release, using Tarjan's algorithm, each sorted by release ID.
+func upgradeCycles(nodes []UpgradeGraphNode, successors map[int][]int) [][]int {
+	index := map[int]int{}
+	lowlink := map[int]int{}
+	onStack := map[int]bool{}
+	var stack []int
+	var cycles [][]int
+
+	var visit func(id int)
+	visit = func(id int) {
+		index[id] = len(index)
+		lowlink[id] = index[id]
+		stack = append(stack, id)
+		onStack[id] = true
+
+		for _, next := range successors[id] {
+			if _, visited := index[next]; !visited {
+				visit(next)
+				if lowlink[next] < lowlink[id] {
+					lowlink[id] = lowlink[next]
+				}
+			} else if onStack[next] && index[next] < lowlink[id] {
+				lowlink[id] = index[next]
+			}
+		}
+
+		if lowlink[id] != index[id] {
+			return
+		}
+
+		var component []int
+		for {
+			top := stack[len(stack)-1]
+			stack = stack[:len(stack)-1]
+			onStack[top] = false
+			component = append(component, top)
+			if top == id {
+				break
+			}
+		}
+
+		if len(component) > 1 {
+			sort.Ints(component)
+			cycles = append(cycles, component)
+		}
+	}
+
+	for _, node := range nodes {
+		if _, visited := index[node.ReleaseID]; !visited {
+			visit(node.ReleaseID)
+		}
+	}
+
+	sort.Slice(cycles, func(i, j int) bool {
+		return cycles[i][0] < cycles[j][0]
+	})
+
+	return cycles
+}
@@ -0,0 +1,287 @@
+package pivnet_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"github.com/onsi/gomega/ghttp"
+	"github.com/pivotal-cf/go-pivnet"
+	"github.com/pivotal-cf/go-pivnet/logger/loggerfakes"
+)
+
+var _ = Describe("PivnetClient - upgrade graph", func() {
+	var (
+		server *ghttp.Server
+		client pivnet.Client
+	)
+
+	upgradePathsURL := func(releaseID int) string {
+		return fmt.Sprintf("%s/products/banana/releases/%d/upgrade_paths", apiPrefix, releaseID)
+	}
+
+	BeforeEach(func() {
+		server = ghttp.NewServer()
+		client = pivnet.NewClient(pivnet.ClientConfig{
+			Host:      server.URL(),
+			Token:     "my-auth-token",
+			UserAgent: "pivnet-resource/0.1.0 (some-url)",
+		}, &loggerfakes.FakeLogger{})
+
+		server.RouteToHandler("GET", fmt.Sprintf("%s/products/banana/releases", apiPrefix),
+			ghttp.RespondWith(http.StatusOK, `{"releases": [
+				{"id": 3, "version": "2.0.0"},
+				{"id": 2, "version": "1.1.0"},
+				{"id": 1, "version": "1.0.0"}
+			]}`),
+		)
+		server.RouteToHandler("GET", upgradePathsURL(3),
+			ghttp.RespondWith(http.StatusOK, `{"upgrade_paths": [
+				{"release": {"id": 2, "version": "1.1.0"}},
+				{"release": {"id": 1, "version": "1.0.0"}}
+			]}`),
+		)
+		server.RouteToHandler("GET", upgradePathsURL(2),
+			ghttp.RespondWith(http.StatusOK, `{"upgrade_paths": [
+				{"release": {"id": 1, "version": "1.0.0"}},
+				{"release": {"id": 9, "version": "0.9.0"}}
+			]}`),
+		)
+		server.RouteToHandler("GET", upgradePathsURL(1),
+			ghttp.RespondWith(http.StatusOK, `{"upgrade_paths": []}`),
+		)
+	})
+
+	AfterEach(func() {
+		server.Close()
+	})
+
+	It("returns the releases and the upgrades between them", func() {
+		graph, err := client.Releases.UpgradeGraph(context.Background(), "banana", 2)
+		Expect(err).NotTo(HaveOccurred())
+
+		Expect(graph.Nodes).To(Equal([]pivnet.UpgradeGraphNode{
+			{ReleaseID: 3, Version: "2.0.0"},
+			{ReleaseID: 2, Version: "1.1.0"},
+			{ReleaseID: 1, Version: "1.0.0"},
+			{ReleaseID: 9, Version: "0.9.0", Missing: true},
+		}))
+		Expect(graph.Edges).To(Equal([]pivnet.UpgradeGraphEdge{
+			{From: 1, To: 2},
+			{From: 1, To: 3},
+			{From: 2, To: 3},
+			{From: 9, To: 2},
+		}))
+	})
+
+	It("renders the graph as DOT", func() {
+		graph, err := client.Releases.UpgradeGraph(context.Background(), "banana", 2)
+		Expect(err).NotTo(HaveOccurred())
+
+		Expect(graph.DOT()).To(Equal(`digraph upgrades {
+  3 [label="2.0.0"];
+  2 [label="1.1.0"];
+  1 [label="1.0.0"];
+  9 [label="0.9.0"];
+  1 -> 2;
+  1 -> 3;
+  2 -> 3;
+  9 -> 2;
+}
+`))
+	})
+
+	Context("when the product has no upgrade paths", func() {
+		BeforeEach(func() {
+			server.RouteToHandler("GET", fmt.Sprintf("%s/products/banana/releases", apiPrefix),
+				ghttp.RespondWith(http.StatusOK, `{"releases": []}`),
+			)
+		})
+
+		It("returns an empty graph that renders as valid DOT and JSON", func() {
+			graph, err := client.Releases.UpgradeGraph(context.Background(), "banana", 2)
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(graph.DOT()).To(Equal("digraph upgrades {\n}\n"))
+
+			b, err := json.Marshal(graph)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(b).To(MatchJSON(`{"nodes": [], "edges": []}`))
+		})
+	})
+
+	Context("when the upgrade paths of a release cannot be fetched", func() {
+		BeforeEach(func() {
+			server.RouteToHandler("GET", upgradePathsURL(2),
+				ghttp.RespondWith(http.StatusTeapot, `{"message": "foo message"}`),
+			)
+		})
+
+		It("returns the rest of the graph with a MultiError", func() {
+			graph, err := client.Releases.UpgradeGraph(context.Background(), "banana", 2)
+
+			var multiErr pivnet.MultiError
+			Expect(errors.As(err, &multiErr)).To(BeTrue())
+			Expect(multiErr.Errors).To(HaveLen(1))
+			Expect(multiErr.Errors[0].ID).To(Equal(2))
+
+			Expect(graph.Nodes).To(HaveLen(3))
+			Expect(graph.Edges).To(Equal([]pivnet.UpgradeGraphEdge{
+				{From: 1, To: 3},
+				{From: 2, To: 3},
+			}))
+		})
+	})
+
+	Context("when the context is cancelled", func() {
+		It("does not fetch any upgrade paths", func() {
+			ctx, cancel := context.WithCancel(context.Background())
+			cancel()
+
+			graph, err := client.Releases.UpgradeGraph(ctx, "banana", 2)
+			Expect(err).To(Equal(context.Canceled))
+			Expect(graph.Edges).To(BeEmpty())
+
+			Expect(server.ReceivedRequests()).To(BeEmpty())
+		})
+
+		Context("while a request is in flight", func() {
+			It("aborts the request and returns the context error", func() {
+				ctx, cancel := context.WithCancel(context.Background())
+				defer cancel()
+
+				server.RouteToHandler("GET", fmt.Sprintf("%s/products/banana/releases", apiPrefix),
+					func(w http.ResponseWriter, r *http.Request) {
+						cancel()
+						<-r.Context().Done()
+					},
+				)
+
+				_, err := client.Releases.UpgradeGraph(ctx, "banana", 2)
+				Expect(errors.Is(err, context.Canceled)).To(BeTrue())
+			})
+		})
+	})
+
+	Context("when the releases cannot be listed", func() {
+		BeforeEach(func() {
+			server.RouteToHandler("GET", fmt.Sprintf("%s/products/banana/releases", apiPrefix),
+				ghttp.RespondWith(http.StatusTeapot, `{"message": "foo message"}`),
+			)
+		})
+
+		It("returns an error", func() {
+			_, err := client.Releases.UpgradeGraph(context.Background(), "banana", 2)
+			Expect(err).To(MatchError(ContainSubstring("foo message")))
+		})
+	})
+})
+
+var _ = Describe("UpgradeGraph - Validate", func() {
+	It("reports self references, missing releases and cycles", func() {
+		graph := pivnet.UpgradeGraph{
+			Nodes: []pivnet.UpgradeGraphNode{
+				{ReleaseID: 1, Version: "1.0.0"},
+				{ReleaseID: 2, Version: "1.1.0"},
+				{ReleaseID: 3, Version: "1.2.0"},
+				{ReleaseID: 4, Version: "2.0.0"},
+				{ReleaseID: 9, Version: "0.9.0", Missing: true},
+			},
+			Edges: []pivnet.UpgradeGraphEdge{
+				{From: 1, To: 2},
+				{From: 2, To: 3},
+				{From: 3, To: 1},
+				{From: 3, To: 4},
+				{From: 4, To: 4},
+				{From: 9, To: 1},
+			},
+		}
+
+		Expect(graph.Validate()).To(Equal([]pivnet.UpgradePathFinding{
+			{
+				Kind:       pivnet.UpgradePathSelfReference,
+				ReleaseIDs: []int{4},
+				Message:    "Release 2.0.0 (4) has an upgrade path from itself",
+			},
+			{
+				Kind:       pivnet.UpgradePathMissingRelease,
+				ReleaseIDs: []int{1, 9},
+				Message:    "Release 1.0.0 (1) has an upgrade path from release 0.9.0 (9), which is not a release of the product",
+			},
+			{
+				Kind:       pivnet.UpgradePathCycle,
+				ReleaseIDs: []int{1, 2, 3},
+				Message:    "Releases 1.0.0 (1), 1.1.0 (2), 1.2.0 (3) form an upgrade cycle",
+			},
+		}))
+	})
+
+	It("returns no findings for a valid graph", func() {
+		graph := pivnet.UpgradeGraph{
+			Nodes: []pivnet.UpgradeGraphNode{{ReleaseID: 1}, {ReleaseID: 2}, {ReleaseID: 3}},
+			Edges: []pivnet.UpgradeGraphEdge{{From: 1, To: 2}, {From: 1, To: 3}, {From: 2, To: 3}},
+		}
+
+		Expect(graph.Validate()).To(BeEmpty())
+	})
+})
+
+var _ = Describe("PivnetClient - validate upgrade paths", func() {
+	var (
+		server *ghttp.Server
+		client pivnet.Client
+	)
+
+	BeforeEach(func() {
+		server = ghttp.NewServer()
+		client = pivnet.NewClient(pivnet.ClientConfig{
+			Host:      server.URL(),
+			Token:     "my-auth-token",
+			UserAgent: "pivnet-resource/0.1.0 (some-url)",
+		}, &loggerfakes.FakeLogger{})
+
+		server.RouteToHandler("GET", fmt.Sprintf("%s/products/banana/releases", apiPrefix),
+			ghttp.RespondWith(http.StatusOK, `{"releases": [{"id": 2, "version": "1.1.0"}, {"id": 1, "version": "1.0.0"}]}`),
+		)
+		server.RouteToHandler("GET", fmt.Sprintf("%s/products/banana/releases/2/upgrade_paths", apiPrefix),
+			ghttp.RespondWith(http.StatusOK, `{"upgrade_paths": [{"release": {"id": 1, "version": "1.0.0"}}]}`),
+		)
+		server.RouteToHandler("GET", fmt.Sprintf("%s/products/banana/releases/1/upgrade_paths", apiPrefix),
+			ghttp.RespondWith(http.StatusOK, `{"upgrade_paths": [{"release": {"id": 2, "version": "1.1.0"}}]}`),
+		)
+	})
+
+	AfterEach(func() {
+		server.Close()
+	})
+
+	It("fetches the upgrade paths of the product and reports their problems", func() {
+		findings, err := client.ReleaseUpgradePaths.Validate("banana")
+		Expect(err).NotTo(HaveOccurred())
+
+		Expect(findings).To(HaveLen(1))
+		Expect(findings[0].Kind).To(Equal(pivnet.UpgradePathCycle))
+		Expect(findings[0].ReleaseIDs).To(Equal([]int{1, 2}))
+
+		for _, req := range server.ReceivedRequests() {
+			Expect(req.Method).To(Equal("GET"))
+		}
+	})
+
+	Context("when the releases cannot be listed", func() {
+		BeforeEach(func() {
+			server.RouteToHandler("GET", fmt.Sprintf("%s/products/banana/releases", apiPrefix),
+				ghttp.RespondWith(http.StatusTeapot, `{"message": "foo message"}`),
+			)
+		})
+
+		It("returns an error", func() {
+			_, err := client.ReleaseUpgradePaths.Validate("banana")
+			Expect(err).To(MatchError(ContainSubstring("foo message")))
+		})
+	})
+})
@@ -0,0 +1,121 @@
+package pivnet
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/pivotal-cf/go-pivnet/logger"
+)
+
+type WatchConfig struct {
+	Interval time.Duration
+
+	// OnNew is called for each release not seen in any earlier poll.
+	OnNew func(Release)
+
+	// OnError, if set, is called when a poll fails. Otherwise failures are
+	// logged. Either way the watch continues with the next poll.
+	OnError func(error)
+
+	// IncludeExisting calls OnNew for the releases found by the initial
+	// poll. By default they are only recorded as seen.
+	IncludeExisting bool
+}
+
+// Watch polls the releases of the product every interval and calls onNew
+// for each release that was not seen in any earlier poll. Releases that
+// exist when Watch is called are not reported.
+//
+// Pivnet does not offer webhooks or subscriptions, so polling is the only
+// way to be notified of new releases. See WatchWithConfig.
+func (r ReleasesService) Watch(
+	ctx context.Context,
+	productSlug string,
+	interval time.Duration,
+	onNew func(Release),
+) error {
+	return r.WatchWithConfig(ctx, productSlug, WatchConfig{
+		Interval: interval,
+		OnNew:    onNew,
+	})
+}
+
+// WatchWithConfig blocks until ctx is done and returns ctx.Err(). A poll in
+// flight when ctx is done is aborted. It returns immediately if the config
+// has no OnNew or a non-positive Interval, or if the initial poll fails.
+func (r ReleasesService) WatchWithConfig(
+	ctx context.Context,
+	productSlug string,
+	config WatchConfig,
+) error {
+	if config.OnNew == nil {
+		return fmt.Errorf("Watch config must have an OnNew callback")
+	}
+
+	if config.Interval <= 0 {
+		return fmt.Errorf("Watch interval must be positive - got %s", config.Interval)
+	}
+
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	r.client = r.client.WithContext(ctx)
+
+	releases, err := r.List(productSlug)
+	if err != nil {
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+		return err
+	}
+
+	seen := map[int]bool{}
+	for _, release := range releases {
+		seen[release.ID] = true
+
+		if config.IncludeExisting {
+			config.OnNew(release)
+		}
+	}
+
+	ticker := time.NewTicker(config.Interval)
+	defer ticker.Stop()
+
+	for ctx.Err() == nil {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-ticker.C:
+		}
+
+		releases, err := r.List(productSlug)
+		if err != nil {
+			if ctx.Err() != nil {
+				return ctx.Err()
+			}
+
+			if config.OnError != nil {
+				config.OnError(err)
+			} else {
+				r.l.Info(
+					"Failed to list releases while watching",
+					logger.Data{"product_slug": productSlug, "error": err.Error()},
+				)
+			}
+			continue
+		}
+
+		for _, release := range releases {
+			if seen[release.ID] {
+				continue
+			}
+
+			seen[release.ID] = true
+			config.OnNew(release)
+		}
+	}
+
+	return ctx.Err()
+}
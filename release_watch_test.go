@@ -0,0 +1,177 @@
+package pivnet_test
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"time"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"github.com/onsi/gomega/ghttp"
+	"github.com/pivotal-cf/go-pivnet"
+	"github.com/pivotal-cf/go-pivnet/logger/loggerfakes"
+)
+
+var _ = Describe("PivnetClient - watch releases", func() {
+	var (
+		server     *ghttp.Server
+		client     pivnet.Client
+		fakeLogger *loggerfakes.FakeLogger
+
+		releasesURL string
+	)
+
+	BeforeEach(func() {
+		server = ghttp.NewServer()
+
+		fakeLogger = &loggerfakes.FakeLogger{}
+		client = pivnet.NewClient(pivnet.ClientConfig{
+			Host:      server.URL(),
+			Token:     "my-auth-token",
+			UserAgent: "pivnet-resource/0.1.0 (some-url)",
+		}, fakeLogger)
+
+		releasesURL = fmt.Sprintf("%s/products/%s/releases", apiPrefix, productSlug)
+	})
+
+	AfterEach(func() {
+		server.Close()
+	})
+
+	Describe("Watch", func() {
+		It("calls the callback for releases that appear after the first poll", func() {
+			server.AppendHandlers(
+				ghttp.CombineHandlers(
+					ghttp.VerifyRequest("GET", releasesURL),
+					ghttp.RespondWith(http.StatusOK, `{"releases":[{"id":1}]}`),
+				),
+				ghttp.CombineHandlers(
+					ghttp.VerifyRequest("GET", releasesURL),
+					ghttp.RespondWith(http.StatusTeapot, `{"message":"foo message"}`),
+				),
+				ghttp.CombineHandlers(
+					ghttp.VerifyRequest("GET", releasesURL),
+					ghttp.RespondWith(http.StatusOK, `{"releases":[{"id":2},{"id":1}]}`),
+				),
+			)
+
+			ctx, cancel := context.WithCancel(context.Background())
+			defer cancel()
+
+			var newReleases []pivnet.Release
+			callback := func(release pivnet.Release) {
+				newReleases = append(newReleases, release)
+				cancel()
+			}
+
+			err := client.Releases.Watch(ctx, productSlug, 10*time.Millisecond, callback)
+			Expect(err).To(Equal(context.Canceled))
+
+			Expect(newReleases).To(Equal([]pivnet.Release{{ID: 2}}))
+			Expect(fakeLogger.InfoCallCount()).To(Equal(1))
+		})
+
+		Context("when configured to report errors and existing releases", func() {
+			It("calls OnNew for existing releases and OnError for failed polls", func() {
+				server.AppendHandlers(
+					ghttp.CombineHandlers(
+						ghttp.VerifyRequest("GET", releasesURL),
+						ghttp.RespondWith(http.StatusOK, `{"releases":[{"id":1}]}`),
+					),
+					ghttp.CombineHandlers(
+						ghttp.VerifyRequest("GET", releasesURL),
+						ghttp.RespondWith(http.StatusTeapot, `{"message":"foo message"}`),
+					),
+				)
+
+				ctx, cancel := context.WithCancel(context.Background())
+				defer cancel()
+
+				var newReleases []pivnet.Release
+				var errs []error
+
+				err := client.Releases.WatchWithConfig(ctx, productSlug, pivnet.WatchConfig{
+					Interval:        10 * time.Millisecond,
+					IncludeExisting: true,
+					OnNew: func(release pivnet.Release) {
+						newReleases = append(newReleases, release)
+					},
+					OnError: func(err error) {
+						errs = append(errs, err)
+						cancel()
+					},
+				})
+				Expect(err).To(Equal(context.Canceled))
+
+				Expect(newReleases).To(Equal([]pivnet.Release{{ID: 1}}))
+				Expect(errs).To(HaveLen(1))
+				Expect(errs[0].Error()).To(ContainSubstring("foo message"))
+				Expect(fakeLogger.InfoCallCount()).To(BeZero())
+			})
+		})
+
+		Context("when the context is cancelled while a poll is in flight", func() {
+			It("aborts the poll and returns the context error", func() {
+				ctx, cancel := context.WithCancel(context.Background())
+				defer cancel()
+
+				server.AppendHandlers(
+					ghttp.CombineHandlers(
+						ghttp.VerifyRequest("GET", releasesURL),
+						ghttp.RespondWith(http.StatusOK, `{"releases":[{"id":1}]}`),
+					),
+					func(w http.ResponseWriter, r *http.Request) {
+						cancel()
+						<-r.Context().Done()
+					},
+				)
+
+				var errs []error
+				err := client.Releases.WatchWithConfig(ctx, productSlug, pivnet.WatchConfig{
+					Interval: 10 * time.Millisecond,
+					OnNew:    func(pivnet.Release) {},
+					OnError: func(err error) {
+						errs = append(errs, err)
+					},
+				})
+				Expect(err).To(Equal(context.Canceled))
+				Expect(errs).To(BeEmpty())
+			})
+		})
+
+		Context("when the initial listing fails", func() {
+			It("returns the error", func() {
+				server.AppendHandlers(
+					ghttp.CombineHandlers(
+						ghttp.VerifyRequest("GET", releasesURL),
+						ghttp.RespondWith(http.StatusTeapot, `{"message":"foo message"}`),
+					),
+				)
+
+				err := client.Releases.Watch(context.Background(), productSlug, time.Millisecond, func(pivnet.Release) {})
+				Expect(err).To(MatchError(ContainSubstring("foo message")))
+			})
+		})
+
+		Context("when the interval is not positive", func() {
+			It("returns an error without polling", func() {
+				err := client.Releases.Watch(context.Background(), productSlug, 0, func(pivnet.Release) {})
+				Expect(err).To(MatchError("Watch interval must be positive - got 0s"))
+
+				Expect(server.ReceivedRequests()).To(BeEmpty())
+			})
+		})
+
+		Context("when there is no OnNew callback", func() {
+			It("returns an error without polling", func() {
+				err := client.Releases.WatchWithConfig(context.Background(), productSlug, pivnet.WatchConfig{
+					Interval: time.Millisecond,
+				})
+				Expect(err).To(MatchError(ContainSubstring("OnNew")))
+
+				Expect(server.ReceivedRequests()).To(BeEmpty())
+			})
+		})
+	})
+})
@@ -3,8 +3,13 @@ package pivnet
 import (
 	"bytes"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"net/http"
+	"reflect"
+	"sort"
+	"strings"
+	"sync"
 	"time"
 
 	"github.com/pivotal-cf/go-pivnet/logger"
@@ -45,6 +50,91 @@ type Release struct {
 	EndOfGuidanceDate     string      `json:"end_of_guidance_date,omitempty" yaml:"end_of_guidance_date,omitempty"`
 	EndOfAvailabilityDate string      `json:"end_of_availability_date,omitempty" yaml:"end_of_availability_date,omitempty"`
 	UpdatedAt             string      `json:"updated_at,omitempty" yaml:"updated_at,omitempty"`
+
+	// SoftwareFilesUpdated and FixesReleased control whether Pivnet notifies
+	// subscribers of a software update or a security fix when the release
+	// is updated. They are only sent when set.
+	SoftwareFilesUpdated bool `json:"software_files_updated,omitempty" yaml:"software_files_updated,omitempty"`
+	FixesReleased        bool `json:"fixes_released,omitempty" yaml:"fixes_released,omitempty"`
+
+	// SkipOSSCompliance omits the OSS compliance confirmation when the
+	// release is passed to Update. It is never sent to Pivnet.
+	SkipOSSCompliance bool `json:"-" yaml:"-"`
+
+	// AdditionalFields holds fields returned by Pivnet that are not
+	// modelled above. They are never sent to Pivnet.
+	AdditionalFields map[string]json.RawMessage `json:"-" yaml:"-"`
+}
+
+func (r *Release) UnmarshalJSON(b []byte) error {
+	type release Release
+
+	var decoded release
+	additionalFields, err := unmarshalWithAdditionalFields(b, &decoded)
+	if err != nil {
+		return err
+	}
+
+	*r = Release(decoded)
+	r.AdditionalFields = additionalFields
+
+	return nil
+}
+
+func (r Release) ReleaseDateTime() (time.Time, error) {
+	return parseTimestamp(r.ReleaseDate)
+}
+
+func (r Release) UpdatedAtTime() (time.Time, error) {
+	return parseTimestamp(r.UpdatedAt)
+}
+
+func (r Release) EndOfSupportDateTime() (time.Time, error) {
+	return parseTimestamp(r.EndOfSupportDate)
+}
+
+// OSSCompliantConfirm confirms that a release complies with the open source
+// licensing requirements. Pivnet requires this confirmation on releases
+// before they can be made available to users, so Create and Update send it
+// unless SkipOSSCompliance is set.
+const OSSCompliantConfirm = "confirm"
+
+const (
+	AvailabilityAdminsOnly         = "Admins Only"
+	AvailabilityAllUsers           = "All Users"
+	AvailabilitySelectedUserGroups = "Selected User Groups Only"
+)
+
+var availabilities = []string{
+	AvailabilityAdminsOnly,
+	AvailabilityAllUsers,
+	AvailabilitySelectedUserGroups,
+}
+
+func validateAvailability(availability string) error {
+	for _, a := range availabilities {
+		if availability == a {
+			return nil
+		}
+	}
+
+	return fmt.Errorf(
+		"Invalid availability '%s' - must be one of: '%s'",
+		availability,
+		strings.Join(availabilities, "', '"),
+	)
+}
+
+func validateExportControl(controlled bool, eccn string, licenseException string) error {
+	if !controlled {
+		return nil
+	}
+
+	if eccn == "" || licenseException == "" {
+		return fmt.Errorf("ECCN and license exception must be provided for controlled releases")
+	}
+
+	return nil
 }
 
 type CreateReleaseConfig struct {
@@ -61,6 +151,47 @@ type CreateReleaseConfig struct {
 	EndOfSupportDate      string
 	EndOfGuidanceDate     string
 	EndOfAvailabilityDate string
+	SkipOSSCompliance     bool
+}
+
+// Validate checks that the config has the fields Pivnet requires. If
+// releaseTypes is not empty, ReleaseType must be one of them; the valid
+// types can be obtained from ReleaseTypes.Get.
+func (c CreateReleaseConfig) Validate(releaseTypes []ReleaseType) error {
+	if c.ProductSlug == "" {
+		return fmt.Errorf("Product slug must not be empty")
+	}
+
+	if c.Version == "" {
+		return fmt.Errorf("Version must not be empty")
+	}
+
+	if c.EULASlug == "" {
+		return fmt.Errorf("EULA slug must not be empty")
+	}
+
+	err := validateExportControl(c.Controlled, c.ECCN, c.LicenseException)
+	if err != nil {
+		return err
+	}
+
+	if len(releaseTypes) == 0 {
+		return nil
+	}
+
+	valid := make([]string, len(releaseTypes))
+	for i, releaseType := range releaseTypes {
+		if c.ReleaseType == string(releaseType) {
+			return nil
+		}
+		valid[i] = string(releaseType)
+	}
+
+	return fmt.Errorf(
+		"Invalid release type '%s' - must be one of: '%s'",
+		c.ReleaseType,
+		strings.Join(valid, "', '"),
+	)
 }
 
 func (r ReleasesService) List(productSlug string) ([]Release, error) {
@@ -99,16 +230,377 @@ func (r ReleasesService) Get(productSlug string, releaseID int) (Release, error)
 	return response, nil
 }
 
+// ListWithEULAs lists the releases of the product with their EULA set. The
+// list endpoint does not include EULAs, so each release without one is
+// fetched individually using at most concurrency simultaneous requests.
+func (r ReleasesService) ListWithEULAs(productSlug string, concurrency int) ([]Release, error) {
+	releases, err := r.List(productSlug)
+	if err != nil {
+		return nil, err
+	}
+
+	errs := map[int]error{}
+
+	var mutex sync.Mutex
+	forEachConcurrently(len(releases), concurrency, func(i int) {
+		if releases[i].EULA != nil {
+			return
+		}
+
+		release, err := r.Get(productSlug, releases[i].ID)
+		if err != nil {
+			mutex.Lock()
+			errs[releases[i].ID] = err
+			mutex.Unlock()
+			return
+		}
+
+		releases[i].EULA = release.EULA
+	})
+
+	if err := newMultiError(errs); err != nil {
+		return nil, err
+	}
+
+	return releases, nil
+}
+
+// maxVersionsInError is the number of versions GetByVersion lists when it
+// finds no release with the version.
+const maxVersionsInError = 10
+
+// GetByVersion returns the release of the product with the given version,
+// or ErrNotFound listing the available versions if there is none. Pivnet
+// does not support HEAD requests for releases, so this is the cheapest way
+// to check that a version exists: it makes a single request listing the
+// product's releases.
+//
+// A release whose version is exactly the given one is preferred. Otherwise
+// versions are compared ignoring surrounding whitespace and, if set,
+// ClientConfig.VersionPrefix.
+func (r ReleasesService) GetByVersion(productSlug string, version string) (Release, error) {
+	releases, err := r.List(productSlug)
+	if err != nil {
+		return Release{}, err
+	}
+
+	if release, ok := r.findReleaseByVersion(releases, version); ok {
+		return release, nil
+	}
+
+	return Release{}, newErrNotFound(fmt.Sprintf(
+		"Release '%s' not found for product '%s' - %s",
+		version,
+		productSlug,
+		describeAvailableVersions(releases),
+	))
+}
+
+func describeAvailableVersions(releases []Release) string {
+	if len(releases) == 0 {
+		return "the product has no releases"
+	}
+
+	var versions []string
+	for i, release := range releases {
+		if i == maxVersionsInError {
+			versions = append(versions, fmt.Sprintf("and %d more", len(releases)-i))
+			break
+		}
+		versions = append(versions, fmt.Sprintf("'%s'", release.Version))
+	}
+
+	return "available versions: " + strings.Join(versions, ", ")
+}
+
+// Exists reports whether the product has a release with the version. An
+// error, including ErrNotFound if the product does not exist, means that
+// the releases could not be listed.
+func (r ReleasesService) Exists(productSlug string, version string) (bool, error) {
+	releases, err := r.List(productSlug)
+	if err != nil {
+		return false, err
+	}
+
+	_, ok := r.findReleaseByVersion(releases, version)
+	return ok, nil
+}
+
+// findReleaseByVersion returns the release with exactly the version, or
+// else the first release whose version matches once both are normalised.
+func (r ReleasesService) findReleaseByVersion(releases []Release, version string) (Release, bool) {
+	for _, release := range releases {
+		if release.Version == version {
+			return release, true
+		}
+	}
+
+	normalized := normalizeVersionInput(version, r.client.versionPrefix)
+	for _, release := range releases {
+		if normalizeVersionInput(release.Version, r.client.versionPrefix) == normalized {
+			return release, true
+		}
+	}
+
+	return Release{}, false
+}
+
+// normalizeVersionInput removes surrounding whitespace and then prefix,
+// ignoring case, from version.
+func normalizeVersionInput(version string, prefix string) string {
+	v := strings.TrimSpace(version)
+	if prefix != "" && len(v) >= len(prefix) && strings.EqualFold(v[:len(prefix)], prefix) {
+		v = strings.TrimSpace(v[len(prefix):])
+	}
+	return v
+}
+
+// DistinctTypes returns the release types used by the product's releases,
+// with the number of releases of each type. Releases without a release type
+// are not counted.
+func (r ReleasesService) DistinctTypes(productSlug string) (map[ReleaseType]int, error) {
+	releases, err := r.List(productSlug)
+	if err != nil {
+		return nil, err
+	}
+
+	counts := map[ReleaseType]int{}
+	for _, release := range releases {
+		if release.ReleaseType != "" {
+			counts[release.ReleaseType]++
+		}
+	}
+
+	return counts, nil
+}
+
+// ListBetween returns the releases of the product whose release date is at
+// or after start and before end. Releases without a parseable release date
+// are excluded.
+func (r ReleasesService) ListBetween(productSlug string, start time.Time, end time.Time) ([]Release, error) {
+	releases, err := r.List(productSlug)
+	if err != nil {
+		return nil, err
+	}
+
+	matching := []Release{}
+	for _, release := range releases {
+		releaseDate, err := release.ReleaseDateTime()
+		if err != nil || releaseDate.IsZero() {
+			r.l.Debug(
+				"Ignoring release without a parseable release date",
+				logger.Data{"id": release.ID, "release_date": release.ReleaseDate},
+			)
+			continue
+		}
+
+		if !releaseDate.Before(start) && releaseDate.Before(end) {
+			matching = append(matching, release)
+		}
+	}
+
+	return matching, nil
+}
+
+// ListNearingEndOfSupport returns the releases of the product whose end of
+// support date is between today and within from now, inclusive, soonest
+// first. Releases without a parseable end of support date are excluded.
+func (r ReleasesService) ListNearingEndOfSupport(productSlug string, within time.Duration) ([]Release, error) {
+	releases, err := r.List(productSlug)
+	if err != nil {
+		return nil, err
+	}
+
+	now := r.client.clock().UTC()
+	today := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.UTC)
+	end := now.Add(within)
+
+	var matching []Release
+	var dates []time.Time
+	for _, release := range releases {
+		endOfSupport, err := release.EndOfSupportDateTime()
+		if err != nil || endOfSupport.IsZero() {
+			r.l.Debug(
+				"Ignoring release without a parseable end of support date",
+				logger.Data{"id": release.ID, "end_of_support_date": release.EndOfSupportDate},
+			)
+			continue
+		}
+
+		if !endOfSupport.Before(today) && !endOfSupport.After(end) {
+			matching = append(matching, release)
+			dates = append(dates, endOfSupport)
+		}
+	}
+
+	sort.Stable(byEndOfSupport{releases: matching, dates: dates})
+
+	if matching == nil {
+		return []Release{}, nil
+	}
+
+	return matching, nil
+}
+
+type byEndOfSupport struct {
+	releases []Release
+	dates    []time.Time
+}
+
+func (b byEndOfSupport) Len() int           { return len(b.releases) }
+func (b byEndOfSupport) Less(i, j int) bool { return b.dates[i].Before(b.dates[j]) }
+func (b byEndOfSupport) Swap(i, j int) {
+	b.releases[i], b.releases[j] = b.releases[j], b.releases[i]
+	b.dates[i], b.dates[j] = b.dates[j], b.dates[i]
+}
+
+// GetMany fetches the releases with the given IDs using at most concurrency
+// simultaneous requests. Releases that could not be fetched are omitted from
+// the returned releases and reported in a MultiError keyed by release ID.
+func (r ReleasesService) GetMany(
+	productSlug string,
+	releaseIDs []int,
+	concurrency int,
+) (map[int]Release, error) {
+	releases := map[int]Release{}
+	errs := map[int]error{}
+
+	var mutex sync.Mutex
+	forEachConcurrently(len(releaseIDs), concurrency, func(i int) {
+		releaseID := releaseIDs[i]
+		release, err := r.Get(productSlug, releaseID)
+
+		mutex.Lock()
+		defer mutex.Unlock()
+
+		if err != nil {
+			errs[releaseID] = err
+			return
+		}
+		releases[releaseID] = release
+	})
+
+	return releases, newMultiError(errs)
+}
+
+// TotalSize returns the number of bytes needed to download every product
+// file of the release, and the number of product files. Product files
+// without a size are not included in the total and are logged as a
+// warning.
+func (r ReleasesService) TotalSize(productSlug string, releaseID int) (int64, int, error) {
+	productFiles, err := ProductFilesService{client: r.client}.ListForRelease(productSlug, releaseID)
+	if err != nil {
+		return 0, 0, err
+	}
+
+	var total int64
+	for _, pf := range productFiles {
+		if pf.Size <= 0 {
+			r.l.Info("Warning: product file has no size and is not included in the total", logger.Data{
+				"product_file_id": pf.ID,
+				"name":            pf.Name,
+			})
+			continue
+		}
+
+		total += int64(pf.Size)
+	}
+
+	return total, len(productFiles), nil
+}
+
+// Links returns the raw _links of a release. Pivnet commonly provides
+// self, product_files, file_groups, user_groups and eula_acceptance.
+func (r ReleasesService) Links(productSlug string, releaseID int) (LinksMap, error) {
+	url := fmt.Sprintf("/products/%s/releases/%d", productSlug, releaseID)
+
+	var response struct {
+		Links LinksMap `json:"_links"`
+	}
+	resp, err := r.client.MakeRequest("GET", url, http.StatusOK, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	err = json.NewDecoder(resp.Body).Decode(&response)
+	if err != nil {
+		return nil, err
+	}
+
+	if response.Links == nil {
+		return LinksMap{}, nil
+	}
+
+	return response.Links, nil
+}
+
+// LatestVersion returns the release of the product with the highest
+// version satisfying the constraint, such as "2.x" or ">= 1.2, < 2". An
+// empty constraint matches every version. Prereleases are only returned
+// when the constraint names one, so the result is otherwise the latest
+// GA release.
+func (r ReleasesService) LatestVersion(productSlug string, constraint string) (Release, error) {
+	c, err := parseVersionConstraint(constraint)
+	if err != nil {
+		return Release{}, err
+	}
+
+	releases, err := r.List(productSlug)
+	if err != nil {
+		return Release{}, err
+	}
+
+	var latest Release
+	var latestVersion version
+	found := false
+
+	for _, release := range releases {
+		v, err := parseVersion(release.Version)
+		if err != nil {
+			r.l.Debug(
+				"Ignoring release with unparseable version",
+				logger.Data{"version": release.Version},
+			)
+			continue
+		}
+
+		if !c.check(v) {
+			continue
+		}
+
+		if !found || v.compare(latestVersion) > 0 {
+			latest = release
+			latestVersion = v
+			found = true
+		}
+	}
+
+	if !found {
+		return Release{}, newErrNotFound(fmt.Sprintf(
+			"No release found for product %s matching version constraint '%s'",
+			productSlug,
+			constraint,
+		))
+	}
+
+	return latest, nil
+}
+
 func (r ReleasesService) Create(config CreateReleaseConfig) (Release, error) {
+	err := r.lintDescription(config.Description)
+	if err != nil {
+		return Release{}, err
+	}
+
 	url := fmt.Sprintf("/products/%s/releases", config.ProductSlug)
 
 	body := createReleaseBody{
 		Release: Release{
-			Availability: "Admins Only",
+			Availability: AvailabilityAdminsOnly,
 			EULA: &EULA{
 				Slug: config.EULASlug,
 			},
-			OSSCompliant:          "confirm",
 			ReleaseDate:           config.ReleaseDate,
 			ReleaseType:           ReleaseType(config.ReleaseType),
 			Version:               config.Version,
@@ -123,8 +615,12 @@ func (r ReleasesService) Create(config CreateReleaseConfig) (Release, error) {
 		},
 	}
 
+	if !config.SkipOSSCompliance {
+		body.Release.OSSCompliant = OSSCompliantConfirm
+	}
+
 	if config.ReleaseDate == "" {
-		body.Release.ReleaseDate = time.Now().Format("2006-01-02")
+		body.Release.ReleaseDate = r.client.clock().Format("2006-01-02")
 		r.l.Info(
 			"No release date found - using default release date",
 			logger.Data{"release date": body.Release.ReleaseDate})
@@ -158,19 +654,304 @@ func (r ReleasesService) Create(config CreateReleaseConfig) (Release, error) {
 }
 
 func (r ReleasesService) Update(productSlug string, release Release) (Release, error) {
+	if release.Availability != "" {
+		err := validateAvailability(release.Availability)
+		if err != nil {
+			return Release{}, err
+		}
+	}
+
+	err := validateExportControl(release.Controlled, release.ECCN, release.LicenseException)
+	if err != nil {
+		return Release{}, err
+	}
+
+	err = r.lintDescription(release.Description)
+	if err != nil {
+		return Release{}, err
+	}
+
 	url := fmt.Sprintf(
 		"/products/%s/releases/%d",
 		productSlug,
 		release.ID,
 	)
 
-	release.OSSCompliant = "confirm"
+	if release.SkipOSSCompliance {
+		release.OSSCompliant = ""
+	} else {
+		release.OSSCompliant = OSSCompliantConfirm
+	}
 
 	var updatedRelease = createReleaseBody{
 		Release: release,
 	}
 
-	body, err := json.Marshal(updatedRelease)
+	return r.patch(url, updatedRelease)
+}
+
+// releaseReadOnlyFields are the fields of Release that UpdateFields refuses
+// to send.
+var releaseReadOnlyFields = map[string]bool{
+	"id":         true,
+	"_links":     true,
+	"updated_at": true,
+}
+
+// UpdateFields updates the release, sending only the fields named in
+// fieldMask, by their JSON names, e.g. []string{"description",
+// "end_of_support_date"}. Fields in the mask are sent even if they are
+// empty, so a mask can clear a field, and nothing outside the mask is sent
+// even if it is set on release. Unlike Update, the OSS compliance
+// confirmation is only sent if "oss_compliant" is in the mask.
+//
+// An empty mask or one naming a field that is unknown or read-only is an
+// error, and nothing is sent.
+func (r ReleasesService) UpdateFields(productSlug string, release Release, fieldMask []string) (Release, error) {
+	if len(fieldMask) == 0 {
+		return Release{}, fmt.Errorf("Field mask is empty - no release fields to update")
+	}
+
+	fields := map[string]interface{}{}
+
+	v := reflect.ValueOf(release)
+	t := v.Type()
+	for _, name := range fieldMask {
+		found := false
+		for i := 0; i < t.NumField(); i++ {
+			fieldName := jsonFieldName(t.Field(i))
+			if fieldName == "" || releaseReadOnlyFields[fieldName] {
+				continue
+			}
+
+			if fieldName == name {
+				fields[name] = v.Field(i).Interface()
+				found = true
+				break
+			}
+		}
+
+		if !found {
+			return Release{}, fmt.Errorf("Unknown or read-only release field '%s' in field mask", name)
+		}
+	}
+
+	if _, ok := fields["availability"]; ok {
+		err := validateAvailability(release.Availability)
+		if err != nil {
+			return Release{}, err
+		}
+	}
+
+	// A controlled release needs an ECCN and license exception, which must
+	// be sent with the flag.
+	if _, ok := fields["controlled"]; ok {
+		var eccn, licenseException string
+		if _, ok := fields["eccn"]; ok {
+			eccn = release.ECCN
+		}
+		if _, ok := fields["license_exception"]; ok {
+			licenseException = release.LicenseException
+		}
+
+		err := validateExportControl(release.Controlled, eccn, licenseException)
+		if err != nil {
+			return Release{}, err
+		}
+	}
+
+	if _, ok := fields["description"]; ok {
+		err := r.lintDescription(release.Description)
+		if err != nil {
+			return Release{}, err
+		}
+	}
+
+	url := fmt.Sprintf(
+		"/products/%s/releases/%d",
+		productSlug,
+		release.ID,
+	)
+
+	return r.patch(url, map[string]interface{}{"release": fields})
+}
+
+// jsonFieldName returns the name of the field in JSON, or "" if it is not
+// encoded.
+func jsonFieldName(field reflect.StructField) string {
+	name := strings.Split(field.Tag.Get("json"), ",")[0]
+	if name == "-" {
+		return ""
+	}
+	return name
+}
+
+func (r ReleasesService) patch(url string, body interface{}) (Release, error) {
+	b, err := json.Marshal(body)
+	if err != nil {
+		// Untested as we cannot force an error because we are marshalling
+		// a known-good body
+		return Release{}, err
+	}
+
+	var response CreateReleaseResponse
+	resp, err := r.client.MakeRequest(
+		"PATCH",
+		url,
+		http.StatusOK,
+		bytes.NewReader(b),
+	)
+	if err != nil {
+		return Release{}, err
+	}
+	defer resp.Body.Close()
+
+	err = json.NewDecoder(resp.Body).Decode(&response)
+	if err != nil {
+		return Release{}, err
+	}
+
+	return response.Release, nil
+}
+
+// Publish makes the release available to all users and returns the updated
+// release. It first checks that the release has a EULA, has product files
+// and, if export controlled, has an ECCN and license exception, and returns
+// an error listing any problems without changing the release. A release that
+// is already available to all users is returned unchanged.
+func (r ReleasesService) Publish(productSlug string, releaseID int) (Release, error) {
+	release, err := r.Get(productSlug, releaseID)
+	if err != nil {
+		return Release{}, err
+	}
+
+	if release.Availability == AvailabilityAllUsers {
+		return release, nil
+	}
+
+	var problems []string
+
+	if release.EULA == nil || release.EULA.Slug == "" {
+		problems = append(problems, "no EULA")
+	}
+
+	err = validateExportControl(release.Controlled, release.ECCN, release.LicenseException)
+	if err != nil {
+		problems = append(problems, err.Error())
+	}
+
+	productFiles, err := ProductFilesService{client: r.client}.ListForRelease(productSlug, releaseID)
+	if err != nil {
+		return Release{}, err
+	}
+
+	if len(productFiles) == 0 {
+		problems = append(problems, "no product files")
+	}
+
+	if len(problems) > 0 {
+		return Release{}, fmt.Errorf(
+			"Release %d cannot be published: %s",
+			releaseID,
+			strings.Join(problems, "; "),
+		)
+	}
+
+	return r.Update(productSlug, Release{
+		ID:           releaseID,
+		Availability: AvailabilityAllUsers,
+	})
+}
+
+// SetEULA changes the EULA of the release to the EULA with the given slug,
+// leaving all other fields untouched, including the OSS compliance
+// confirmation. It returns ErrNotFound if there is no such EULA.
+func (r ReleasesService) SetEULA(productSlug string, releaseID int, eulaSlug string) (Release, error) {
+	eula, err := EULAsService{client: r.client}.Get(eulaSlug)
+	if err != nil {
+		var notFound ErrNotFound
+		if errors.As(err, &notFound) {
+			notFound.Message = fmt.Sprintf("EULA '%s' not found", eulaSlug)
+			return Release{}, notFound
+		}
+		return Release{}, err
+	}
+
+	return r.UpdateFields(productSlug, Release{
+		ID:   releaseID,
+		EULA: &EULA{Slug: eula.Slug},
+	}, []string{"eula"})
+}
+
+type exportControlBody struct {
+	Release exportControl `json:"release"`
+}
+
+type exportControl struct {
+	Controlled       bool   `json:"controlled"`
+	ECCN             string `json:"eccn,omitempty"`
+	LicenseException string `json:"license_exception,omitempty"`
+	OSSCompliant     string `json:"oss_compliant,omitempty"`
+}
+
+// SetExportControl sets whether the release is export-controlled. Unlike
+// Update, it always sends the controlled flag so it can also be cleared.
+// ECCN and licenseException are required when controlled is true.
+func (r ReleasesService) SetExportControl(
+	productSlug string,
+	releaseID int,
+	controlled bool,
+	eccn string,
+	licenseException string,
+) (Release, error) {
+	return r.SetExportControlWithOptions(productSlug, releaseID, ExportControlOptions{
+		Controlled:       controlled,
+		ECCN:             eccn,
+		LicenseException: licenseException,
+	})
+}
+
+type ExportControlOptions struct {
+	Controlled       bool
+	ECCN             string
+	LicenseException string
+
+	// SkipOSSCompliance omits the OSS compliance confirmation, as
+	// Release.SkipOSSCompliance does for Update.
+	SkipOSSCompliance bool
+}
+
+// SetExportControlWithOptions behaves like SetExportControl, taking the
+// export control fields from the options.
+func (r ReleasesService) SetExportControlWithOptions(
+	productSlug string,
+	releaseID int,
+	options ExportControlOptions,
+) (Release, error) {
+	err := validateExportControl(options.Controlled, options.ECCN, options.LicenseException)
+	if err != nil {
+		return Release{}, err
+	}
+
+	ossCompliant := OSSCompliantConfirm
+	if options.SkipOSSCompliance {
+		ossCompliant = ""
+	}
+
+	url := fmt.Sprintf(
+		"/products/%s/releases/%d",
+		productSlug,
+		releaseID,
+	)
+
+	body, err := json.Marshal(exportControlBody{
+		Release: exportControl{
+			Controlled:       options.Controlled,
+			ECCN:             options.ECCN,
+			LicenseException: options.LicenseException,
+			OSSCompliant:     ossCompliant,
+		},
+	})
 	if err != nil {
 		// Untested as we cannot force an error because we are marshalling
 		// a known-good body
@@ -217,3 +998,9 @@ func (r ReleasesService) Delete(productSlug string, release Release) error {
 
 	return nil
 }
+
+// DeleteIfExists deletes the release, treating a release that does not
+// exist as already deleted. Use Delete to find out whether it existed.
+func (r ReleasesService) DeleteIfExists(productSlug string, release Release) error {
+	return ignoreNotFound(r.Delete(productSlug, release))
+}
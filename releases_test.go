@@ -1,6 +1,7 @@
 package pivnet_test
 
 import (
+	"errors"
 	"fmt"
 	"net/http"
 	"time"
@@ -101,57 +102,689 @@ var _ = Describe("PivnetClient - product files", func() {
 		})
 	})
 
+	Context("when getting a release that does not exist", func() {
+		It("returns an error matching ErrNotFound", func() {
+			server.AppendHandlers(
+				ghttp.CombineHandlers(
+					ghttp.VerifyRequest("GET", fmt.Sprintf("%s/products/%s/releases/%d", apiPrefix, productSlug, 12)),
+					ghttp.RespondWith(http.StatusNotFound, `{"message":"not found"}`),
+				),
+			)
+
+			_, err := client.Releases.Get(productSlug, 12)
+			Expect(errors.Is(err, pivnet.ErrNotFound{})).To(BeTrue())
+		})
+	})
+
 	Describe("Get", func() {
 		It("returns the release for the product slug and releaseID", func() {
 			response := `{"id": 3, "version": "3.2.1", "_links": {"product_files": {"href":"https://banana.org/cookies/download"}}}`
 
-			server.AppendHandlers(
-				ghttp.CombineHandlers(
-					ghttp.VerifyRequest("GET", apiPrefix+"/products/banana/releases/3"),
-					ghttp.RespondWith(http.StatusOK, response),
-				),
-			)
+			server.AppendHandlers(
+				ghttp.CombineHandlers(
+					ghttp.VerifyRequest("GET", apiPrefix+"/products/banana/releases/3"),
+					ghttp.RespondWith(http.StatusOK, response),
+				),
+			)
+
+			release, err := client.Releases.Get("banana", 3)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(release.ID).To(Equal(3))
+		})
+
+		It("keeps fields that are not modelled in AdditionalFields", func() {
+			response := `{"id": 3, "version": "3.2.1", "new_field": {"a": 1}, "other": "b"}`
+
+			server.AppendHandlers(
+				ghttp.CombineHandlers(
+					ghttp.VerifyRequest("GET", apiPrefix+"/products/banana/releases/3"),
+					ghttp.RespondWith(http.StatusOK, response),
+				),
+			)
+
+			release, err := client.Releases.Get("banana", 3)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(release.Version).To(Equal("3.2.1"))
+
+			Expect(release.AdditionalFields).To(HaveLen(2))
+			Expect(release.AdditionalFields["new_field"]).To(MatchJSON(`{"a": 1}`))
+			Expect(release.AdditionalFields["other"]).To(MatchJSON(`"b"`))
+		})
+
+		Context("when the server responds with a non-2XX status code", func() {
+			var (
+				body []byte
+			)
+
+			BeforeEach(func() {
+				body = []byte(`{"message":"foo message"}`)
+			})
+
+			It("returns an error", func() {
+				server.AppendHandlers(
+					ghttp.CombineHandlers(
+						ghttp.VerifyRequest("GET", apiPrefix+"/products/banana/releases/3"),
+						ghttp.RespondWith(http.StatusTeapot, body),
+					),
+				)
+
+				_, err := client.Releases.Get("banana", 3)
+				Expect(err.Error()).To(ContainSubstring("foo message"))
+			})
+		})
+
+		Context("when the json unmarshalling fails with error", func() {
+			It("forwards the error", func() {
+				server.AppendHandlers(
+					ghttp.CombineHandlers(
+						ghttp.VerifyRequest("GET", apiPrefix+"/products/banana/releases/3"),
+						ghttp.RespondWith(http.StatusTeapot, "%%%"),
+					),
+				)
+
+				_, err := client.Releases.Get("banana", 3)
+				Expect(err).To(HaveOccurred())
+
+				Expect(err.Error()).To(ContainSubstring("invalid character"))
+			})
+		})
+	})
+
+	Describe("GetMany", func() {
+		BeforeEach(func() {
+			server.RouteToHandler("GET", apiPrefix+"/products/banana/releases/1",
+				ghttp.RespondWith(http.StatusOK, `{"id": 1, "version": "1.0.0"}`),
+			)
+			server.RouteToHandler("GET", apiPrefix+"/products/banana/releases/2",
+				ghttp.RespondWith(http.StatusOK, `{"id": 2, "version": "2.0.0"}`),
+			)
+			server.RouteToHandler("GET", apiPrefix+"/products/banana/releases/3",
+				ghttp.RespondWith(http.StatusTeapot, `{"message":"foo message"}`),
+			)
+		})
+
+		It("returns the releases keyed by ID", func() {
+			releases, err := client.Releases.GetMany("banana", []int{1, 2}, 2)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(releases).To(HaveLen(2))
+			Expect(releases[1].Version).To(Equal("1.0.0"))
+			Expect(releases[2].Version).To(Equal("2.0.0"))
+		})
+
+		Context("when fetching some releases fails", func() {
+			It("returns the partial results and a MultiError keyed by ID", func() {
+				releases, err := client.Releases.GetMany("banana", []int{1, 2, 3}, 1)
+				Expect(releases).To(HaveLen(2))
+
+				var multiErr pivnet.MultiError
+				Expect(errors.As(err, &multiErr)).To(BeTrue())
+				Expect(multiErr.Errors).To(HaveLen(1))
+				Expect(multiErr.Errors[0].ID).To(Equal(3))
+				Expect(multiErr.Errors[0].Err.Error()).To(ContainSubstring("foo message"))
+			})
+		})
+
+		Context("when no IDs are provided", func() {
+			It("returns empty results without making requests", func() {
+				releases, err := client.Releases.GetMany("banana", nil, 4)
+				Expect(err).NotTo(HaveOccurred())
+				Expect(releases).To(BeEmpty())
+				Expect(server.ReceivedRequests()).To(BeEmpty())
+			})
+		})
+	})
+
+	Describe("Links", func() {
+		It("returns the raw links for the release", func() {
+			response := `{"id": 3, "_links": {"self": {"href":"https://banana.org/releases/3"}, "file_groups": {"href":"https://banana.org/releases/3/file_groups"}}}`
+
+			server.AppendHandlers(
+				ghttp.CombineHandlers(
+					ghttp.VerifyRequest("GET", apiPrefix+"/products/banana/releases/3"),
+					ghttp.RespondWith(http.StatusOK, response),
+				),
+			)
+
+			links, err := client.Releases.Links("banana", 3)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(links).To(HaveLen(2))
+
+			href, err := links.Href("file_groups")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(href).To(Equal("https://banana.org/releases/3/file_groups"))
+		})
+
+		Context("when the link is not present", func() {
+			It("returns an error resolving the link", func() {
+				server.AppendHandlers(
+					ghttp.CombineHandlers(
+						ghttp.VerifyRequest("GET", apiPrefix+"/products/banana/releases/3"),
+						ghttp.RespondWith(http.StatusOK, `{"id": 3}`),
+					),
+				)
+
+				links, err := client.Releases.Links("banana", 3)
+				Expect(err).NotTo(HaveOccurred())
+				Expect(links).To(BeEmpty())
+
+				_, err = links.Href("user_groups")
+				Expect(err).To(MatchError(ContainSubstring("user_groups")))
+			})
+		})
+
+		Context("when the server responds with a non-2XX status code", func() {
+			It("returns an error", func() {
+				server.AppendHandlers(
+					ghttp.CombineHandlers(
+						ghttp.VerifyRequest("GET", apiPrefix+"/products/banana/releases/3"),
+						ghttp.RespondWith(http.StatusTeapot, `{"message":"foo message"}`),
+					),
+				)
+
+				_, err := client.Releases.Links("banana", 3)
+				Expect(err.Error()).To(ContainSubstring("foo message"))
+			})
+		})
+	})
+
+	Describe("Exists", func() {
+		BeforeEach(func() {
+			server.RouteToHandler("GET", apiPrefix+"/products/banana/releases",
+				ghttp.RespondWith(http.StatusOK, `{"releases": [{"id":1,"version":"1.0.0"}]}`),
+			)
+		})
+
+		It("returns true when the version exists", func() {
+			exists, err := client.Releases.Exists("banana", "1.0.0")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(exists).To(BeTrue())
+		})
+
+		It("returns false without an error when the version does not exist", func() {
+			exists, err := client.Releases.Exists("banana", "2.0.0")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(exists).To(BeFalse())
+		})
+
+		Context("when the product does not exist", func() {
+			It("returns an ErrNotFound", func() {
+				server.RouteToHandler("GET", apiPrefix+"/products/cherry/releases",
+					ghttp.RespondWith(http.StatusNotFound, `{"message":"product not found"}`),
+				)
+
+				exists, err := client.Releases.Exists("cherry", "1.0.0")
+				Expect(errors.Is(err, pivnet.ErrNotFound{})).To(BeTrue())
+				Expect(exists).To(BeFalse())
+			})
+		})
+
+		Context("when the request fails", func() {
+			It("returns the error", func() {
+				server.RouteToHandler("GET", apiPrefix+"/products/cherry/releases",
+					ghttp.RespondWith(http.StatusTeapot, `{"message":"foo message"}`),
+				)
+
+				_, err := client.Releases.Exists("cherry", "1.0.0")
+				Expect(err).To(MatchError(ContainSubstring("foo message")))
+			})
+		})
+	})
+
+	Describe("DistinctTypes", func() {
+		It("returns the release types with their counts", func() {
+			server.AppendHandlers(
+				ghttp.CombineHandlers(
+					ghttp.VerifyRequest("GET", apiPrefix+"/products/banana/releases"),
+					ghttp.RespondWith(http.StatusOK, `{"releases": [
+						{"id":1,"release_type":"Major Release"},
+						{"id":2,"release_type":"Minor Release"},
+						{"id":3,"release_type":"Minor Release"},
+						{"id":4}
+					]}`),
+				),
+			)
+
+			types, err := client.Releases.DistinctTypes("banana")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(types).To(Equal(map[pivnet.ReleaseType]int{
+				"Major Release": 1,
+				"Minor Release": 2,
+			}))
+		})
+
+		Context("when the product has no releases", func() {
+			It("returns an empty map", func() {
+				server.AppendHandlers(
+					ghttp.RespondWith(http.StatusOK, `{"releases": []}`),
+				)
+
+				types, err := client.Releases.DistinctTypes("banana")
+				Expect(err).NotTo(HaveOccurred())
+				Expect(types).NotTo(BeNil())
+				Expect(types).To(BeEmpty())
+			})
+		})
+
+		Context("when the server responds with a non-2XX status code", func() {
+			It("returns an error", func() {
+				server.AppendHandlers(
+					ghttp.RespondWith(http.StatusTeapot, `{"message":"foo message"}`),
+				)
+
+				_, err := client.Releases.DistinctTypes("banana")
+				Expect(err).To(MatchError(ContainSubstring("foo message")))
+			})
+		})
+	})
+
+	Describe("ListBetween", func() {
+		var (
+			start time.Time
+			end   time.Time
+		)
+
+		BeforeEach(func() {
+			start = time.Date(2016, 4, 1, 0, 0, 0, 0, time.UTC)
+			end = time.Date(2016, 7, 1, 0, 0, 0, 0, time.UTC)
+		})
+
+		It("returns the releases dated within the range", func() {
+			server.AppendHandlers(
+				ghttp.CombineHandlers(
+					ghttp.VerifyRequest("GET", apiPrefix+"/products/banana/releases"),
+					ghttp.RespondWith(http.StatusOK, `{"releases": [
+						{"id":1,"release_date":"2016-03-31"},
+						{"id":2,"release_date":"2016-04-01"},
+						{"id":3,"release_date":"2016-06-30"},
+						{"id":4,"release_date":"2016-07-01"},
+						{"id":5,"release_date":"not-a-date"},
+						{"id":6}
+					]}`),
+				),
+			)
+
+			releases, err := client.Releases.ListBetween("banana", start, end)
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(releases).To(HaveLen(2))
+			Expect(releases[0].ID).To(Equal(2))
+			Expect(releases[1].ID).To(Equal(3))
+		})
+
+		Context("when the server responds with a non-2XX status code", func() {
+			It("returns an error", func() {
+				server.AppendHandlers(
+					ghttp.RespondWith(http.StatusTeapot, `{"message":"foo message"}`),
+				)
+
+				_, err := client.Releases.ListBetween("banana", start, end)
+				Expect(err).To(MatchError(ContainSubstring("foo message")))
+			})
+		})
+	})
+
+	Describe("TotalSize", func() {
+		It("sums the sizes of the product files of the release", func() {
+			server.AppendHandlers(
+				ghttp.CombineHandlers(
+					ghttp.VerifyRequest("GET", apiPrefix+"/products/banana/releases/3/product_files"),
+					ghttp.RespondWith(http.StatusOK, `{"product_files": [
+						{"id":1,"size":1024},
+						{"id":2,"size":4294967296},
+						{"id":3,"name":"unsized"}
+					]}`),
+				),
+			)
+
+			total, files, err := client.Releases.TotalSize("banana", 3)
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(total).To(Equal(int64(4294968320)))
+			Expect(files).To(Equal(3))
+
+			fake := fakeLogger.(*loggerfakes.FakeLogger)
+			Expect(fake.InfoCallCount()).To(Equal(1))
+			action, data := fake.InfoArgsForCall(0)
+			Expect(action).To(ContainSubstring("no size"))
+			Expect(data[0]).To(HaveKeyWithValue("product_file_id", 3))
+		})
+
+		Context("when the product files cannot be listed", func() {
+			It("returns an error", func() {
+				server.AppendHandlers(
+					ghttp.RespondWith(http.StatusTeapot, `{"message":"foo message"}`),
+				)
+
+				_, _, err := client.Releases.TotalSize("banana", 3)
+				Expect(err).To(MatchError(ContainSubstring("foo message")))
+			})
+		})
+	})
+
+	Describe("ListNearingEndOfSupport", func() {
+		BeforeEach(func() {
+			newClientConfig.Clock = func() time.Time {
+				return time.Date(2016, time.June, 15, 12, 0, 0, 0, time.UTC)
+			}
+			client = pivnet.NewClient(newClientConfig, fakeLogger)
+		})
+
+		It("returns the releases whose end of support falls within the window, soonest first", func() {
+			server.AppendHandlers(
+				ghttp.CombineHandlers(
+					ghttp.VerifyRequest("GET", apiPrefix+"/products/banana/releases"),
+					ghttp.RespondWith(http.StatusOK, `{"releases": [
+						{"id":1,"end_of_support_date":"2016-06-14"},
+						{"id":2,"end_of_support_date":"2016-07-01"},
+						{"id":3,"end_of_support_date":"2016-06-15"},
+						{"id":4,"end_of_support_date":"2016-07-16"},
+						{"id":5,"end_of_support_date":"not-a-date"},
+						{"id":6}
+					]}`),
+				),
+			)
+
+			releases, err := client.Releases.ListNearingEndOfSupport("banana", 30*24*time.Hour)
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(releases).To(HaveLen(2))
+			Expect(releases[0].ID).To(Equal(3))
+			Expect(releases[1].ID).To(Equal(2))
+		})
+
+		Context("when the server responds with a non-2XX status code", func() {
+			It("returns an error", func() {
+				server.AppendHandlers(
+					ghttp.RespondWith(http.StatusTeapot, `{"message":"foo message"}`),
+				)
+
+				_, err := client.Releases.ListNearingEndOfSupport("banana", time.Hour)
+				Expect(err).To(MatchError(ContainSubstring("foo message")))
+			})
+		})
+	})
+
+	Describe("Release timestamps", func() {
+		It("parses the release date and updated timestamp", func() {
+			release := pivnet.Release{
+				ReleaseDate: "2016-01-02",
+				UpdatedAt:   "2016-01-02T03:04:05.000Z",
+			}
+
+			releaseDate, err := release.ReleaseDateTime()
+			Expect(err).NotTo(HaveOccurred())
+			Expect(releaseDate).To(Equal(time.Date(2016, 1, 2, 0, 0, 0, 0, time.UTC)))
+
+			updatedAt, err := release.UpdatedAtTime()
+			Expect(err).NotTo(HaveOccurred())
+			Expect(updatedAt.Equal(time.Date(2016, 1, 2, 3, 4, 5, 0, time.UTC))).To(BeTrue())
+		})
+	})
+
+	Describe("LatestVersion", func() {
+		var (
+			response           string
+			responseStatusCode int
+		)
+
+		BeforeEach(func() {
+			responseStatusCode = http.StatusOK
+			response = `{"releases": [
+				{"id":1,"version":"1.9.0"},
+				{"id":2,"version":"2.1.0"},
+				{"id":3,"version":"2.10.1"},
+				{"id":4,"version":"not-a-version"},
+				{"id":5,"version":"3.0.0-rc.1"},
+				{"id":6,"version":"2.10.1"},
+				{"id":7,"version":"Version 2.10.0"}
+			]}`
+		})
+
+		JustBeforeEach(func() {
+			server.AppendHandlers(
+				ghttp.CombineHandlers(
+					ghttp.VerifyRequest("GET", apiPrefix+"/products/banana/releases"),
+					ghttp.RespondWith(responseStatusCode, response),
+				),
+			)
+		})
+
+		It("returns the newest release, ignoring unparseable versions and prereleases", func() {
+			release, err := client.Releases.LatestVersion("banana", "")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(release.ID).To(Equal(3))
+		})
+
+		Context("when a wildcard constraint is provided", func() {
+			It("returns the newest release matching the constraint", func() {
+				release, err := client.Releases.LatestVersion("banana", "2.x")
+				Expect(err).NotTo(HaveOccurred())
+				Expect(release.ID).To(Equal(3))
+			})
+
+			Context("when a newer prerelease matches the wildcard", func() {
+				BeforeEach(func() {
+					response = `{"releases": [
+						{"id":1,"version":"2.5.0"},
+						{"id":2,"version":"2.6.0-rc.1"}
+					]}`
+				})
+
+				It("returns the newest GA release", func() {
+					release, err := client.Releases.LatestVersion("banana", "2.x")
+					Expect(err).NotTo(HaveOccurred())
+					Expect(release.ID).To(Equal(1))
+				})
+			})
+		})
+
+		Context("when the constraint names a prerelease", func() {
+			It("returns prereleases of that version", func() {
+				release, err := client.Releases.LatestVersion("banana", ">= 3.0.0-rc.0")
+				Expect(err).NotTo(HaveOccurred())
+				Expect(release.ID).To(Equal(5))
+			})
+		})
+
+		Context("when a range constraint is provided", func() {
+			It("returns the newest release matching the constraint", func() {
+				release, err := client.Releases.LatestVersion("banana", ">= 1.0, < 2.10")
+				Expect(err).NotTo(HaveOccurred())
+				Expect(release.ID).To(Equal(2))
+			})
+		})
+
+		Context("when a version has a 'version' prefix", func() {
+			It("ignores the prefix", func() {
+				release, err := client.Releases.LatestVersion("banana", "2.10.0")
+				Expect(err).NotTo(HaveOccurred())
+				Expect(release.ID).To(Equal(7))
+			})
+		})
+
+		Context("when no release matches the constraint", func() {
+			It("returns an ErrNotFound", func() {
+				_, err := client.Releases.LatestVersion("banana", "4.x")
+				Expect(err).To(HaveOccurred())
+				Expect(err).To(BeAssignableToTypeOf(pivnet.ErrNotFound{}))
+			})
+		})
+
+		Context("when the constraint is invalid", func() {
+			It("returns an error without making a request", func() {
+				_, err := client.Releases.LatestVersion("banana", ">= banana")
+				Expect(err).To(HaveOccurred())
+				Expect(err.Error()).To(ContainSubstring("Invalid version constraint"))
+
+				Expect(server.ReceivedRequests()).To(BeEmpty())
+			})
+		})
+
+		Context("when the server responds with a non-2XX status code", func() {
+			BeforeEach(func() {
+				response = `{"message":"foo message"}`
+				responseStatusCode = http.StatusTeapot
+			})
+
+			It("returns an error", func() {
+				_, err := client.Releases.LatestVersion("banana", "")
+				Expect(err.Error()).To(ContainSubstring("foo message"))
+			})
+		})
+	})
+
+	Describe("CreateReleaseConfig.Validate", func() {
+		var (
+			config       pivnet.CreateReleaseConfig
+			releaseTypes []pivnet.ReleaseType
+		)
+
+		BeforeEach(func() {
+			config = pivnet.CreateReleaseConfig{
+				ProductSlug: productSlug,
+				Version:     "1.2.3",
+				EULASlug:    "some_eula",
+				ReleaseType: string(pivnet.ReleaseTypeMinor),
+			}
+
+			releaseTypes = []pivnet.ReleaseType{
+				pivnet.ReleaseTypeMajor,
+				pivnet.ReleaseTypeMinor,
+			}
+		})
+
+		It("accepts a valid config", func() {
+			Expect(config.Validate(releaseTypes)).To(Succeed())
+		})
+
+		Context("when the release type is not valid", func() {
+			BeforeEach(func() {
+				config.ReleaseType = "Not a real release"
+			})
+
+			It("returns an error listing the valid types", func() {
+				err := config.Validate(releaseTypes)
+				Expect(err).To(MatchError(
+					"Invalid release type 'Not a real release' - must be one of: 'Major Release', 'Minor Release'",
+				))
+			})
 
-			release, err := client.Releases.Get("banana", 3)
-			Expect(err).NotTo(HaveOccurred())
-			Expect(release.ID).To(Equal(3))
+			Context("when no release types are provided", func() {
+				It("does not check the release type", func() {
+					Expect(config.Validate(nil)).To(Succeed())
+				})
+			})
 		})
 
-		Context("when the server responds with a non-2XX status code", func() {
-			var (
-				body []byte
-			)
+		Context("when the release is controlled without an ECCN", func() {
+			BeforeEach(func() {
+				config.Controlled = true
+				config.LicenseException = "NLR"
+			})
+
+			It("returns an error", func() {
+				Expect(config.Validate(nil)).To(MatchError(ContainSubstring("ECCN and license exception")))
+			})
+		})
 
+		Context("when the version is empty", func() {
 			BeforeEach(func() {
-				body = []byte(`{"message":"foo message"}`)
+				config.Version = ""
 			})
 
 			It("returns an error", func() {
-				server.AppendHandlers(
-					ghttp.CombineHandlers(
-						ghttp.VerifyRequest("GET", apiPrefix+"/products/banana/releases/3"),
-						ghttp.RespondWith(http.StatusTeapot, body),
-					),
+				Expect(config.Validate(nil)).To(MatchError("Version must not be empty"))
+			})
+		})
+	})
+
+	Describe("ListWithEULAs", func() {
+		var (
+			releasesURL string
+		)
+
+		BeforeEach(func() {
+			releasesURL = fmt.Sprintf("%s/products/%s/releases", apiPrefix, productSlug)
+
+			server.RouteToHandler("GET", releasesURL,
+				ghttp.RespondWith(http.StatusOK, `{"releases":[{"id":1},{"id":2,"eula":{"slug":"listed-eula"}},{"id":3}]}`),
+			)
+			server.RouteToHandler("GET", releasesURL+"/1",
+				ghttp.RespondWith(http.StatusOK, `{"id":1,"eula":{"slug":"eula-one"}}`),
+			)
+		})
+
+		It("fetches the EULA of releases listed without one", func() {
+			server.RouteToHandler("GET", releasesURL+"/3",
+				ghttp.RespondWith(http.StatusOK, `{"id":3,"eula":{"slug":"eula-three"}}`),
+			)
+
+			releases, err := client.Releases.ListWithEULAs(productSlug, 2)
+			Expect(err).NotTo(HaveOccurred())
+
+			var slugs []string
+			for _, release := range releases {
+				slugs = append(slugs, release.EULA.Slug)
+			}
+			Expect(slugs).To(Equal([]string{"eula-one", "listed-eula", "eula-three"}))
+
+			Expect(server.ReceivedRequests()).To(HaveLen(3))
+		})
+
+		Context("when fetching a release returns an error", func() {
+			It("returns the error", func() {
+				server.RouteToHandler("GET", releasesURL+"/3",
+					ghttp.RespondWith(http.StatusTeapot, `{"message":"foo message"}`),
 				)
 
-				_, err := client.Releases.Get("banana", 3)
-				Expect(err.Error()).To(ContainSubstring("foo message"))
+				_, err := client.Releases.ListWithEULAs(productSlug, 2)
+				Expect(err).To(BeAssignableToTypeOf(pivnet.MultiError{}))
+				Expect(err).To(MatchError(ContainSubstring("foo message")))
 			})
 		})
+	})
 
-		Context("when the json unmarshalling fails with error", func() {
-			It("forwards the error", func() {
-				server.AppendHandlers(
-					ghttp.CombineHandlers(
-						ghttp.VerifyRequest("GET", apiPrefix+"/products/banana/releases/3"),
-						ghttp.RespondWith(http.StatusTeapot, "%%%"),
-					),
-				)
+	Describe("GetByVersion", func() {
+		BeforeEach(func() {
+			server.AppendHandlers(
+				ghttp.CombineHandlers(
+					ghttp.VerifyRequest("GET", fmt.Sprintf("%s/products/%s/releases", apiPrefix, productSlug)),
+					ghttp.RespondWith(http.StatusOK, `{"releases":[{"id":1,"version":"1.0.0"},{"id":2,"version":"2.0.0"}]}`),
+				),
+			)
+		})
 
-				_, err := client.Releases.Get("banana", 3)
-				Expect(err).To(HaveOccurred())
+		It("returns the release with the version", func() {
+			release, err := client.Releases.GetByVersion(productSlug, "2.0.0")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(release).To(Equal(pivnet.Release{ID: 2, Version: "2.0.0"}))
+		})
 
-				Expect(err.Error()).To(ContainSubstring("invalid character"))
+		It("ignores whitespace around the version", func() {
+			release, err := client.Releases.GetByVersion(productSlug, " 2.0.0\n")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(release.ID).To(Equal(2))
+		})
+
+		Context("when a version prefix is configured", func() {
+			BeforeEach(func() {
+				newClientConfig.VersionPrefix = "version "
+				client = pivnet.NewClient(newClientConfig, fakeLogger)
+			})
+
+			It("ignores the prefix", func() {
+				release, err := client.Releases.GetByVersion(productSlug, "Version 2.0.0")
+				Expect(err).NotTo(HaveOccurred())
+				Expect(release.ID).To(Equal(2))
+			})
+		})
+
+		Context("when no release has the version", func() {
+			It("returns an ErrNotFound listing the available versions", func() {
+				_, err := client.Releases.GetByVersion(productSlug, "3.0.0")
+				Expect(err).To(BeAssignableToTypeOf(pivnet.ErrNotFound{}))
+				Expect(err.Error()).To(ContainSubstring("3.0.0"))
+				Expect(err.Error()).To(ContainSubstring("available versions: '1.0.0', '2.0.0'"))
 			})
 		})
 	})
@@ -186,7 +819,12 @@ var _ = Describe("PivnetClient - product files", func() {
 			)
 
 			BeforeEach(func() {
-				expectedReleaseDate = time.Now().Format("2006-01-02")
+				newClientConfig.Clock = func() time.Time {
+					return time.Date(2016, time.February, 3, 4, 5, 6, 0, time.UTC)
+				}
+				client = pivnet.NewClient(newClientConfig, fakeLogger)
+
+				expectedReleaseDate = "2016-02-03"
 
 				expectedRequestBody = requestBody{
 					Release: pivnet.Release{
@@ -342,15 +980,261 @@ var _ = Describe("PivnetClient - product files", func() {
 							),
 						)
 
-						release, err := client.Releases.Create(createReleaseConfig)
-						Expect(err).NotTo(HaveOccurred())
-						Expect(release.Version).To(Equal(releaseVersion))
-					})
-				})
+						release, err := client.Releases.Create(createReleaseConfig)
+						Expect(err).NotTo(HaveOccurred())
+						Expect(release.Version).To(Equal(releaseVersion))
+					})
+				})
+			})
+		})
+
+		Context("when SkipOSSCompliance is set", func() {
+			It("creates the release without confirming OSS compliance", func() {
+				createReleaseConfig.ReleaseDate = "2015-12-24"
+				createReleaseConfig.SkipOSSCompliance = true
+
+				server.AppendHandlers(
+					ghttp.CombineHandlers(
+						ghttp.VerifyRequest("POST", apiPrefix+"/products/"+productSlug+"/releases"),
+						ghttp.VerifyJSON(`{"release":{"availability":"Admins Only","eula":{"slug":"some_eula"},"release_date":"2015-12-24","release_type":"Not a real release","version":"1.2.3.4"}}`),
+						ghttp.RespondWith(http.StatusCreated, `{"release": {"id": 3, "version": "1.2.3.4"}}`),
+					),
+				)
+
+				_, err := client.Releases.Create(createReleaseConfig)
+				Expect(err).NotTo(HaveOccurred())
+			})
+		})
+
+		Context("when the server responds with a non-201 status code", func() {
+			var (
+				body []byte
+			)
+
+			BeforeEach(func() {
+				body = []byte(`{"message":"foo message"}`)
+			})
+
+			It("returns an error", func() {
+				server.AppendHandlers(
+					ghttp.CombineHandlers(
+						ghttp.VerifyRequest("POST", apiPrefix+"/products/"+productSlug+"/releases"),
+						ghttp.RespondWith(http.StatusTeapot, body),
+					),
+				)
+
+				_, err := client.Releases.Create(createReleaseConfig)
+				Expect(err.Error()).To(ContainSubstring("foo message"))
+			})
+		})
+
+		Context("when the json unmarshalling fails with error", func() {
+			It("forwards the error", func() {
+				server.AppendHandlers(
+					ghttp.CombineHandlers(
+						ghttp.VerifyRequest("POST", apiPrefix+"/products/"+productSlug+"/releases"),
+						ghttp.RespondWith(http.StatusTeapot, "%%%"),
+					),
+				)
+
+				_, err := client.Releases.Create(createReleaseConfig)
+				Expect(err).To(HaveOccurred())
+
+				Expect(err.Error()).To(ContainSubstring("invalid character"))
+			})
+		})
+	})
+
+	Describe("Publish", func() {
+		var (
+			releaseURL   string
+			release      string
+			productFiles string
+		)
+
+		BeforeEach(func() {
+			releaseURL = fmt.Sprintf("%s/products/%s/releases/%d", apiPrefix, "banana-slug", 42)
+			release = `{"id": 42, "availability": "Admins Only", "eula": {"slug": "some-eula"}}`
+			productFiles = `{"product_files": [{"id": 1}]}`
+		})
+
+		JustBeforeEach(func() {
+			server.RouteToHandler("GET", releaseURL, ghttp.RespondWith(http.StatusOK, release))
+			server.RouteToHandler("GET", releaseURL+"/product_files", ghttp.RespondWith(http.StatusOK, productFiles))
+		})
+
+		It("makes the release available to all users", func() {
+			server.RouteToHandler("PATCH", releaseURL, ghttp.CombineHandlers(
+				ghttp.VerifyJSON(`{"release":{"id": 42, "availability": "All Users", "oss_compliant":"confirm"}}`),
+				ghttp.RespondWith(http.StatusOK, `{"release": {"id": 42, "availability": "All Users"}}`),
+			))
+
+			published, err := client.Releases.Publish("banana-slug", 42)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(published.Availability).To(Equal(pivnet.AvailabilityAllUsers))
+		})
+
+		Context("when the release is already available to all users", func() {
+			BeforeEach(func() {
+				release = `{"id": 42, "availability": "All Users"}`
+			})
+
+			It("returns the release without updating it", func() {
+				published, err := client.Releases.Publish("banana-slug", 42)
+				Expect(err).NotTo(HaveOccurred())
+				Expect(published.ID).To(Equal(42))
+
+				Expect(server.ReceivedRequests()).To(HaveLen(1))
+			})
+		})
+
+		Context("when the release is not publishable", func() {
+			BeforeEach(func() {
+				release = `{"id": 42, "availability": "Admins Only", "controlled": true}`
+				productFiles = `{"product_files": []}`
+			})
+
+			It("returns an error listing the problems without updating the release", func() {
+				_, err := client.Releases.Publish("banana-slug", 42)
+				Expect(err).To(MatchError(
+					"Release 42 cannot be published: no EULA; ECCN and license exception must be provided for controlled releases; no product files",
+				))
+
+				Expect(server.ReceivedRequests()).To(HaveLen(2))
+			})
+		})
+	})
+
+	Describe("Update", func() {
+		It("submits the updated values for a release with OSS compliance", func() {
+			release := pivnet.Release{
+				ID:      42,
+				Version: "1.2.3.4",
+				EULA: &pivnet.EULA{
+					Slug: "some-eula",
+					ID:   15,
+				},
+			}
+
+			patchURL := fmt.Sprintf("%s/products/%s/releases/%d", apiPrefix, "banana-slug", release.ID)
+
+			response := `{"release": {"id": 42, "version": "1.2.3.4"}}`
+			server.AppendHandlers(
+				ghttp.CombineHandlers(
+					ghttp.VerifyRequest("PATCH", patchURL),
+					ghttp.VerifyJSON(`{"release":{"id": 42, "version": "1.2.3.4", "eula":{"slug":"some-eula","id":15}, "oss_compliant":"confirm"}}`),
+					ghttp.RespondWith(http.StatusOK, response),
+				),
+			)
+
+			release, err := client.Releases.Update("banana-slug", release)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(release.Version).To(Equal("1.2.3.4"))
+		})
+
+		Context("when SkipOSSCompliance is set", func() {
+			It("submits the updated values without OSS compliance", func() {
+				release := pivnet.Release{
+					ID:                42,
+					Version:           "1.2.3.4",
+					OSSCompliant:      "confirm",
+					SkipOSSCompliance: true,
+				}
+
+				patchURL := fmt.Sprintf("%s/products/%s/releases/%d", apiPrefix, "banana-slug", release.ID)
+
+				server.AppendHandlers(
+					ghttp.CombineHandlers(
+						ghttp.VerifyRequest("PATCH", patchURL),
+						ghttp.VerifyJSON(`{"release":{"id": 42, "version": "1.2.3.4"}}`),
+						ghttp.RespondWith(http.StatusOK, `{"release": {"id": 42, "version": "1.2.3.4"}}`),
+					),
+				)
+
+				_, err := client.Releases.Update("banana-slug", release)
+				Expect(err).NotTo(HaveOccurred())
+			})
+		})
+
+		Context("when notification flags are set", func() {
+			It("submits the flags", func() {
+				release := pivnet.Release{
+					ID:                   42,
+					SoftwareFilesUpdated: true,
+					FixesReleased:        true,
+				}
+
+				patchURL := fmt.Sprintf("%s/products/%s/releases/%d", apiPrefix, "banana-slug", release.ID)
+
+				server.AppendHandlers(
+					ghttp.CombineHandlers(
+						ghttp.VerifyRequest("PATCH", patchURL),
+						ghttp.VerifyJSON(`{"release":{"id": 42, "software_files_updated": true, "fixes_released": true, "oss_compliant":"confirm"}}`),
+						ghttp.RespondWith(http.StatusOK, `{"release": {"id": 42}}`),
+					),
+				)
+
+				_, err := client.Releases.Update("banana-slug", release)
+				Expect(err).NotTo(HaveOccurred())
+			})
+		})
+
+		Context("when a valid availability is provided", func() {
+			It("submits the availability", func() {
+				release := pivnet.Release{
+					ID:           42,
+					Availability: pivnet.AvailabilityAllUsers,
+				}
+
+				patchURL := fmt.Sprintf("%s/products/%s/releases/%d", apiPrefix, "banana-slug", release.ID)
+
+				response := `{"release": {"id": 42, "availability": "All Users"}}`
+				server.AppendHandlers(
+					ghttp.CombineHandlers(
+						ghttp.VerifyRequest("PATCH", patchURL),
+						ghttp.VerifyJSON(`{"release":{"id": 42, "availability": "All Users", "oss_compliant":"confirm"}}`),
+						ghttp.RespondWith(http.StatusOK, response),
+					),
+				)
+
+				release, err := client.Releases.Update("banana-slug", release)
+				Expect(err).NotTo(HaveOccurred())
+				Expect(release.Availability).To(Equal(pivnet.AvailabilityAllUsers))
+			})
+		})
+
+		Context("when an unknown availability is provided", func() {
+			It("returns an error listing the valid availabilities", func() {
+				release := pivnet.Release{
+					ID:           42,
+					Availability: "Everyone",
+				}
+
+				_, err := client.Releases.Update("banana-slug", release)
+				Expect(err).To(HaveOccurred())
+				Expect(err.Error()).To(ContainSubstring("Everyone"))
+				Expect(err.Error()).To(ContainSubstring(pivnet.AvailabilitySelectedUserGroups))
+
+				Expect(server.ReceivedRequests()).To(BeEmpty())
+			})
+		})
+
+		Context("when the release is controlled without an ECCN", func() {
+			It("returns an error without making a request", func() {
+				release := pivnet.Release{
+					ID:               42,
+					Controlled:       true,
+					LicenseException: "ENC Unrestricted",
+				}
+
+				_, err := client.Releases.Update("banana-slug", release)
+				Expect(err).To(MatchError(ContainSubstring("ECCN and license exception must be provided")))
+
+				Expect(server.ReceivedRequests()).To(BeEmpty())
 			})
 		})
 
-		Context("when the server responds with a non-201 status code", func() {
+		Context("when the server responds with a non-200 status code", func() {
 			var (
 				body []byte
 			)
@@ -359,29 +1243,39 @@ var _ = Describe("PivnetClient - product files", func() {
 				body = []byte(`{"message":"foo message"}`)
 			})
 
-			It("returns an error", func() {
+			It("returns the error", func() {
+				release := pivnet.Release{ID: 111}
+				patchURL := fmt.Sprintf("%s/products/%s/releases/%d", apiPrefix, "banana-slug", release.ID)
+
 				server.AppendHandlers(
 					ghttp.CombineHandlers(
-						ghttp.VerifyRequest("POST", apiPrefix+"/products/"+productSlug+"/releases"),
+						ghttp.VerifyRequest("PATCH", patchURL),
 						ghttp.RespondWith(http.StatusTeapot, body),
 					),
 				)
 
-				_, err := client.Releases.Create(createReleaseConfig)
+				_, err := client.Releases.Update("banana-slug", release)
 				Expect(err.Error()).To(ContainSubstring("foo message"))
 			})
 		})
 
 		Context("when the json unmarshalling fails with error", func() {
 			It("forwards the error", func() {
+				release := pivnet.Release{ID: 111}
+
 				server.AppendHandlers(
 					ghttp.CombineHandlers(
-						ghttp.VerifyRequest("POST", apiPrefix+"/products/"+productSlug+"/releases"),
+						ghttp.VerifyRequest("PATCH", fmt.Sprintf(
+							"%s/products/%s/releases/%d",
+							apiPrefix,
+							"banana-slug",
+							release.ID,
+						)),
 						ghttp.RespondWith(http.StatusTeapot, "%%%"),
 					),
 				)
 
-				_, err := client.Releases.Create(createReleaseConfig)
+				_, err := client.Releases.Update("banana-slug", release)
 				Expect(err).To(HaveOccurred())
 
 				Expect(err.Error()).To(ContainSubstring("invalid character"))
@@ -389,78 +1283,339 @@ var _ = Describe("PivnetClient - product files", func() {
 		})
 	})
 
-	Describe("Update", func() {
-		It("submits the updated values for a release with OSS compliance", func() {
-			release := pivnet.Release{
-				ID:      42,
-				Version: "1.2.3.4",
-				EULA: &pivnet.EULA{
-					Slug: "some-eula",
-					ID:   15,
-				},
+	Describe("UpdateFields", func() {
+		var (
+			release  pivnet.Release
+			patchURL string
+		)
+
+		BeforeEach(func() {
+			release = pivnet.Release{
+				ID:               42,
+				Version:          "1.2.3.4",
+				Description:      "new description",
+				ReleaseNotesURL:  "",
+				EndOfSupportDate: "2020-01-01",
+				EULA:             &pivnet.EULA{Slug: "some-eula"},
 			}
 
-			patchURL := fmt.Sprintf("%s/products/%s/releases/%d", apiPrefix, "banana-slug", release.ID)
+			patchURL = fmt.Sprintf("%s/products/%s/releases/%d", apiPrefix, "banana-slug", release.ID)
+		})
 
-			response := `{"release": {"id": 42, "version": "1.2.3.4"}}`
+		It("sends only the fields in the mask, even if they are empty", func() {
 			server.AppendHandlers(
 				ghttp.CombineHandlers(
 					ghttp.VerifyRequest("PATCH", patchURL),
-					ghttp.VerifyJSON(`{"release":{"id": 42, "version": "1.2.3.4", "eula":{"slug":"some-eula","id":15}, "oss_compliant":"confirm"}}`),
-					ghttp.RespondWith(http.StatusOK, response),
+					ghttp.VerifyJSON(`{"release":{"description": "new description", "release_notes_url": ""}}`),
+					ghttp.RespondWith(http.StatusOK, `{"release": {"id": 42, "description": "new description"}}`),
 				),
 			)
 
-			release, err := client.Releases.Update("banana-slug", release)
+			updated, err := client.Releases.UpdateFields("banana-slug", release, []string{"description", "release_notes_url"})
 			Expect(err).NotTo(HaveOccurred())
-			Expect(release.Version).To(Equal("1.2.3.4"))
+			Expect(updated.Description).To(Equal("new description"))
 		})
 
-		Context("when the server responds with a non-200 status code", func() {
-			var (
-				body []byte
-			)
+		Context("when the mask names an unknown field", func() {
+			It("returns an error without making a request", func() {
+				_, err := client.Releases.UpdateFields("banana-slug", release, []string{"description", "colour"})
+				Expect(err).To(MatchError("Unknown or read-only release field 'colour' in field mask"))
 
-			BeforeEach(func() {
-				body = []byte(`{"message":"foo message"}`)
+				Expect(server.ReceivedRequests()).To(BeEmpty())
 			})
+		})
 
-			It("returns the error", func() {
-				release := pivnet.Release{ID: 111}
-				patchURL := fmt.Sprintf("%s/products/%s/releases/%d", apiPrefix, "banana-slug", release.ID)
+		Context("when the mask names a field that is not sent to Pivnet", func() {
+			It("returns an error without making a request", func() {
+				_, err := client.Releases.UpdateFields("banana-slug", release, []string{""})
+				Expect(err).To(MatchError("Unknown or read-only release field '' in field mask"))
+
+				_, err = client.Releases.UpdateFields("banana-slug", release, []string{"-"})
+				Expect(err).To(MatchError("Unknown or read-only release field '-' in field mask"))
+
+				Expect(server.ReceivedRequests()).To(BeEmpty())
+			})
+		})
+
+		Context("when the mask names a read-only field", func() {
+			It("returns an error without making a request", func() {
+				_, err := client.Releases.UpdateFields("banana-slug", release, []string{"id"})
+				Expect(err).To(MatchError(ContainSubstring("'id'")))
+
+				Expect(server.ReceivedRequests()).To(BeEmpty())
+			})
+		})
+
+		Context("when the mask is empty", func() {
+			It("returns an error without making a request", func() {
+				_, err := client.Releases.UpdateFields("banana-slug", release, nil)
+				Expect(err).To(HaveOccurred())
+
+				Expect(server.ReceivedRequests()).To(BeEmpty())
+			})
+		})
+
+		Context("when the masked availability is invalid", func() {
+			It("returns an error without making a request", func() {
+				release.Availability = "Everyone"
+
+				_, err := client.Releases.UpdateFields("banana-slug", release, []string{"availability"})
+				Expect(err).To(HaveOccurred())
+
+				Expect(server.ReceivedRequests()).To(BeEmpty())
+			})
+		})
+
+		Context("when the mask sets controlled without the ECCN and license exception", func() {
+			It("returns an error without making a request", func() {
+				release.Controlled = true
+				release.ECCN = "5D002"
+				release.LicenseException = "ENC Unrestricted"
+
+				_, err := client.Releases.UpdateFields("banana-slug", release, []string{"controlled", "eccn"})
+				Expect(err).To(MatchError(ContainSubstring("ECCN and license exception must be provided")))
+
+				Expect(server.ReceivedRequests()).To(BeEmpty())
+			})
+		})
+
+		Context("when the mask sets controlled with the ECCN and license exception", func() {
+			It("sends them", func() {
+				release.Controlled = true
+				release.ECCN = "5D002"
+				release.LicenseException = "ENC Unrestricted"
 
 				server.AppendHandlers(
 					ghttp.CombineHandlers(
 						ghttp.VerifyRequest("PATCH", patchURL),
-						ghttp.RespondWith(http.StatusTeapot, body),
+						ghttp.VerifyJSON(`{"release":{"controlled":true,"eccn":"5D002","license_exception":"ENC Unrestricted"}}`),
+						ghttp.RespondWith(http.StatusOK, `{"release":{"id":42}}`),
+					),
+				)
+
+				_, err := client.Releases.UpdateFields("banana-slug", release, []string{"controlled", "eccn", "license_exception"})
+				Expect(err).NotTo(HaveOccurred())
+			})
+		})
+	})
+
+	Describe("release description lint", func() {
+		var (
+			release pivnet.Release
+		)
+
+		BeforeEach(func() {
+			release = pivnet.Release{
+				ID:          42,
+				Description: "See [the docs](https://example.com for **details",
+			}
+		})
+
+		Context("when the lint mode is strict", func() {
+			BeforeEach(func() {
+				newClientConfig.ReleaseDescriptionLint = pivnet.MarkdownLintStrict
+				client = pivnet.NewClient(newClientConfig, fakeLogger)
+			})
+
+			It("returns the problems without updating the release", func() {
+				_, err := client.Releases.Update("banana-slug", release)
+
+				var lintErr pivnet.MarkdownLintError
+				Expect(errors.As(err, &lintErr)).To(BeTrue())
+				Expect(lintErr.Warnings).To(HaveLen(2))
+				Expect(server.ReceivedRequests()).To(BeEmpty())
+			})
+
+			It("returns the problems without creating the release", func() {
+				_, err := client.Releases.Create(pivnet.CreateReleaseConfig{
+					ProductSlug: "banana-slug",
+					Version:     "1.2.3",
+					EULASlug:    "some-eula",
+					Description: release.Description,
+				})
+
+				Expect(err).To(BeAssignableToTypeOf(pivnet.MarkdownLintError{}))
+				Expect(server.ReceivedRequests()).To(BeEmpty())
+			})
+
+			It("sends a well-formed description", func() {
+				release.Description = "See [the docs](https://example.com) for **details**"
+
+				server.AppendHandlers(
+					ghttp.CombineHandlers(
+						ghttp.VerifyRequest("PATCH", fmt.Sprintf("%s/products/banana-slug/releases/42", apiPrefix)),
+						ghttp.RespondWith(http.StatusOK, `{"release": {"id": 42}}`),
 					),
 				)
 
 				_, err := client.Releases.Update("banana-slug", release)
-				Expect(err.Error()).To(ContainSubstring("foo message"))
+				Expect(err).NotTo(HaveOccurred())
 			})
 		})
 
-		Context("when the json unmarshalling fails with error", func() {
-			It("forwards the error", func() {
-				release := pivnet.Release{ID: 111}
+		Context("when the lint mode is warn", func() {
+			BeforeEach(func() {
+				newClientConfig.ReleaseDescriptionLint = pivnet.MarkdownLintWarn
+				client = pivnet.NewClient(newClientConfig, fakeLogger)
+			})
 
+			It("logs the problems and updates the release", func() {
 				server.AppendHandlers(
 					ghttp.CombineHandlers(
-						ghttp.VerifyRequest("PATCH", fmt.Sprintf(
-							"%s/products/%s/releases/%d",
-							apiPrefix,
-							"banana-slug",
-							release.ID,
-						)),
-						ghttp.RespondWith(http.StatusTeapot, "%%%"),
+						ghttp.VerifyRequest("PATCH", fmt.Sprintf("%s/products/banana-slug/releases/42", apiPrefix)),
+						ghttp.RespondWith(http.StatusOK, `{"release": {"id": 42}}`),
+					),
+				)
+
+				_, err := client.Releases.Update("banana-slug", release)
+				Expect(err).NotTo(HaveOccurred())
+
+				fake := fakeLogger.(*loggerfakes.FakeLogger)
+				Expect(fake.InfoCallCount()).To(Equal(2))
+				action, data := fake.InfoArgsForCall(0)
+				Expect(action).To(ContainSubstring("release description"))
+				Expect(data[0]["problem"]).To(Equal("link URL is missing ')'"))
+			})
+		})
+
+		Context("when the lint mode is off", func() {
+			It("updates the release without checking the description", func() {
+				server.AppendHandlers(
+					ghttp.CombineHandlers(
+						ghttp.VerifyRequest("PATCH", fmt.Sprintf("%s/products/banana-slug/releases/42", apiPrefix)),
+						ghttp.RespondWith(http.StatusOK, `{"release": {"id": 42}}`),
 					),
 				)
 
 				_, err := client.Releases.Update("banana-slug", release)
+				Expect(err).NotTo(HaveOccurred())
+				Expect(fakeLogger.(*loggerfakes.FakeLogger).InfoCallCount()).To(BeZero())
+			})
+		})
+	})
+
+	Describe("SetEULA", func() {
+		var (
+			eulaURL  string
+			patchURL string
+		)
+
+		BeforeEach(func() {
+			eulaURL = fmt.Sprintf("%s/eulas/%s", apiPrefix, "some-eula")
+			patchURL = fmt.Sprintf("%s/products/%s/releases/%d", apiPrefix, productSlug, 42)
+		})
+
+		It("patches only the EULA of the release", func() {
+			server.AppendHandlers(
+				ghttp.CombineHandlers(
+					ghttp.VerifyRequest("GET", eulaURL),
+					ghttp.RespondWith(http.StatusOK, `{"id":7,"slug":"some-eula","name":"Some EULA"}`),
+				),
+				ghttp.CombineHandlers(
+					ghttp.VerifyRequest("PATCH", patchURL),
+					ghttp.VerifyJSON(`{"release":{"eula":{"slug":"some-eula"}}}`),
+					ghttp.RespondWith(http.StatusOK, `{"release":{"id":42,"eula":{"id":7,"slug":"some-eula"}}}`),
+				),
+			)
+
+			release, err := client.Releases.SetEULA(productSlug, 42, "some-eula")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(release.EULA.Slug).To(Equal("some-eula"))
+		})
+
+		Context("when the EULA does not exist", func() {
+			It("returns an ErrNotFound naming the EULA without updating the release", func() {
+				server.AppendHandlers(
+					ghttp.CombineHandlers(
+						ghttp.VerifyRequest("GET", eulaURL),
+						ghttp.RespondWith(http.StatusNotFound, `{"message":"not found"}`),
+					),
+				)
+
+				_, err := client.Releases.SetEULA(productSlug, 42, "some-eula")
+				Expect(err).To(MatchError("EULA 'some-eula' not found"))
+				Expect(err).To(BeAssignableToTypeOf(pivnet.ErrNotFound{}))
+				Expect(server.ReceivedRequests()).To(HaveLen(1))
+			})
+		})
+	})
+
+	Describe("SetExportControl", func() {
+		var (
+			patchURL string
+		)
+
+		BeforeEach(func() {
+			patchURL = fmt.Sprintf("%s/products/%s/releases/%d", apiPrefix, productSlug, 42)
+		})
+
+		It("submits the export control fields", func() {
+			server.AppendHandlers(
+				ghttp.CombineHandlers(
+					ghttp.VerifyRequest("PATCH", patchURL),
+					ghttp.VerifyJSON(`{"release":{"controlled":true,"eccn":"5D002","license_exception":"ENC Unrestricted","oss_compliant":"confirm"}}`),
+					ghttp.RespondWith(http.StatusOK, `{"release":{"id":42,"controlled":true,"eccn":"5D002","license_exception":"ENC Unrestricted"}}`),
+				),
+			)
+
+			release, err := client.Releases.SetExportControl(productSlug, 42, true, "5D002", "ENC Unrestricted")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(release.Controlled).To(BeTrue())
+			Expect(release.ECCN).To(Equal("5D002"))
+			Expect(release.LicenseException).To(Equal("ENC Unrestricted"))
+		})
+
+		Context("when clearing the controlled flag", func() {
+			It("submits controlled as false", func() {
+				server.AppendHandlers(
+					ghttp.CombineHandlers(
+						ghttp.VerifyRequest("PATCH", patchURL),
+						ghttp.VerifyJSON(`{"release":{"controlled":false,"oss_compliant":"confirm"}}`),
+						ghttp.RespondWith(http.StatusOK, `{"release":{"id":42}}`),
+					),
+				)
+
+				_, err := client.Releases.SetExportControl(productSlug, 42, false, "", "")
+				Expect(err).NotTo(HaveOccurred())
+			})
+		})
+
+		Context("when controlled without a license exception", func() {
+			It("returns an error without making a request", func() {
+				_, err := client.Releases.SetExportControl(productSlug, 42, true, "5D002", "")
 				Expect(err).To(HaveOccurred())
+				Expect(server.ReceivedRequests()).To(BeEmpty())
+			})
+		})
 
-				Expect(err.Error()).To(ContainSubstring("invalid character"))
+		Context("when SkipOSSCompliance is set", func() {
+			It("omits the OSS compliance confirmation", func() {
+				server.AppendHandlers(
+					ghttp.CombineHandlers(
+						ghttp.VerifyRequest("PATCH", patchURL),
+						ghttp.VerifyJSON(`{"release":{"controlled":false}}`),
+						ghttp.RespondWith(http.StatusOK, `{"release":{"id":42}}`),
+					),
+				)
+
+				_, err := client.Releases.SetExportControlWithOptions(productSlug, 42, pivnet.ExportControlOptions{
+					SkipOSSCompliance: true,
+				})
+				Expect(err).NotTo(HaveOccurred())
+			})
+		})
+
+		Context("when the server responds with a non-200 status code", func() {
+			It("returns the error", func() {
+				server.AppendHandlers(
+					ghttp.CombineHandlers(
+						ghttp.VerifyRequest("PATCH", patchURL),
+						ghttp.RespondWith(http.StatusTeapot, `{"message":"foo message"}`),
+					),
+				)
+
+				_, err := client.Releases.SetExportControl(productSlug, 42, false, "", "")
+				Expect(err.Error()).To(ContainSubstring("foo message"))
 			})
 		})
 	})
@@ -528,4 +1683,51 @@ var _ = Describe("PivnetClient - product files", func() {
 			})
 		})
 	})
+
+	Describe("DeleteIfExists", func() {
+		var (
+			release = pivnet.Release{ID: 1234}
+		)
+
+		It("deletes the release", func() {
+			server.AppendHandlers(
+				ghttp.CombineHandlers(
+					ghttp.VerifyRequest("DELETE", fmt.Sprintf("%s/products/banana/releases/%d", apiPrefix, release.ID)),
+					ghttp.RespondWith(http.StatusNoContent, nil),
+				),
+			)
+
+			err := client.Releases.DeleteIfExists("banana", release)
+			Expect(err).NotTo(HaveOccurred())
+		})
+
+		Context("when the release does not exist", func() {
+			It("does not return an error", func() {
+				server.AppendHandlers(
+					ghttp.CombineHandlers(
+						ghttp.VerifyRequest("DELETE", fmt.Sprintf("%s/products/banana/releases/%d", apiPrefix, release.ID)),
+						ghttp.RespondWithJSONEncoded(http.StatusNotFound, pivnetErr{Message: "not found"}),
+					),
+				)
+
+				err := client.Releases.DeleteIfExists("banana", release)
+				Expect(err).NotTo(HaveOccurred())
+			})
+		})
+
+		Context("when the server responds with another non-204 status code", func() {
+			It("returns an error", func() {
+				server.AppendHandlers(
+					ghttp.CombineHandlers(
+						ghttp.VerifyRequest("DELETE", fmt.Sprintf("%s/products/banana/releases/%d", apiPrefix, release.ID)),
+						ghttp.RespondWithJSONEncoded(http.StatusTeapot, pivnetErr{Message: "foo message"}),
+					),
+				)
+
+				err := client.Releases.DeleteIfExists("banana", release)
+				Expect(err).To(HaveOccurred())
+				Expect(err.Error()).To(ContainSubstring("foo message"))
+			})
+		})
+	})
 })
@@ -0,0 +1,153 @@
+package pivnet
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+const (
+	DefaultRetryDelay    = time.Second
+	DefaultMaxRetryAfter = 5 * time.Minute
+)
+
+// RetryPolicy controls how MakeRequest retries idempotent (GET and HEAD)
+// requests that fail with a network error or a transient status code
+// (429, 500, 502, 503 or 504). The zero value disables retries.
+type RetryPolicy struct {
+	// MaxAttempts is the total number of attempts, including the first.
+	// Values below 2 disable retries.
+	MaxAttempts int
+
+	// Delay is the wait before the first retry, doubled for every further
+	// retry. Zero uses DefaultRetryDelay. A Retry-After header on the
+	// response takes precedence.
+	Delay time.Duration
+
+	// MaxDelay caps the backoff delay. Zero means no cap.
+	MaxDelay time.Duration
+
+	// MaxRetryAfter is the longest Retry-After the client will wait for. A
+	// response asking for a longer wait is not retried and its error, such
+	// as ErrTooManyRequests, is returned immediately. Zero uses
+	// DefaultMaxRetryAfter and a negative value means no cap.
+	MaxRetryAfter time.Duration
+}
+
+var retryableStatusCodes = map[int]bool{
+	http.StatusTooManyRequests:     true,
+	http.StatusInternalServerError: true,
+	http.StatusBadGateway:          true,
+	http.StatusServiceUnavailable:  true,
+	http.StatusGatewayTimeout:      true,
+}
+
+// retryReason returns why the attempt should be retried, or false if it
+// should not be.
+func (p RetryPolicy) retryReason(
+	req *http.Request,
+	expected func(statusCode int) bool,
+	resp *http.Response,
+	err error,
+) (string, bool) {
+	if p.MaxAttempts < 2 {
+		return "", false
+	}
+
+	if req.Method != "GET" && req.Method != "HEAD" {
+		return "", false
+	}
+
+	if err != nil {
+		if req.Context().Err() != nil {
+			return "", false
+		}
+		return err.Error(), true
+	}
+
+	if (expected != nil && expected(resp.StatusCode)) || !retryableStatusCodes[resp.StatusCode] {
+		return "", false
+	}
+
+	return fmt.Sprintf("status code %d", resp.StatusCode), true
+}
+
+// retryAfterTooLong reports whether the response asks for a longer wait than
+// MaxRetryAfter allows, returning the requested wait.
+func (p RetryPolicy) retryAfterTooLong(resp *http.Response) (time.Duration, bool) {
+	retryAfter, ok := parseRetryAfter(resp)
+	if !ok || p.MaxRetryAfter < 0 {
+		return 0, false
+	}
+
+	max := p.MaxRetryAfter
+	if max == 0 {
+		max = DefaultMaxRetryAfter
+	}
+
+	return retryAfter, retryAfter > max
+}
+
+// delay returns how long to wait after the given (1-based) failed attempt.
+func (p RetryPolicy) delay(attempt int, resp *http.Response) time.Duration {
+	if retryAfter, ok := parseRetryAfter(resp); ok {
+		return retryAfter
+	}
+
+	delay := p.Delay
+	if delay <= 0 {
+		delay = DefaultRetryDelay
+	}
+
+	for i := 1; i < attempt; i++ {
+		delay *= 2
+		if p.MaxDelay > 0 && delay >= p.MaxDelay {
+			break
+		}
+	}
+
+	if p.MaxDelay > 0 && delay > p.MaxDelay {
+		delay = p.MaxDelay
+	}
+
+	return delay
+}
+
+func parseRetryAfter(resp *http.Response) (time.Duration, bool) {
+	if resp == nil {
+		return 0, false
+	}
+
+	value := resp.Header.Get("Retry-After")
+	if value == "" {
+		return 0, false
+	}
+
+	if seconds, err := strconv.Atoi(value); err == nil && seconds >= 0 {
+		return time.Duration(seconds) * time.Second, true
+	}
+
+	if t, err := http.ParseTime(value); err == nil {
+		delay := t.Sub(time.Now())
+		if delay < 0 {
+			delay = 0
+		}
+		return delay, true
+	}
+
+	return 0, false
+}
+
+func sleepContext(ctx context.Context, d time.Duration) error {
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+
+	select {
+	case <-timer.C:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
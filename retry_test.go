@@ -0,0 +1,213 @@
+package pivnet_test
+
+import (
+	"fmt"
+	"net/http"
+	"time"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"github.com/onsi/gomega/ghttp"
+	"github.com/pivotal-cf/go-pivnet"
+	"github.com/pivotal-cf/go-pivnet/logger"
+	"github.com/pivotal-cf/go-pivnet/logger/loggerfakes"
+)
+
+var _ = Describe("PivnetClient - retries", func() {
+	var (
+		server     *ghttp.Server
+		client     pivnet.Client
+		fakeLogger *loggerfakes.FakeLogger
+
+		newClientConfig pivnet.ClientConfig
+	)
+
+	BeforeEach(func() {
+		server = ghttp.NewServer()
+
+		fakeLogger = &loggerfakes.FakeLogger{}
+		newClientConfig = pivnet.ClientConfig{
+			Host:      server.URL(),
+			Token:     "my-auth-token",
+			UserAgent: "pivnet-resource/0.1.0 (some-url)",
+			RetryPolicy: pivnet.RetryPolicy{
+				MaxAttempts: 3,
+				Delay:       time.Millisecond,
+			},
+		}
+	})
+
+	JustBeforeEach(func() {
+		client = pivnet.NewClient(newClientConfig, fakeLogger)
+	})
+
+	AfterEach(func() {
+		server.Close()
+	})
+
+	retryLogs := func() []logger.Data {
+		var data []logger.Data
+		for i := 0; i < fakeLogger.DebugCallCount(); i++ {
+			action, d := fakeLogger.DebugArgsForCall(i)
+			if action == "Retrying request" {
+				data = append(data, d[0])
+			}
+		}
+		return data
+	}
+
+	It("retries transient failures of GET requests and logs each retry", func() {
+		server.AppendHandlers(
+			ghttp.RespondWith(http.StatusServiceUnavailable, `{"message":"unavailable"}`),
+			ghttp.RespondWith(http.StatusBadGateway, `{"message":"bad gateway"}`),
+			ghttp.CombineHandlers(
+				ghttp.VerifyRequest("GET", fmt.Sprintf("%s/foo", apiPrefix)),
+				ghttp.RespondWith(http.StatusOK, `{}`),
+			),
+		)
+
+		resp, err := client.MakeRequest("GET", "/foo", http.StatusOK, nil)
+		Expect(err).NotTo(HaveOccurred())
+		resp.Body.Close()
+
+		logs := retryLogs()
+		Expect(logs).To(HaveLen(2))
+
+		Expect(logs[0]["attempt"]).To(Equal(1))
+		Expect(logs[0]["reason"]).To(Equal("status code 503"))
+		Expect(logs[0]["delay"]).To(Equal("1ms"))
+
+		Expect(logs[1]["attempt"]).To(Equal(2))
+		Expect(logs[1]["reason"]).To(Equal("status code 502"))
+		Expect(logs[1]["delay"]).To(Equal("2ms"))
+	})
+
+	It("uses the Retry-After header as the delay", func() {
+		server.AppendHandlers(
+			ghttp.RespondWith(http.StatusTooManyRequests, `{"message":"slow down"}`, http.Header{"Retry-After": []string{"0"}}),
+			ghttp.RespondWith(http.StatusOK, `{}`),
+		)
+
+		_, err := client.MakeRequest("GET", "/foo", http.StatusOK, nil)
+		Expect(err).NotTo(HaveOccurred())
+
+		logs := retryLogs()
+		Expect(logs).To(HaveLen(1))
+		Expect(logs[0]["delay"]).To(Equal("0s"))
+	})
+
+	Context("when the Retry-After header exceeds the default MaxRetryAfter", func() {
+		It("returns the rate limit error without waiting", func() {
+			server.AppendHandlers(
+				ghttp.RespondWith(http.StatusTooManyRequests, `{"message":"slow down"}`, http.Header{"Retry-After": []string{"3600"}}),
+			)
+
+			_, err := client.MakeRequest("GET", "/foo", http.StatusOK, nil)
+			Expect(err).To(Equal(pivnet.ErrTooManyRequests{
+				ResponseCode: http.StatusTooManyRequests,
+				Message:      "slow down",
+				RetryAfter:   time.Hour,
+			}))
+			Expect(err.Error()).To(Equal("slow down - retry after 1h0m0s"))
+			Expect(server.ReceivedRequests()).To(HaveLen(1))
+			Expect(retryLogs()).To(BeEmpty())
+		})
+	})
+
+	Context("when MaxRetryAfter is set", func() {
+		BeforeEach(func() {
+			newClientConfig.RetryPolicy.MaxRetryAfter = time.Second
+		})
+
+		It("does not retry a response asking for a longer wait", func() {
+			server.AppendHandlers(
+				ghttp.RespondWith(http.StatusServiceUnavailable, `{"message":"unavailable"}`, http.Header{"Retry-After": []string{"2"}}),
+			)
+
+			_, err := client.MakeRequest("GET", "/foo", http.StatusOK, nil)
+			Expect(err).To(MatchError(ContainSubstring("unavailable")))
+			Expect(server.ReceivedRequests()).To(HaveLen(1))
+		})
+
+		It("retries a response asking for a shorter wait", func() {
+			server.AppendHandlers(
+				ghttp.RespondWith(http.StatusTooManyRequests, `{"message":"slow down"}`, http.Header{"Retry-After": []string{"0"}}),
+				ghttp.RespondWith(http.StatusOK, `{}`),
+			)
+
+			_, err := client.MakeRequest("GET", "/foo", http.StatusOK, nil)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(server.ReceivedRequests()).To(HaveLen(2))
+		})
+	})
+
+	Context("when all attempts are rate limited", func() {
+		It("returns the rate limit error", func() {
+			for i := 0; i < 3; i++ {
+				server.AppendHandlers(
+					ghttp.RespondWith(http.StatusTooManyRequests, `{"message":"slow down"}`),
+				)
+			}
+
+			_, err := client.MakeRequest("GET", "/foo", http.StatusOK, nil)
+			Expect(err).To(BeAssignableToTypeOf(pivnet.ErrTooManyRequests{}))
+			Expect(err.(pivnet.ErrTooManyRequests).RetryAfter).To(BeZero())
+			Expect(server.ReceivedRequests()).To(HaveLen(3))
+		})
+	})
+
+	Context("when all attempts fail", func() {
+		It("returns the last error", func() {
+			server.AppendHandlers(
+				ghttp.RespondWith(http.StatusServiceUnavailable, `{"message":"first"}`),
+				ghttp.RespondWith(http.StatusServiceUnavailable, `{"message":"second"}`),
+				ghttp.RespondWith(http.StatusServiceUnavailable, `{"message":"third"}`),
+			)
+
+			_, err := client.MakeRequest("GET", "/foo", http.StatusOK, nil)
+			Expect(err).To(MatchError(ContainSubstring("third")))
+			Expect(server.ReceivedRequests()).To(HaveLen(3))
+		})
+	})
+
+	Context("when the request is not idempotent", func() {
+		It("does not retry", func() {
+			server.AppendHandlers(
+				ghttp.RespondWith(http.StatusServiceUnavailable, `{"message":"unavailable"}`),
+			)
+
+			_, err := client.MakeRequest("POST", "/foo", http.StatusOK, nil)
+			Expect(err).To(HaveOccurred())
+			Expect(server.ReceivedRequests()).To(HaveLen(1))
+			Expect(retryLogs()).To(BeEmpty())
+		})
+	})
+
+	Context("when the status code is not transient", func() {
+		It("does not retry", func() {
+			server.AppendHandlers(
+				ghttp.RespondWith(http.StatusTeapot, `{"message":"teapot"}`),
+			)
+
+			_, err := client.MakeRequest("GET", "/foo", http.StatusOK, nil)
+			Expect(err).To(HaveOccurred())
+			Expect(server.ReceivedRequests()).To(HaveLen(1))
+		})
+	})
+
+	Context("when no retry policy is configured", func() {
+		BeforeEach(func() {
+			newClientConfig.RetryPolicy = pivnet.RetryPolicy{}
+		})
+
+		It("does not retry", func() {
+			server.AppendHandlers(
+				ghttp.RespondWith(http.StatusServiceUnavailable, `{"message":"unavailable"}`),
+			)
+
+			_, err := client.MakeRequest("GET", "/foo", http.StatusOK, nil)
+			Expect(err).To(HaveOccurred())
+			Expect(server.ReceivedRequests()).To(HaveLen(1))
+		})
+	})
+})
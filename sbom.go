@@ -0,0 +1,188 @@
+package pivnet
+
+import (
+	"fmt"
+	"strconv"
+	"sync"
+	"time"
+)
+
+const (
+	SBOMFormat      = "CycloneDX"
+	SBOMSpecVersion = "1.4"
+
+	// SBOMSourceProperty is the metadata property recording that an SBOM
+	// was derived from Pivnet metadata.
+	SBOMSourceProperty = "pivnet:source"
+)
+
+// SBOM is a bill of materials for a release in a subset of the CycloneDX
+// JSON format. It is derived from the metadata Pivnet holds for the release
+// and its product files, not from scanning their content, so it only
+// describes the files themselves and not the software inside them.
+type SBOM struct {
+	BOMFormat    string           `json:"bomFormat"`
+	SpecVersion  string           `json:"specVersion"`
+	Version      int              `json:"version"`
+	Metadata     SBOMMetadata     `json:"metadata"`
+	Components   []SBOMComponent  `json:"components"`
+	Dependencies []SBOMDependency `json:"dependencies,omitempty"`
+}
+
+type SBOMMetadata struct {
+	Timestamp string `json:"timestamp"`
+
+	// Component is the release.
+	Component  SBOMComponent  `json:"component"`
+	Properties []SBOMProperty `json:"properties,omitempty"`
+}
+
+// SBOMComponent is the release, one of its product files or a release it
+// depends on.
+type SBOMComponent struct {
+	// Type is "application" for releases and "file" for product files.
+	Type       string         `json:"type"`
+	BOMRef     string         `json:"bom-ref"`
+	Name       string         `json:"name"`
+	Version    string         `json:"version,omitempty"`
+	Hashes     []SBOMHash     `json:"hashes,omitempty"`
+	Properties []SBOMProperty `json:"properties,omitempty"`
+}
+
+type SBOMHash struct {
+	// Alg is "SHA-256", "SHA-1" or "MD5".
+	Alg     string `json:"alg"`
+	Content string `json:"content"`
+}
+
+type SBOMProperty struct {
+	Name  string `json:"name"`
+	Value string `json:"value"`
+}
+
+// SBOMDependency lists the components a component depends on by BOMRef.
+type SBOMDependency struct {
+	Ref       string   `json:"ref"`
+	DependsOn []string `json:"dependsOn,omitempty"`
+}
+
+// SBOM returns a bill of materials for the release listing each of its
+// product files, with its name, version, checksums, size and file type, and
+// the releases it depends on. The release, its product files and its
+// dependencies are fetched concurrently. See SBOM for its limitations.
+func (r ReleasesService) SBOM(productSlug string, releaseID int) (SBOM, error) {
+	var (
+		release      Release
+		productFiles []ProductFile
+		dependencies []ReleaseDependency
+		errs         [3]error
+	)
+
+	var wg sync.WaitGroup
+	wg.Add(3)
+
+	go func() {
+		defer wg.Done()
+		release, errs[0] = r.Get(productSlug, releaseID)
+	}()
+
+	go func() {
+		defer wg.Done()
+		productFiles, errs[1] = ProductFilesService{client: r.client}.ListForRelease(productSlug, releaseID)
+	}()
+
+	go func() {
+		defer wg.Done()
+		dependencies, errs[2] = ReleaseDependenciesService{client: r.client}.List(productSlug, releaseID)
+	}()
+
+	wg.Wait()
+
+	for _, err := range errs {
+		if err != nil {
+			return SBOM{}, err
+		}
+	}
+
+	releaseRef := sbomReleaseRef(release.ID)
+
+	sbom := SBOM{
+		BOMFormat:   SBOMFormat,
+		SpecVersion: SBOMSpecVersion,
+		Version:     1,
+		Metadata: SBOMMetadata{
+			Timestamp: r.client.clock().UTC().Format(time.RFC3339),
+			Component: SBOMComponent{
+				Type:    "application",
+				BOMRef:  releaseRef,
+				Name:    productSlug,
+				Version: release.Version,
+			},
+			Properties: []SBOMProperty{
+				{Name: SBOMSourceProperty, Value: "Derived from Pivnet metadata, not from scanning the product files"},
+			},
+		},
+		Components: []SBOMComponent{},
+	}
+
+	for _, pf := range productFiles {
+		sbom.Components = append(sbom.Components, sbomProductFile(pf))
+	}
+
+	var dependsOn []string
+	for _, dependency := range dependencies {
+		name := dependency.Release.Product.Slug
+		if name == "" {
+			name = dependency.Release.Product.Name
+		}
+
+		ref := sbomReleaseRef(dependency.Release.ID)
+		sbom.Components = append(sbom.Components, SBOMComponent{
+			Type:    "application",
+			BOMRef:  ref,
+			Name:    name,
+			Version: dependency.Release.Version,
+		})
+		dependsOn = append(dependsOn, ref)
+	}
+
+	sbom.Dependencies = []SBOMDependency{{Ref: releaseRef, DependsOn: dependsOn}}
+
+	return sbom, nil
+}
+
+func sbomReleaseRef(releaseID int) string {
+	return fmt.Sprintf("pivnet:release:%d", releaseID)
+}
+
+func sbomProductFile(pf ProductFile) SBOMComponent {
+	component := SBOMComponent{
+		Type:    "file",
+		BOMRef:  fmt.Sprintf("pivnet:product-file:%d", pf.ID),
+		Name:    pf.Name,
+		Version: pf.FileVersion,
+	}
+
+	for _, hash := range []SBOMHash{
+		{Alg: "SHA-256", Content: pf.SHA256},
+		{Alg: "SHA-1", Content: pf.SHA1},
+		{Alg: "MD5", Content: pf.MD5},
+	} {
+		if hash.Content != "" {
+			component.Hashes = append(component.Hashes, hash)
+		}
+	}
+
+	component.Properties = append(component.Properties,
+		SBOMProperty{Name: "pivnet:product_file_id", Value: strconv.Itoa(pf.ID)},
+		SBOMProperty{Name: "pivnet:file_name", Value: productFileName(pf)},
+	)
+	if pf.FileType != "" {
+		component.Properties = append(component.Properties, SBOMProperty{Name: "pivnet:file_type", Value: pf.FileType})
+	}
+	if pf.Size > 0 {
+		component.Properties = append(component.Properties, SBOMProperty{Name: "pivnet:size", Value: strconv.Itoa(pf.Size)})
+	}
+
+	return component
+}
@@ -0,0 +1,128 @@
+package pivnet_test
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"time"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"github.com/onsi/gomega/ghttp"
+	"github.com/pivotal-cf/go-pivnet"
+	"github.com/pivotal-cf/go-pivnet/logger/loggerfakes"
+)
+
+var _ = Describe("PivnetClient - SBOM", func() {
+	var (
+		server *ghttp.Server
+		client pivnet.Client
+
+		releaseURL string
+	)
+
+	BeforeEach(func() {
+		server = ghttp.NewServer()
+		client = pivnet.NewClient(pivnet.ClientConfig{
+			Host:      server.URL(),
+			Token:     "my-auth-token",
+			UserAgent: "pivnet-resource/0.1.0 (some-url)",
+			Clock: func() time.Time {
+				return time.Date(2016, 1, 2, 3, 4, 5, 0, time.UTC)
+			},
+		}, &loggerfakes.FakeLogger{})
+
+		releaseURL = fmt.Sprintf("%s/products/banana/releases/1234", apiPrefix)
+
+		server.RouteToHandler("GET", releaseURL,
+			ghttp.RespondWith(http.StatusOK, `{"id": 1234, "version": "1.2.3"}`),
+		)
+		server.RouteToHandler("GET", releaseURL+"/product_files",
+			ghttp.RespondWith(http.StatusOK, `{"product_files": [{
+				"id": 5,
+				"name": "Banana Tile",
+				"aws_object_key": "product-files/banana/banana-1.2.3.pivotal",
+				"file_version": "1.2.3",
+				"file_type": "Software",
+				"sha256": "some-sha256",
+				"md5": "some-md5",
+				"size": 1024
+			}]}`),
+		)
+		server.RouteToHandler("GET", releaseURL+"/dependencies",
+			ghttp.RespondWith(http.StatusOK, `{"dependencies": [{
+				"release": {"id": 99, "version": "4.5.6", "product": {"slug": "stemcells"}}
+			}]}`),
+		)
+	})
+
+	AfterEach(func() {
+		server.Close()
+	})
+
+	It("describes the release, its product files and its dependencies", func() {
+		sbom, err := client.Releases.SBOM("banana", 1234)
+		Expect(err).NotTo(HaveOccurred())
+
+		b, err := json.Marshal(sbom)
+		Expect(err).NotTo(HaveOccurred())
+
+		Expect(b).To(MatchJSON(`{
+			"bomFormat": "CycloneDX",
+			"specVersion": "1.4",
+			"version": 1,
+			"metadata": {
+				"timestamp": "2016-01-02T03:04:05Z",
+				"component": {
+					"type": "application",
+					"bom-ref": "pivnet:release:1234",
+					"name": "banana",
+					"version": "1.2.3"
+				},
+				"properties": [
+					{"name": "pivnet:source", "value": "Derived from Pivnet metadata, not from scanning the product files"}
+				]
+			},
+			"components": [
+				{
+					"type": "file",
+					"bom-ref": "pivnet:product-file:5",
+					"name": "Banana Tile",
+					"version": "1.2.3",
+					"hashes": [
+						{"alg": "SHA-256", "content": "some-sha256"},
+						{"alg": "MD5", "content": "some-md5"}
+					],
+					"properties": [
+						{"name": "pivnet:product_file_id", "value": "5"},
+						{"name": "pivnet:file_name", "value": "banana-1.2.3.pivotal"},
+						{"name": "pivnet:file_type", "value": "Software"},
+						{"name": "pivnet:size", "value": "1024"}
+					]
+				},
+				{
+					"type": "application",
+					"bom-ref": "pivnet:release:99",
+					"name": "stemcells",
+					"version": "4.5.6"
+				}
+			],
+			"dependencies": [
+				{"ref": "pivnet:release:1234", "dependsOn": ["pivnet:release:99"]}
+			]
+		}`))
+	})
+
+	Context("when a sub-resource cannot be fetched", func() {
+		BeforeEach(func() {
+			server.RouteToHandler("GET", releaseURL+"/dependencies",
+				ghttp.RespondWith(http.StatusTeapot, `{"message": "foo message"}`),
+			)
+		})
+
+		It("returns the error", func() {
+			_, err := client.Releases.SBOM("banana", 1234)
+			Expect(err).To(MatchError(ContainSubstring("foo message")))
+		})
+	})
+})
@@ -0,0 +1,148 @@
+package pivnet
+
+import (
+	"fmt"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/pivotal-cf/go-pivnet/logger"
+)
+
+// signedURLExpiryMargin is how long before it expires a cached signed URL
+// stops being used, so that a download does not start just before the URL
+// expires.
+const signedURLExpiryMargin = time.Minute
+
+// signedURLCache holds the signed download URL of each product file until
+// it expires. A nil cache holds nothing.
+type signedURLCache struct {
+	mutex sync.Mutex
+	urls  map[int]string
+}
+
+func newSignedURLCache() *signedURLCache {
+	return &signedURLCache{urls: map[int]string{}}
+}
+
+func (c *signedURLCache) get(productFileID int, now time.Time) (string, bool) {
+	if c == nil {
+		return "", false
+	}
+
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	location, ok := c.urls[productFileID]
+	if !ok {
+		return "", false
+	}
+
+	if !now.Add(signedURLExpiryMargin).Before(signedURLExpiry(location)) {
+		delete(c.urls, productFileID)
+		return "", false
+	}
+
+	return location, true
+}
+
+// set caches the URL if its expiry is known.
+func (c *signedURLCache) set(productFileID int, location string) {
+	if c == nil || signedURLExpiry(location).IsZero() {
+		return
+	}
+
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	c.urls[productFileID] = location
+}
+
+func (c *signedURLCache) remove(productFileID int) {
+	if c == nil {
+		return
+	}
+
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	delete(c.urls, productFileID)
+}
+
+// openDownload requests the content of the product file, returning a
+// response with status 200, or 304 if ifModifiedSince is set and the file
+// is unchanged.
+//
+// Without a signed URL cache it POSTs to the download link, which accepts
+// the EULA and redirects to a fresh signed URL. With one it requests the
+// cached signed URL directly, fetching a new one if it has expired or is
+// rejected with a 403.
+func (p ProductFilesService) openDownload(
+	pf ProductFile,
+	downloadLink string,
+	ifModifiedSince string,
+) (*http.Response, error) {
+	notModifiedAllowed := func(statusCode int) bool {
+		return ifModifiedSince != "" && statusCode == http.StatusNotModified
+	}
+
+	if p.client.signedURLs == nil {
+		client := p.client
+		client.auditLogger = nil
+		if ifModifiedSince != "" {
+			client.requestEditors = append(append([]RequestEditor{}, client.requestEditors...),
+				func(req *http.Request) error {
+					req.Header.Set("If-Modified-Since", ifModifiedSince)
+					return nil
+				},
+			)
+		}
+
+		return client.makeRequestExpecting(
+			"POST",
+			downloadLink,
+			func(statusCode int) bool {
+				return statusCode == http.StatusOK || notModifiedAllowed(statusCode)
+			},
+			nil,
+			0,
+		)
+	}
+
+	header := http.Header{}
+	if ifModifiedSince != "" {
+		header.Set("If-Modified-Since", ifModifiedSince)
+	}
+
+	for attempt := 1; ; attempt++ {
+		location, err := p.signedDownloadURL(pf)
+		if err != nil {
+			return nil, err
+		}
+
+		resp, err := p.signedURLRequest(location, header)
+		if err != nil {
+			return nil, err
+		}
+
+		if resp.StatusCode == http.StatusOK || notModifiedAllowed(resp.StatusCode) {
+			return resp, nil
+		}
+		resp.Body.Close()
+
+		if resp.StatusCode == http.StatusForbidden && attempt == 1 {
+			p.client.logger.Debug(
+				"Signed download URL rejected - fetching a new one",
+				logger.Data{"product_file_id": pf.ID},
+			)
+			p.client.signedURLs.remove(pf.ID)
+			continue
+		}
+
+		return nil, fmt.Errorf(
+			"Download of product file %d failed - status code %d",
+			pf.ID,
+			resp.StatusCode,
+		)
+	}
+}
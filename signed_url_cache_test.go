@@ -0,0 +1,134 @@
+package pivnet_test
+
+import (
+	"bytes"
+	"fmt"
+	"net/http"
+	"sync/atomic"
+	"time"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"github.com/onsi/gomega/ghttp"
+	"github.com/pivotal-cf/go-pivnet"
+	"github.com/pivotal-cf/go-pivnet/logger/loggerfakes"
+)
+
+var _ = Describe("PivnetClient - signed URL cache", func() {
+	var (
+		server *ghttp.Server
+		client pivnet.Client
+		now    time.Time
+
+		releaseID     = 1234
+		productFileID = 2345
+		fileContents  = []byte("some file contents")
+
+		signedURLRequests int32
+		storageStatuses   []int
+	)
+
+	BeforeEach(func() {
+		server = ghttp.NewServer()
+		now = time.Date(2016, 1, 2, 3, 4, 5, 0, time.UTC)
+
+		client = pivnet.NewClient(pivnet.ClientConfig{
+			Host:            server.URL(),
+			Token:           "my-auth-token",
+			UserAgent:       "pivnet-resource/0.1.0 (some-url)",
+			CacheSignedURLs: true,
+			Clock:           func() time.Time { return now },
+		}, &loggerfakes.FakeLogger{})
+
+		signedURLRequests = 0
+		storageStatuses = nil
+
+		server.RouteToHandler("GET",
+			fmt.Sprintf("%s/products/%s/releases/%d/product_files/%d", apiPrefix, productSlug, releaseID, productFileID),
+			ghttp.RespondWithJSONEncoded(http.StatusOK, pivnet.ProductFileResponse{pivnet.ProductFile{
+				ID: productFileID,
+				Links: &pivnet.Links{
+					Download: map[string]string{"href": "/some/download/link"},
+				},
+			}}),
+		)
+
+		server.RouteToHandler("POST", apiPrefix+"/some/download/link",
+			func(w http.ResponseWriter, req *http.Request) {
+				n := atomic.AddInt32(&signedURLRequests, 1)
+				location := fmt.Sprintf(
+					"%s/storage/file?n=%d&X-Amz-Date=%s&X-Amz-Expires=3600",
+					server.URL(),
+					n,
+					now.Format("20060102T150405Z"),
+				)
+				http.Redirect(w, req, location, http.StatusFound)
+			},
+		)
+
+		server.RouteToHandler("GET", "/storage/file",
+			func(w http.ResponseWriter, req *http.Request) {
+				Expect(req.Header.Get("Authorization")).To(BeEmpty())
+
+				status := http.StatusOK
+				if len(storageStatuses) > 0 {
+					status, storageStatuses = storageStatuses[0], storageStatuses[1:]
+				}
+
+				w.WriteHeader(status)
+				if status == http.StatusOK {
+					w.Write(fileContents)
+				}
+			},
+		)
+	})
+
+	AfterEach(func() {
+		server.Close()
+	})
+
+	download := func() {
+		var buffer bytes.Buffer
+		err := client.ProductFiles.DownloadTo(productSlug, releaseID, productFileID, &buffer)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(buffer.Bytes()).To(Equal(fileContents))
+	}
+
+	It("reuses the signed URL for repeated downloads", func() {
+		download()
+		download()
+
+		Expect(atomic.LoadInt32(&signedURLRequests)).To(BeEquivalentTo(1))
+	})
+
+	Context("when the signed URL is about to expire", func() {
+		It("fetches a new one", func() {
+			download()
+
+			now = now.Add(59*time.Minute + 30*time.Second)
+			download()
+
+			Expect(atomic.LoadInt32(&signedURLRequests)).To(BeEquivalentTo(2))
+		})
+	})
+
+	Context("when the storage provider rejects the cached URL", func() {
+		It("fetches a new one and retries the download", func() {
+			download()
+
+			storageStatuses = []int{http.StatusForbidden}
+			download()
+
+			Expect(atomic.LoadInt32(&signedURLRequests)).To(BeEquivalentTo(2))
+		})
+	})
+
+	Context("when the storage provider keeps rejecting the URL", func() {
+		It("returns an error", func() {
+			storageStatuses = []int{http.StatusForbidden, http.StatusForbidden}
+
+			err := client.ProductFiles.DownloadTo(productSlug, releaseID, productFileID, &bytes.Buffer{})
+			Expect(err).To(MatchError(fmt.Sprintf("Download of product file %d failed - status code 403", productFileID)))
+		})
+	})
+})
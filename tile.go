@@ -0,0 +1,93 @@
+package pivnet
+
+import (
+	"archive/zip"
+	"fmt"
+	"io/ioutil"
+	"path"
+	"sort"
+	"strings"
+
+	yaml "gopkg.in/yaml.v2"
+)
+
+// TileMetadata is the product identification read from the metadata of a
+// .pivotal tile.
+type TileMetadata struct {
+	Name           string `json:"name" yaml:"name"`
+	ProductVersion string `json:"product_version" yaml:"product_version"`
+}
+
+type ErrInvalidTile struct {
+	Path   string
+	Reason string
+}
+
+func (e ErrInvalidTile) Error() string {
+	return fmt.Sprintf("'%s' is not a valid tile - %s", e.Path, e.Reason)
+}
+
+// ValidateTile checks that the file at filepath is a .pivotal tile, i.e. a zip
+// archive with a product metadata YAML file in its metadata/ directory, and
+// returns the product name and version from that file. It catches
+// truncated downloads of files that Pivnet has no checksum for, and files
+// that are not tiles at all, which should not be passed to it. Problems with
+// the file are returned as ErrInvalidTile.
+func (p ProductFilesService) ValidateTile(filepath string) (TileMetadata, error) {
+	archive, err := zip.OpenReader(filepath)
+	if err != nil {
+		return TileMetadata{}, ErrInvalidTile{Path: filepath, Reason: fmt.Sprintf("cannot be read as a zip archive: %s", err.Error())}
+	}
+	defer archive.Close()
+
+	metadataFile := findTileMetadata(archive.File)
+	if metadataFile == nil {
+		return TileMetadata{}, ErrInvalidTile{Path: filepath, Reason: "no metadata YAML file in metadata/"}
+	}
+
+	f, err := metadataFile.Open()
+	if err != nil {
+		return TileMetadata{}, ErrInvalidTile{Path: filepath, Reason: fmt.Sprintf("cannot read %s: %s", metadataFile.Name, err.Error())}
+	}
+	defer f.Close()
+
+	b, err := ioutil.ReadAll(f)
+	if err != nil {
+		return TileMetadata{}, ErrInvalidTile{Path: filepath, Reason: fmt.Sprintf("cannot read %s: %s", metadataFile.Name, err.Error())}
+	}
+
+	var metadata TileMetadata
+	err = yaml.Unmarshal(b, &metadata)
+	if err != nil {
+		return TileMetadata{}, ErrInvalidTile{Path: filepath, Reason: fmt.Sprintf("cannot parse %s: %s", metadataFile.Name, err.Error())}
+	}
+
+	if metadata.Name == "" || metadata.ProductVersion == "" {
+		return TileMetadata{}, ErrInvalidTile{Path: filepath, Reason: fmt.Sprintf("%s has no name or product_version", metadataFile.Name)}
+	}
+
+	return metadata, nil
+}
+
+// findTileMetadata returns the first YAML file, by name, directly inside the
+// metadata/ directory of the archive, or nil if there is none.
+func findTileMetadata(files []*zip.File) *zip.File {
+	var candidates []*zip.File
+	for _, f := range files {
+		dir, name := path.Split(f.Name)
+		ext := path.Ext(name)
+		if dir == "metadata/" && (ext == ".yml" || ext == ".yaml") && !strings.HasPrefix(name, ".") {
+			candidates = append(candidates, f)
+		}
+	}
+
+	if len(candidates) == 0 {
+		return nil
+	}
+
+	sort.Slice(candidates, func(i, j int) bool {
+		return candidates[i].Name < candidates[j].Name
+	})
+
+	return candidates[0]
+}
@@ -0,0 +1,104 @@
+package pivnet_test
+
+import (
+	"archive/zip"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"github.com/pivotal-cf/go-pivnet"
+	"github.com/pivotal-cf/go-pivnet/logger/loggerfakes"
+)
+
+var _ = Describe("PivnetClient - ValidateTile", func() {
+	var (
+		client pivnet.Client
+		dir    string
+		path   string
+	)
+
+	writeZip := func(files map[string]string) {
+		f, err := os.Create(path)
+		Expect(err).NotTo(HaveOccurred())
+		defer f.Close()
+
+		w := zip.NewWriter(f)
+		for name, contents := range files {
+			entry, err := w.Create(name)
+			Expect(err).NotTo(HaveOccurred())
+			_, err = entry.Write([]byte(contents))
+			Expect(err).NotTo(HaveOccurred())
+		}
+		Expect(w.Close()).To(Succeed())
+	}
+
+	BeforeEach(func() {
+		client = pivnet.NewClient(pivnet.ClientConfig{
+			Host:  "https://example.com",
+			Token: "my-auth-token",
+		}, &loggerfakes.FakeLogger{})
+
+		var err error
+		dir, err = ioutil.TempDir("", "go-pivnet-tile")
+		Expect(err).NotTo(HaveOccurred())
+
+		path = filepath.Join(dir, "some-tile.pivotal")
+	})
+
+	AfterEach(func() {
+		os.RemoveAll(dir)
+	})
+
+	It("returns the product name and version from the tile metadata", func() {
+		writeZip(map[string]string{
+			"metadata/some-tile.yml":    "name: some-tile\nproduct_version: 1.2.3\nlabel: Some Tile\n",
+			"releases/some-release.tgz": "release contents",
+		})
+
+		metadata, err := client.ProductFiles.ValidateTile(path)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(metadata).To(Equal(pivnet.TileMetadata{
+			Name:           "some-tile",
+			ProductVersion: "1.2.3",
+		}))
+	})
+
+	Context("when the file is not a zip archive", func() {
+		It("returns an ErrInvalidTile", func() {
+			Expect(ioutil.WriteFile(path, []byte("truncated"), 0644)).To(Succeed())
+
+			_, err := client.ProductFiles.ValidateTile(path)
+			Expect(err).To(BeAssignableToTypeOf(pivnet.ErrInvalidTile{}))
+			Expect(err).To(MatchError(ContainSubstring("cannot be read as a zip archive")))
+		})
+	})
+
+	Context("when there is no metadata directory", func() {
+		It("returns an ErrInvalidTile", func() {
+			writeZip(map[string]string{"releases/some-release.tgz": "release contents"})
+
+			_, err := client.ProductFiles.ValidateTile(path)
+			Expect(err).To(MatchError(ContainSubstring("no metadata YAML file in metadata/")))
+		})
+	})
+
+	Context("when the metadata cannot be parsed", func() {
+		It("returns an ErrInvalidTile", func() {
+			writeZip(map[string]string{"metadata/some-tile.yml": "name: [unclosed"})
+
+			_, err := client.ProductFiles.ValidateTile(path)
+			Expect(err).To(MatchError(ContainSubstring("cannot parse metadata/some-tile.yml")))
+		})
+	})
+
+	Context("when the metadata has no product version", func() {
+		It("returns an ErrInvalidTile", func() {
+			writeZip(map[string]string{"metadata/some-tile.yml": "name: some-tile\n"})
+
+			_, err := client.ProductFiles.ValidateTile(path)
+			Expect(err).To(MatchError(ContainSubstring("has no name or product_version")))
+		})
+	})
+})
@@ -0,0 +1,29 @@
+package pivnet
+
+import (
+	"fmt"
+	"time"
+)
+
+var timestampLayouts = []string{
+	time.RFC3339Nano,
+	"2006-01-02T15:04:05.000Z0700",
+	"2006-01-02",
+}
+
+// parseTimestamp parses the timestamp and date formats returned by Pivnet.
+// An empty string yields the zero time without error.
+func parseTimestamp(s string) (time.Time, error) {
+	if s == "" {
+		return time.Time{}, nil
+	}
+
+	for _, layout := range timestampLayouts {
+		t, err := time.Parse(layout, s)
+		if err == nil {
+			return t, nil
+		}
+	}
+
+	return time.Time{}, fmt.Errorf("Could not parse timestamp: '%s'", s)
+}
@@ -111,6 +111,28 @@ func (u UserGroupsService) ListForRelease(productSlug string, releaseID int) ([]
 	return response.UserGroups, nil
 }
 
+// CountForRelease returns the number of user groups that can access the
+// release. Releases available to all users are not restricted by user
+// groups, so zero is returned for them whatever groups are associated.
+// The user groups endpoint is not paginated, so this makes two requests.
+func (u UserGroupsService) CountForRelease(productSlug string, releaseID int) (int, error) {
+	release, err := ReleasesService{client: u.client, l: u.client.logger}.Get(productSlug, releaseID)
+	if err != nil {
+		return 0, err
+	}
+
+	if release.Availability == AvailabilityAllUsers {
+		return 0, nil
+	}
+
+	userGroups, err := u.ListForRelease(productSlug, releaseID)
+	if err != nil {
+		return 0, err
+	}
+
+	return len(userGroups), nil
+}
+
 func (u UserGroupsService) AddToRelease(productSlug string, releaseID int, userGroupID int) error {
 	url := fmt.Sprintf(
 		"/products/%s/releases/%d/add_user_group",
@@ -179,6 +201,47 @@ func (u UserGroupsService) RemoveFromRelease(productSlug string, releaseID int,
 	return nil
 }
 
+func (u UserGroupsService) AddToReleaseIfNotPresent(productSlug string, releaseID int, userGroupID int) error {
+	present, err := u.isAssociatedWithRelease(productSlug, releaseID, userGroupID)
+	if err != nil {
+		return err
+	}
+
+	if present {
+		return nil
+	}
+
+	return u.AddToRelease(productSlug, releaseID, userGroupID)
+}
+
+func (u UserGroupsService) RemoveFromReleaseIfPresent(productSlug string, releaseID int, userGroupID int) error {
+	present, err := u.isAssociatedWithRelease(productSlug, releaseID, userGroupID)
+	if err != nil {
+		return err
+	}
+
+	if !present {
+		return nil
+	}
+
+	return u.RemoveFromRelease(productSlug, releaseID, userGroupID)
+}
+
+func (u UserGroupsService) isAssociatedWithRelease(productSlug string, releaseID int, userGroupID int) (bool, error) {
+	userGroups, err := u.ListForRelease(productSlug, releaseID)
+	if err != nil {
+		return false, err
+	}
+
+	for _, userGroup := range userGroups {
+		if userGroup.ID == userGroupID {
+			return true, nil
+		}
+	}
+
+	return false, nil
+}
+
 func (u UserGroupsService) Get(userGroupID int) (UserGroup, error) {
 	url := fmt.Sprintf("/user_groups/%d", userGroupID)
 
@@ -1,6 +1,7 @@
 package pivnet_test
 
 import (
+	"errors"
 	"fmt"
 	"net/http"
 
@@ -172,6 +173,63 @@ var _ = Describe("PivnetClient - user groups", func() {
 		})
 	})
 
+	Describe("CountForRelease", func() {
+		var (
+			releaseURL string
+		)
+
+		BeforeEach(func() {
+			releaseURL = fmt.Sprintf("%s/products/%s/releases/%d", apiPrefix, productSlug, 12)
+		})
+
+		It("returns the number of user groups of a restricted release", func() {
+			server.AppendHandlers(
+				ghttp.CombineHandlers(
+					ghttp.VerifyRequest("GET", releaseURL),
+					ghttp.RespondWith(http.StatusOK, `{"id":12,"availability":"Selected User Groups Only"}`),
+				),
+				ghttp.CombineHandlers(
+					ghttp.VerifyRequest("GET", releaseURL+"/user_groups"),
+					ghttp.RespondWith(http.StatusOK, `{"user_groups":[{"id":1},{"id":2}]}`),
+				),
+			)
+
+			count, err := client.UserGroups.CountForRelease(productSlug, 12)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(count).To(Equal(2))
+		})
+
+		Context("when the release is available to all users", func() {
+			It("returns zero without listing user groups", func() {
+				server.AppendHandlers(
+					ghttp.CombineHandlers(
+						ghttp.VerifyRequest("GET", releaseURL),
+						ghttp.RespondWith(http.StatusOK, `{"id":12,"availability":"All Users"}`),
+					),
+				)
+
+				count, err := client.UserGroups.CountForRelease(productSlug, 12)
+				Expect(err).NotTo(HaveOccurred())
+				Expect(count).To(BeZero())
+				Expect(server.ReceivedRequests()).To(HaveLen(1))
+			})
+		})
+
+		Context("when getting the release returns an error", func() {
+			It("returns the error", func() {
+				server.AppendHandlers(
+					ghttp.CombineHandlers(
+						ghttp.VerifyRequest("GET", releaseURL),
+						ghttp.RespondWith(http.StatusTeapot, `{"message":"foo message"}`),
+					),
+				)
+
+				_, err := client.UserGroups.CountForRelease(productSlug, 12)
+				Expect(err).To(MatchError(ContainSubstring("foo message")))
+			})
+		})
+	})
+
 	Describe("Add To Release", func() {
 		var (
 			productSlug = "banana-slug"
@@ -328,6 +386,152 @@ var _ = Describe("PivnetClient - user groups", func() {
 		})
 	})
 
+	Describe("Add To Release If Not Present", func() {
+		var (
+			productSlug = "banana-slug"
+			releaseID   = 2345
+			userGroupID = 3456
+
+			listURL string
+		)
+
+		BeforeEach(func() {
+			listURL = fmt.Sprintf(
+				"%s/products/%s/releases/%d/user_groups",
+				apiPrefix,
+				productSlug,
+				releaseID,
+			)
+		})
+
+		Context("when the user group is not associated with the release", func() {
+			It("adds the user group", func() {
+				server.AppendHandlers(
+					ghttp.CombineHandlers(
+						ghttp.VerifyRequest("GET", listURL),
+						ghttp.RespondWith(http.StatusOK, `{"user_groups":[{"id":1}]}`),
+					),
+					ghttp.CombineHandlers(
+						ghttp.VerifyRequest("PATCH", fmt.Sprintf(
+							"%s/products/%s/releases/%d/add_user_group",
+							apiPrefix,
+							productSlug,
+							releaseID,
+						)),
+						ghttp.VerifyJSON(`{"user_group":{"id":3456}}`),
+						ghttp.RespondWith(http.StatusNoContent, nil),
+					),
+				)
+
+				err := client.UserGroups.AddToReleaseIfNotPresent(productSlug, releaseID, userGroupID)
+				Expect(err).NotTo(HaveOccurred())
+				Expect(server.ReceivedRequests()).To(HaveLen(2))
+			})
+		})
+
+		Context("when the user group is already associated with the release", func() {
+			It("returns without error and does not add it again", func() {
+				server.AppendHandlers(
+					ghttp.CombineHandlers(
+						ghttp.VerifyRequest("GET", listURL),
+						ghttp.RespondWith(http.StatusOK, `{"user_groups":[{"id":3456}]}`),
+					),
+				)
+
+				err := client.UserGroups.AddToReleaseIfNotPresent(productSlug, releaseID, userGroupID)
+				Expect(err).NotTo(HaveOccurred())
+				Expect(server.ReceivedRequests()).To(HaveLen(1))
+			})
+		})
+
+		Context("when listing the user groups returns an error", func() {
+			It("forwards the error", func() {
+				server.AppendHandlers(
+					ghttp.CombineHandlers(
+						ghttp.VerifyRequest("GET", listURL),
+						ghttp.RespondWith(http.StatusTeapot, `{"message":"foo message"}`),
+					),
+				)
+
+				err := client.UserGroups.AddToReleaseIfNotPresent(productSlug, releaseID, userGroupID)
+				Expect(err.Error()).To(ContainSubstring("foo message"))
+			})
+		})
+	})
+
+	Describe("Remove From Release If Present", func() {
+		var (
+			productSlug = "banana-slug"
+			releaseID   = 2345
+			userGroupID = 3456
+
+			listURL string
+		)
+
+		BeforeEach(func() {
+			listURL = fmt.Sprintf(
+				"%s/products/%s/releases/%d/user_groups",
+				apiPrefix,
+				productSlug,
+				releaseID,
+			)
+		})
+
+		Context("when the user group is associated with the release", func() {
+			It("removes the user group", func() {
+				server.AppendHandlers(
+					ghttp.CombineHandlers(
+						ghttp.VerifyRequest("GET", listURL),
+						ghttp.RespondWith(http.StatusOK, `{"user_groups":[{"id":3456}]}`),
+					),
+					ghttp.CombineHandlers(
+						ghttp.VerifyRequest("PATCH", fmt.Sprintf(
+							"%s/products/%s/releases/%d/remove_user_group",
+							apiPrefix,
+							productSlug,
+							releaseID,
+						)),
+						ghttp.VerifyJSON(`{"user_group":{"id":3456}}`),
+						ghttp.RespondWith(http.StatusNoContent, nil),
+					),
+				)
+
+				err := client.UserGroups.RemoveFromReleaseIfPresent(productSlug, releaseID, userGroupID)
+				Expect(err).NotTo(HaveOccurred())
+				Expect(server.ReceivedRequests()).To(HaveLen(2))
+			})
+		})
+
+		Context("when the user group is not associated with the release", func() {
+			It("returns without error", func() {
+				server.AppendHandlers(
+					ghttp.CombineHandlers(
+						ghttp.VerifyRequest("GET", listURL),
+						ghttp.RespondWith(http.StatusOK, `{"user_groups":[]}`),
+					),
+				)
+
+				err := client.UserGroups.RemoveFromReleaseIfPresent(productSlug, releaseID, userGroupID)
+				Expect(err).NotTo(HaveOccurred())
+				Expect(server.ReceivedRequests()).To(HaveLen(1))
+			})
+		})
+	})
+
+	Context("when getting a user group that does not exist", func() {
+		It("returns an error matching ErrNotFound", func() {
+			server.AppendHandlers(
+				ghttp.CombineHandlers(
+					ghttp.VerifyRequest("GET", fmt.Sprintf("%s/user_groups/%d", apiPrefix, 12)),
+					ghttp.RespondWith(http.StatusNotFound, `{"message":"not found"}`),
+				),
+			)
+
+			_, err := client.UserGroups.Get(12)
+			Expect(errors.Is(err, pivnet.ErrNotFound{})).To(BeTrue())
+		})
+	})
+
 	Describe("Get User Group", func() {
 		var (
 			userGroupID int
@@ -0,0 +1,134 @@
+package pivnet
+
+import (
+	"os"
+	"path/filepath"
+	"sort"
+	"sync"
+)
+
+const (
+	FileVerificationOK         = "ok"
+	FileVerificationMissing    = "missing"
+	FileVerificationMismatch   = "mismatch"
+	FileVerificationNoChecksum = "no_checksum"
+	FileVerificationError      = "error"
+)
+
+// FileVerification is the result of checking one product file of a release
+// against a local copy.
+type FileVerification struct {
+	ProductFileID int    `json:"product_file_id" yaml:"product_file_id"`
+	FileName      string `json:"file_name" yaml:"file_name"`
+	Path          string `json:"path" yaml:"path"`
+
+	// Status is one of the FileVerification constants.
+	Status string `json:"status" yaml:"status"`
+
+	// Message describes the problem if Status is not FileVerificationOK.
+	Message string `json:"message,omitempty" yaml:"message,omitempty"`
+}
+
+// VerifyDir checks every product file of the release against the file of
+// the same name in dir, as laid out by DownloadAll, using the size and
+// checksum Pivnet records. Nothing is downloaded. Up to concurrency files
+// are checked at once.
+//
+// The report is sorted by file name. An error is only returned if the
+// product files of the release cannot be listed.
+func (p ProductFilesService) VerifyDir(
+	productSlug string,
+	releaseID int,
+	dir string,
+	concurrency int,
+) ([]FileVerification, error) {
+	return p.VerifyDirWithOptions(productSlug, releaseID, dir, VerifyDirOptions{
+		Concurrency: concurrency,
+	})
+}
+
+type VerifyDirOptions struct {
+	// Concurrency is the number of files hashed at once. Values below one
+	// check one file at a time.
+	Concurrency int
+
+	// OnResult, if set, is called with the result of each file as soon as
+	// it has been checked, in order of completion. Calls are not made
+	// concurrently.
+	OnResult func(FileVerification)
+}
+
+// VerifyDirWithOptions behaves like VerifyDir, applying the provided
+// options. The report is sorted by file name, then product file ID,
+// whatever order the files were checked in.
+func (p ProductFilesService) VerifyDirWithOptions(
+	productSlug string,
+	releaseID int,
+	dir string,
+	options VerifyDirOptions,
+) ([]FileVerification, error) {
+	productFiles, err := p.ListForRelease(productSlug, releaseID)
+	if err != nil {
+		return nil, err
+	}
+
+	var mutex sync.Mutex
+	report := make([]FileVerification, len(productFiles))
+	forEachConcurrently(len(productFiles), options.Concurrency, func(i int) {
+		report[i] = verifyInDir(productFiles[i], dir)
+
+		if options.OnResult != nil {
+			mutex.Lock()
+			options.OnResult(report[i])
+			mutex.Unlock()
+		}
+	})
+
+	sort.Slice(report, func(i, j int) bool {
+		if report[i].FileName != report[j].FileName {
+			return report[i].FileName < report[j].FileName
+		}
+		return report[i].ProductFileID < report[j].ProductFileID
+	})
+
+	return report, nil
+}
+
+func verifyInDir(pf ProductFile, dir string) FileVerification {
+	name := localFileName(pf)
+
+	result := FileVerification{
+		ProductFileID: pf.ID,
+		FileName:      name,
+		Path:          filepath.Join(dir, name),
+		Status:        FileVerificationOK,
+	}
+
+	_, err := os.Stat(result.Path)
+	if err != nil {
+		result.Status = FileVerificationError
+		if os.IsNotExist(err) {
+			result.Status = FileVerificationMissing
+		}
+		result.Message = err.Error()
+		return result
+	}
+
+	if newChecksumVerifier(pf) == nil {
+		result.Status = FileVerificationNoChecksum
+		result.Message = "Pivnet has no checksum for this file"
+		return result
+	}
+
+	err = verifyLocalFile(pf, result.Path)
+	if err != nil {
+		result.Status = FileVerificationError
+		switch err.(type) {
+		case ErrChecksumMismatch, ErrSizeMismatch:
+			result.Status = FileVerificationMismatch
+		}
+		result.Message = err.Error()
+	}
+
+	return result
+}
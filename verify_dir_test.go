@@ -0,0 +1,151 @@
+package pivnet_test
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"path/filepath"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"github.com/onsi/gomega/ghttp"
+	"github.com/pivotal-cf/go-pivnet"
+	"github.com/pivotal-cf/go-pivnet/logger/loggerfakes"
+)
+
+var _ = Describe("PivnetClient - VerifyDir", func() {
+	var (
+		server *ghttp.Server
+		client pivnet.Client
+		dir    string
+
+		releaseID = 1234
+	)
+
+	sha256Of := func(s string) string {
+		sum := sha256.Sum256([]byte(s))
+		return hex.EncodeToString(sum[:])
+	}
+
+	BeforeEach(func() {
+		server = ghttp.NewServer()
+		client = pivnet.NewClient(pivnet.ClientConfig{
+			Host:      server.URL(),
+			Token:     "my-auth-token",
+			UserAgent: "pivnet-resource/0.1.0 (some-url)",
+		}, &loggerfakes.FakeLogger{})
+
+		var err error
+		dir, err = ioutil.TempDir("", "go-pivnet-verify-dir")
+		Expect(err).NotTo(HaveOccurred())
+
+		for name, contents := range map[string]string{
+			"ok.tgz":          "ok contents",
+			"corrupt.tgz":     "corrupt contents",
+			"truncated.tgz":   "trunc",
+			"no-checksum.tgz": "anything",
+		} {
+			err = ioutil.WriteFile(filepath.Join(dir, name), []byte(contents), 0644)
+			Expect(err).NotTo(HaveOccurred())
+		}
+	})
+
+	AfterEach(func() {
+		server.Close()
+		os.RemoveAll(dir)
+	})
+
+	It("reports the status of every product file without downloading", func() {
+		server.AppendHandlers(
+			ghttp.CombineHandlers(
+				ghttp.VerifyRequest("GET", fmt.Sprintf("%s/products/%s/releases/%d/product_files", apiPrefix, productSlug, releaseID)),
+				ghttp.RespondWithJSONEncoded(http.StatusOK, pivnet.ProductFilesResponse{
+					ProductFiles: []pivnet.ProductFile{
+						{ID: 1, AWSObjectKey: "product-files/banana/ok.tgz", SHA256: sha256Of("ok contents"), Size: 11},
+						{ID: 2, AWSObjectKey: "product-files/banana/corrupt.tgz", SHA256: sha256Of("original contents")},
+						{ID: 3, AWSObjectKey: "product-files/banana/truncated.tgz", SHA256: sha256Of("truncated"), Size: 9},
+						{ID: 4, AWSObjectKey: "product-files/banana/missing.tgz", SHA256: sha256Of("missing")},
+						{ID: 5, AWSObjectKey: "product-files/banana/no-checksum.tgz"},
+					},
+				}),
+			),
+		)
+
+		report, err := client.ProductFiles.VerifyDir(productSlug, releaseID, dir, 2)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(server.ReceivedRequests()).To(HaveLen(1))
+
+		Expect(report).To(HaveLen(5))
+
+		statuses := map[string]string{}
+		for _, result := range report {
+			statuses[result.FileName] = result.Status
+			Expect(result.Path).To(Equal(filepath.Join(dir, result.FileName)))
+		}
+
+		Expect(statuses).To(Equal(map[string]string{
+			"corrupt.tgz":     pivnet.FileVerificationMismatch,
+			"missing.tgz":     pivnet.FileVerificationMissing,
+			"no-checksum.tgz": pivnet.FileVerificationNoChecksum,
+			"ok.tgz":          pivnet.FileVerificationOK,
+			"truncated.tgz":   pivnet.FileVerificationMismatch,
+		}))
+
+		Expect(report[0].FileName).To(Equal("corrupt.tgz"))
+		Expect(report[0].ProductFileID).To(Equal(2))
+		Expect(report[0].Message).To(ContainSubstring("checksum"))
+
+		Expect(report[3].FileName).To(Equal("ok.tgz"))
+		Expect(report[3].Message).To(BeEmpty())
+	})
+
+	Describe("VerifyDirWithOptions", func() {
+		It("reports each result as it completes and returns them sorted", func() {
+			productFiles := []pivnet.ProductFile{}
+			for i, name := range []string{"ok.tgz", "corrupt.tgz", "truncated.tgz", "no-checksum.tgz", "missing.tgz"} {
+				productFiles = append(productFiles, pivnet.ProductFile{
+					ID:           i + 1,
+					AWSObjectKey: "product-files/banana/" + name,
+					SHA256:       sha256Of("ok contents"),
+				})
+			}
+
+			server.AppendHandlers(
+				ghttp.RespondWithJSONEncoded(http.StatusOK, pivnet.ProductFilesResponse{
+					ProductFiles: productFiles,
+				}),
+			)
+
+			var reported []string
+			report, err := client.ProductFiles.VerifyDirWithOptions(productSlug, releaseID, dir, pivnet.VerifyDirOptions{
+				Concurrency: 3,
+				OnResult: func(result pivnet.FileVerification) {
+					reported = append(reported, result.FileName)
+				},
+			})
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(reported).To(ConsistOf("ok.tgz", "corrupt.tgz", "truncated.tgz", "no-checksum.tgz", "missing.tgz"))
+
+			var names []string
+			for _, result := range report {
+				names = append(names, result.FileName)
+			}
+			Expect(names).To(Equal([]string{"corrupt.tgz", "missing.tgz", "no-checksum.tgz", "ok.tgz", "truncated.tgz"}))
+		})
+	})
+
+	Context("when the product files cannot be listed", func() {
+		It("returns an error", func() {
+			server.AppendHandlers(
+				ghttp.RespondWith(http.StatusTeapot, `{"message":"foo message"}`),
+			)
+
+			_, err := client.ProductFiles.VerifyDir(productSlug, releaseID, dir, 1)
+			Expect(err).To(MatchError(ContainSubstring("foo message")))
+		})
+	})
+})
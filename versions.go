@@ -0,0 +1,292 @@
+package pivnet
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+type version struct {
+	segments   []int
+	prerelease []string
+}
+
+// parseVersion normalises the inconsistent version strings found on Pivnet
+// so that they can be compared:
+//
+//   - surrounding whitespace is ignored
+//   - a leading "version " and then a leading "v" are removed, ignoring case,
+//     so "Version 1.2", "v1.2" and "1.2" are equal
+//   - build metadata after a "+" is ignored
+//   - anything after the first "-" is a dot-separated prerelease
+//   - the remainder must be dot-separated non-negative integers
+//
+// Any other string is rejected with an error; parseVersion never panics.
+func parseVersion(s string) (version, error) {
+	v := strings.TrimSpace(s)
+	if len(v) >= len("version ") && strings.EqualFold(v[:len("version ")], "version ") {
+		v = strings.TrimSpace(v[len("version "):])
+	}
+	if strings.HasPrefix(v, "v") || strings.HasPrefix(v, "V") {
+		v = v[1:]
+	}
+
+	if i := strings.Index(v, "+"); i >= 0 {
+		v = v[:i]
+	}
+
+	var prerelease []string
+	if i := strings.Index(v, "-"); i >= 0 {
+		if i == len(v)-1 {
+			return version{}, fmt.Errorf("Invalid version: %q", s)
+		}
+		prerelease = strings.Split(v[i+1:], ".")
+		v = v[:i]
+	}
+
+	if v == "" {
+		return version{}, fmt.Errorf("Invalid version: %q", s)
+	}
+
+	var segments []int
+	for _, part := range strings.Split(v, ".") {
+		n, err := strconv.Atoi(part)
+		if err != nil || n < 0 {
+			return version{}, fmt.Errorf("Invalid version: %q", s)
+		}
+		segments = append(segments, n)
+	}
+
+	return version{
+		segments:   segments,
+		prerelease: prerelease,
+	}, nil
+}
+
+// compare returns -1, 0 or 1 if v is less than, equal to or greater than o.
+// Missing segments are treated as zero, so 1.2 and 1.2.0 are equal.
+func (v version) compare(o version) int {
+	for i := 0; i < len(v.segments) || i < len(o.segments); i++ {
+		a := segmentAt(v.segments, i)
+		b := segmentAt(o.segments, i)
+		if a != b {
+			return compareInts(a, b)
+		}
+	}
+
+	return comparePrerelease(v.prerelease, o.prerelease)
+}
+
+func segmentAt(segments []int, i int) int {
+	if i < len(segments) {
+		return segments[i]
+	}
+	return 0
+}
+
+func compareInts(a int, b int) int {
+	switch {
+	case a < b:
+		return -1
+	case a > b:
+		return 1
+	default:
+		return 0
+	}
+}
+
+// comparePrerelease follows semver precedence: a version without a
+// prerelease is greater than one with a prerelease, numeric identifiers
+// compare numerically and are lower than alphanumeric identifiers.
+func comparePrerelease(a []string, b []string) int {
+	switch {
+	case len(a) == 0 && len(b) == 0:
+		return 0
+	case len(a) == 0:
+		return 1
+	case len(b) == 0:
+		return -1
+	}
+
+	for i := 0; i < len(a) && i < len(b); i++ {
+		an, aErr := strconv.Atoi(a[i])
+		bn, bErr := strconv.Atoi(b[i])
+
+		switch {
+		case aErr == nil && bErr == nil:
+			if an != bn {
+				return compareInts(an, bn)
+			}
+		case aErr == nil:
+			return -1
+		case bErr == nil:
+			return 1
+		default:
+			if c := strings.Compare(a[i], b[i]); c != 0 {
+				return c
+			}
+		}
+	}
+
+	return compareInts(len(a), len(b))
+}
+
+type versionConstraint []versionClause
+
+type versionClause struct {
+	op       string
+	version  version
+	wildcard bool
+}
+
+var versionOperators = []string{">=", "<=", "!=", ">", "<", "="}
+
+// parseVersionConstraint parses a constraint such as ">= 1.2, < 2" or "2.x".
+// Clauses separated by commas or whitespace must all be satisfied. A version
+// ending in "x" or "*" matches any version with the same leading segments.
+// An empty constraint matches every version.
+//
+// As with semver ranges, a prerelease version only satisfies a constraint
+// that has a clause naming a prerelease of the same version, so "2.x"
+// matches 2.5.0 but not 2.6.0-rc.1, while ">= 2.6.0-rc.0" matches both.
+func parseVersionConstraint(s string) (versionConstraint, error) {
+	var tokens []string
+	for _, field := range strings.Fields(strings.Replace(s, ",", " ", -1)) {
+		if len(tokens) > 0 && isVersionOperator(tokens[len(tokens)-1]) {
+			tokens[len(tokens)-1] += field
+			continue
+		}
+		tokens = append(tokens, field)
+	}
+
+	var constraint versionConstraint
+	for _, token := range tokens {
+		clause, err := parseVersionClause(token)
+		if err != nil {
+			return nil, fmt.Errorf("Invalid version constraint %q: %s", s, err.Error())
+		}
+		constraint = append(constraint, clause)
+	}
+
+	return constraint, nil
+}
+
+func isVersionOperator(s string) bool {
+	for _, op := range versionOperators {
+		if s == op {
+			return true
+		}
+	}
+	return false
+}
+
+func parseVersionClause(token string) (versionClause, error) {
+	op := "="
+	for _, candidate := range versionOperators {
+		if strings.HasPrefix(token, candidate) {
+			op = candidate
+			token = token[len(candidate):]
+			break
+		}
+	}
+
+	if strings.HasSuffix(token, ".x") || strings.HasSuffix(token, ".*") ||
+		token == "x" || token == "*" {
+		if op != "=" && op != "!=" {
+			return versionClause{}, fmt.Errorf("operator %q cannot be used with a wildcard", op)
+		}
+
+		prefix := strings.TrimSuffix(strings.TrimSuffix(token, "x"), "*")
+		prefix = strings.TrimSuffix(prefix, ".")
+
+		var segments []int
+		if prefix != "" {
+			v, err := parseVersion(prefix)
+			if err != nil {
+				return versionClause{}, err
+			}
+			if len(v.prerelease) > 0 {
+				return versionClause{}, fmt.Errorf("wildcard versions cannot have a prerelease")
+			}
+			segments = v.segments
+		}
+
+		return versionClause{
+			op:       op,
+			version:  version{segments: segments},
+			wildcard: true,
+		}, nil
+	}
+
+	v, err := parseVersion(token)
+	if err != nil {
+		return versionClause{}, err
+	}
+
+	return versionClause{
+		op:      op,
+		version: v,
+	}, nil
+}
+
+func (c versionConstraint) check(v version) bool {
+	if len(v.prerelease) > 0 && !c.allowsPrerelease(v) {
+		return false
+	}
+
+	for _, clause := range c {
+		if !clause.check(v) {
+			return false
+		}
+	}
+	return true
+}
+
+// allowsPrerelease reports whether a clause names a prerelease with the
+// same segments as v.
+func (c versionConstraint) allowsPrerelease(v version) bool {
+	for _, clause := range c {
+		if clause.wildcard || len(clause.version.prerelease) == 0 {
+			continue
+		}
+
+		if clause.version.compare(version{segments: v.segments, prerelease: clause.version.prerelease}) == 0 {
+			return true
+		}
+	}
+	return false
+}
+
+func (c versionClause) check(v version) bool {
+	if c.wildcard {
+		matches := true
+		for i, segment := range c.version.segments {
+			if segmentAt(v.segments, i) != segment {
+				matches = false
+				break
+			}
+		}
+
+		if c.op == "!=" {
+			return !matches
+		}
+		return matches
+	}
+
+	cmp := v.compare(c.version)
+
+	switch c.op {
+	case ">=":
+		return cmp >= 0
+	case "<=":
+		return cmp <= 0
+	case "!=":
+		return cmp != 0
+	case ">":
+		return cmp > 0
+	case "<":
+		return cmp < 0
+	default:
+		return cmp == 0
+	}
+}
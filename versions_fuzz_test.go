@@ -0,0 +1,43 @@
+package pivnet
+
+import "testing"
+
+func FuzzParseVersion(f *testing.F) {
+	for _, seed := range []string{
+		"1.2.3",
+		"version 0.2.3",
+		"Version 1.2",
+		"v2.0.0-rc.1",
+		"2.0.0-rc.1+build.5",
+		"1.2.3-",
+		"-",
+		"v",
+		"version ",
+		"1..2",
+		"99999999999999999999",
+		"1.2.3-alpha.1.beta",
+		" 1.2 ",
+	} {
+		f.Add(seed)
+	}
+
+	reference, err := parseVersion("1.2.3-rc.1")
+	if err != nil {
+		f.Fatal(err)
+	}
+
+	f.Fuzz(func(t *testing.T, s string) {
+		v, err := parseVersion(s)
+		if err != nil {
+			return
+		}
+
+		if v.compare(v) != 0 {
+			t.Errorf("%q does not compare equal to itself", s)
+		}
+
+		if v.compare(reference) != -reference.compare(v) {
+			t.Errorf("comparing %q with %q is not antisymmetric", s, "1.2.3-rc.1")
+		}
+	})
+}